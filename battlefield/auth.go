@@ -0,0 +1,180 @@
+package battlefield
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Role describes permissions of authenticated player.
+type Role string
+
+// Roles supported by Authenticator.
+const (
+	RolePlayer Role = "player"
+	RoleAdmin  Role = "admin"
+)
+
+// Principal describes authenticated caller.
+type Principal struct {
+	Player string `json:"sub"`
+	Role   Role   `json:"role"`
+}
+
+// tokenClaims is the signed payload of bearer token.
+type tokenClaims struct {
+	Principal
+	Expires int64 `json:"exp"`
+}
+
+type principalKey struct{}
+
+// Authenticator checks static API keys and HMAC-signed bearer tokens.
+type Authenticator struct {
+	keys   map[string]Principal
+	secret []byte
+	logger *logrus.Logger
+}
+
+// NewAuthenticator creates new Authenticator.
+// keys maps static API keys to their principals, secret is used
+// to verify bearer tokens; tokens are rejected if secret is empty.
+func NewAuthenticator(l *logrus.Logger, keys map[string]Principal, secret []byte) Authenticator {
+	return Authenticator{keys: keys, secret: secret, logger: l}
+}
+
+// Middleware rejects requests without valid credentials and stores
+// authenticated Principal in the request context.
+// API key is expected in X-API-Key header, token in Authorization header
+// with Bearer scheme.
+func (a Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.authenticate(r)
+		if err != nil {
+			a.logger.Errorf("Authenticator: %s %s: %v", r.Method, r.URL.Path, err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="battleship"`)
+			handleErrorResponse(w, err)
+			return
+		}
+		ctx := context.WithValue(r.Context(), principalKey{}, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (a Authenticator) authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		for k, p := range a.keys {
+			if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+				return p, nil
+			}
+		}
+		return Principal{}, errorInvalidCredentials
+	}
+
+	h := r.Header.Get("Authorization")
+	if h == "" {
+		return Principal{}, errorUnauthorized
+	}
+	const prefix = "Bearer "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return Principal{}, errorInvalidCredentials
+	}
+	return a.parseToken(strings.TrimSpace(h[len(prefix):]), time.Now())
+}
+
+func (a Authenticator) parseToken(token string, now time.Time) (Principal, error) {
+	if len(a.secret) == 0 {
+		return Principal{}, errorInvalidCredentials
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Principal{}, errorInvalidCredentials
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Principal{}, errorInvalidCredentials
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Principal{}, errorInvalidCredentials
+	}
+	if !hmac.Equal(sig, sign(a.secret, payload)) {
+		return Principal{}, errorInvalidCredentials
+	}
+
+	claims := tokenClaims{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Player == "" {
+		return Principal{}, errorInvalidCredentials
+	}
+	if claims.Expires != 0 && now.Unix() >= claims.Expires {
+		return Principal{}, errorTokenExpired
+	}
+	if claims.Role == "" {
+		claims.Role = RolePlayer
+	}
+	return claims.Principal, nil
+}
+
+// SignToken creates bearer token for the principal signed with secret.
+// Zero exp means token never expires.
+func SignToken(secret []byte, p Principal, exp time.Time) string {
+	claims := tokenClaims{Principal: p}
+	if !exp.IsZero() {
+		claims.Expires = exp.Unix()
+	}
+	// marshaling of the plain struct can't fail.
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(sign(secret, payload))
+}
+
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// ParseAPIKeys parses API keys in "key:player:role,key:player:role" format.
+// Role can be omitted and defaults to RolePlayer.
+func ParseAPIKeys(s string) (map[string]Principal, error) {
+	keys := make(map[string]Principal)
+	if strings.TrimSpace(s) == "" {
+		return keys, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		l := strings.Split(strings.TrimSpace(entry), ":")
+		if len(l) < 2 || len(l) > 3 || l[0] == "" || l[1] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q", entry)
+		}
+		p := Principal{Player: l[1], Role: RolePlayer}
+		if len(l) == 3 {
+			p.Role = Role(l[2])
+		}
+		if p.Role != RolePlayer && p.Role != RoleAdmin {
+			return nil, fmt.Errorf("invalid role of API key entry %q", entry)
+		}
+		keys[l[0]] = p
+	}
+	return keys, nil
+}
+
+// callerFromRequest returns caller of the request.
+// Requests without Principal come only when authentication is disabled,
+// so they are allowed to do anything.
+func callerFromRequest(r *http.Request) caller {
+	ifMatch := r.Header.Get("If-Match")
+	p, ok := r.Context().Value(principalKey{}).(Principal)
+	if !ok {
+		return caller{admin: true, ifMatch: ifMatch}
+	}
+	return caller{player: p.Player, admin: p.Role == RoleAdmin, ifMatch: ifMatch}
+}
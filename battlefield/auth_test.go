@@ -0,0 +1,174 @@
+package battlefield
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	l := logrus.New()
+	keys := map[string]Principal{"key": {Player: "alice", Role: RolePlayer}}
+	secret := []byte("secret")
+	want := Authenticator{keys: keys, secret: secret, logger: l}
+	got := NewAuthenticator(l, keys, secret)
+	assert.Equal(t, want, got)
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	secret := []byte("secret")
+	keys := map[string]Principal{
+		"alice-key": {Player: "alice", Role: RolePlayer},
+		"root-key":  {Player: "root", Role: RoleAdmin},
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success, api key",
+			headers:    map[string]string{"X-API-Key": "alice-key"},
+			wantStatus: http.StatusOK,
+			wantBody:   "alice false",
+		},
+		{
+			name:       "success, admin api key",
+			headers:    map[string]string{"X-API-Key": "root-key"},
+			wantStatus: http.StatusOK,
+			wantBody:   "root true",
+		},
+		{
+			name: "success, bearer token",
+			headers: map[string]string{"Authorization": "Bearer " + SignToken(
+				secret,
+				Principal{Player: "bob"},
+				time.Now().Add(time.Hour),
+			)},
+			wantStatus: http.StatusOK,
+			wantBody:   "bob false",
+		},
+		{
+			name:       "error, no credentials",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"UNAUTHORIZED","err":"authentication required"}`,
+		},
+		{
+			name:       "error, unknown api key",
+			headers:    map[string]string{"X-API-Key": "unknown"},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"INVALID_CREDENTIALS","err":"invalid credentials"}`,
+		},
+		{
+			name:       "error, unsupported scheme",
+			headers:    map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"INVALID_CREDENTIALS","err":"invalid credentials"}`,
+		},
+		{
+			name: "error, token signed with other secret",
+			headers: map[string]string{"Authorization": "Bearer " + SignToken(
+				[]byte("other"),
+				Principal{Player: "bob"},
+				time.Time{},
+			)},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"INVALID_CREDENTIALS","err":"invalid credentials"}`,
+		},
+		{
+			name: "error, token expired",
+			headers: map[string]string{"Authorization": "Bearer " + SignToken(
+				secret,
+				Principal{Player: "bob"},
+				time.Now().Add(-time.Hour),
+			)},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"TOKEN_EXPIRED","err":"token expired"}`,
+		},
+		{
+			name:       "error, malformed token",
+			headers:    map[string]string{"Authorization": "Bearer not-a-token"},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"code":"INVALID_CREDENTIALS","err":"invalid credentials"}`,
+		},
+	}
+
+	a := NewAuthenticator(logrus.New(), keys, secret)
+	h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cl := callerFromRequest(r)
+		_, _ = w.Write([]byte(cl.player + " " + strconv.FormatBool(cl.admin)))
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/shot", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			h.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    map[string]Principal
+		wantErr bool
+	}{
+		{
+			name: "success, empty",
+			args: "",
+			want: map[string]Principal{},
+		},
+		{
+			name: "success, role omitted and provided",
+			args: "k1:alice, k2:root:admin",
+			want: map[string]Principal{
+				"k1": {Player: "alice", Role: RolePlayer},
+				"k2": {Player: "root", Role: RoleAdmin},
+			},
+		},
+		{
+			name:    "error, player omitted",
+			args:    "k1",
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown role",
+			args:    "k1:alice:superuser",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAPIKeys(tt.args)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestCallerFromRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/state", nil)
+	assert.Equal(t, caller{admin: true}, callerFromRequest(req))
+
+	req.Header.Set("If-Match", `"42-1"`)
+	req = req.WithContext(context.WithValue(req.Context(), principalKey{}, Principal{Player: "alice"}))
+	assert.Equal(t, caller{player: "alice", ifMatch: `"42-1"`}, callerFromRequest(req))
+}
@@ -1,38 +1,128 @@
 package battlefield
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"my/battleship/coordinates"
+)
+
 // maxFieldSize is selected to include all english letters.
-const maxFieldSize uint = 'z' - 'a' + 1
+const maxFieldSize = coordinates.MaxSize
 
 // Field contains all battlefield data.
 type Field struct {
+	// id identifies the game played on the field,
+	// version is incremented on every change of the game.
+	id      string
+	version uint64
+
 	field      [][]cell
 	size       uint
 	isSet      bool
 	shipsAdded bool
 
+	// rules is the name of the rules preset,
+	// grid is the name of the grid, GridSquare if empty.
+	rules string
+	grid  string
+	// wrap makes the field a torus, ships may cross its edges.
+	wrap bool
+	// seed is the source of all randomness of the game, see random.
+	seed int64
+	// terrain is terrain of the field as it was set.
+	terrain Terrain
+	// moving allows the owner to move undamaged ships, repairs is the number
+	// of cells the owner can repair on every ship, zero if repairs are off.
+	// defenderTurn is set by the move of the attacker and spent by the move
+	// or the repair of the ship, see ownerTurn. It's the optional reply:
+	// the attacker doesn't wait for it, the reply not made is lost.
+	moving       bool
+	repairs      int
+	defenderTurn bool
+	// weapons are weapons left in the advanced mode, nil if it's off.
+	weapons map[string]int
+	// mines is the number of mines the owner hides, zero if mines are off,
+	// minePenalty is the penalty of the attacker who hits a mine,
+	// turnLost is set until the attacker spends the turn lost on the mine.
+	mines       int
+	minePenalty string
+	minesAdded  bool
+	turnLost    bool
+	// ships are placed ships in the order of the request.
+	ships []*ship
+
 	gameIsOver bool
 	shipsAlive int
 
+	// owner is the player who created the field and places ships,
+	// attacker is the player who shoots at it.
+	// Both are empty if authentication is disabled.
+	owner    string
+	attacker string
+
+	// log records all moves to persist and replay the game.
+	log []event
+
+	// created is the time the field was created. paused is set while
+	// the game is paused since pausedAt, pausedFor is the total time
+	// of previous pauses. See clock.
+	created   time.Time
+	paused    bool
+	pausedAt  time.Time
+	pausedFor time.Duration
+	// archived is set when the finished game is saved to the archive.
+	archived bool
+
 	state state
+	stats gameStats
 }
 
 type cell struct {
 	occupied bool
 	ship     *ship
+	mine     bool
 	shot     bool
+	// repaired is set if the hit cell was repaired, the attacker
+	// sees the hit until the cell is shot again.
+	repaired bool
+	terrain  terrain
 }
 
 type shotResult struct {
 	Destroy bool
 	Knock   bool
 	End     bool
+	// Terrain is the kind of terrain absorbed the shot.
+	Terrain string
+	// Mine is set if the shot hit the mine, Penalty is the penalty of it.
+	Mine    bool
+	Penalty string
+	// Skipped is set if the shot spent the turn lost on the mine.
+	Skipped bool
 }
 
 type state struct {
+	game      string
+	version   uint64
+	seed      int64
 	shipCount int
 	destroyed int
 	knocked   int
 	shotCount int
+	// paused is set while the game is paused, clock is the play time.
+	paused bool
+	clock  time.Duration
+	// mineCount is the number of hidden mines, minesHit is the number
+	// of mines hit, retaliations is the number of free turns of the owner left.
+	mineCount    int
+	minesHit     int
+	retaliations int
+	// weapons are weapons left, nil if the advanced mode is off.
+	weapons map[string]int
 }
 
 // NewField creates new battlefield with provided size.
@@ -48,3 +138,79 @@ func NewField(size uint) Field {
 		isSet: true,
 	}
 }
+
+// newGameID generates random game identifier.
+func newGameID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// copyField returns copy of the field cells.
+func (f Field) copyField() [][]cell {
+	field := make([][]cell, len(f.field))
+	for i := range f.field {
+		field[i] = append([]cell(nil), f.field[i]...)
+	}
+	return field
+}
+
+// etag returns entity tag of the game version.
+func etag(game string, version uint64) string {
+	return strconv.Quote(game + "-" + strconv.FormatUint(version, 10))
+}
+
+// matchETag checks if entity tag matches If-Match or If-None-Match header.
+func matchETag(header, tag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks if caller expects the current version of the game.
+func (f Field) matches(cl caller) bool {
+	return cl.ifMatch == "" || matchETag(cl.ifMatch, etag(f.id, f.version))
+}
+
+// isOwnedBy checks if caller can manage ships on the field.
+func (f Field) isOwnedBy(cl caller) bool {
+	return cl.admin || f.owner == "" || f.owner == cl.player
+}
+
+// defends reports if the owner takes turns after moves of the attacker.
+func (f Field) defends() bool {
+	return f.moving || f.repairs > 0
+}
+
+// ownerTurn reports if the owner can move or repair the ship: on their
+// turn or on the free turn given by the mine.
+func (f Field) ownerTurn() bool {
+	return f.defenderTurn || f.state.retaliations > 0
+}
+
+// spendOwnerTurn spends the turn of the owner, free turns are kept
+// while the owner has the regular one.
+func (f *Field) spendOwnerTurn() {
+	if f.defenderTurn {
+		f.defenderTurn = false
+		return
+	}
+	f.state.retaliations--
+}
+
+// hasTurn checks if caller can shoot at the field.
+// Owner can't shoot at own ships, and once the attacker
+// made the first shot, nobody else can shoot.
+func (f Field) hasTurn(cl caller) bool {
+	if cl.player == "" {
+		return true
+	}
+	if cl.player == f.owner {
+		return false
+	}
+	return f.attacker == "" || f.attacker == cl.player
+}
@@ -43,3 +43,24 @@ func TestNewField(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "success, equal", header: `"42-1"`, want: true},
+		{name: "success, one of list", header: `"42-0", "42-1"`, want: true},
+		{name: "success, weak tag", header: `W/"42-1"`, want: true},
+		{name: "success, any", header: "*", want: true},
+		{name: "not matched, other version", header: `"42-2"`},
+		{name: "not matched, unquoted", header: "42-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchETag(tt.header, etag("42", 1)))
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package battlefield
+
+import (
+	"math/bits"
+
+	"my/battleship/coordinates"
+)
+
+// boardCells is the number of cells of the biggest board.
+const boardCells = maxFieldSize * maxFieldSize
+
+// bitset is the set of cells of the board, cell (x, y) is bit y*size+x.
+type bitset [(boardCells + 63) / 64]uint64
+
+func (b *bitset) set(i uint) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b *bitset) has(i uint) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b *bitset) count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Board is compact representation of the field for simulations,
+// it doesn't allocate memory after creation. Ships and shots follow
+// the same rules as on the Field: ships can't be placed on or near
+// other ships and every cell can be shot once.
+type Board struct {
+	size uint
+
+	ships    bitset
+	reserved bitset // cells near ships
+	shots    bitset
+
+	// owners are ship numbers by cell, starting from one, zero if no ship.
+	owners [boardCells]uint8
+	// alive are numbers of not shot cells by ship number,
+	// ships don't touch, so there are at most boardCells/4 of them.
+	alive      [boardCells/4 + 1]uint16
+	shipCount  int
+	shipsAlive int
+}
+
+// NewBoard creates new board of the size, size should not exceed maxFieldSize.
+func NewBoard(size uint) *Board {
+	return &Board{size: size}
+}
+
+// Reset removes all ships and shots.
+func (b *Board) Reset() {
+	*b = Board{size: b.size}
+}
+
+// Size returns size of the board.
+func (b *Board) Size() uint {
+	return b.size
+}
+
+// ShipsAlive returns number of not destroyed ships.
+func (b *Board) ShipsAlive() int {
+	return b.shipsAlive
+}
+
+// Shots returns number of shot cells.
+func (b *Board) Shots() int {
+	return b.shots.count()
+}
+
+func (b *Board) index(x, y uint) uint {
+	return y*b.size + x
+}
+
+// Place places rectangular ship with the corners. The board is
+// unchanged if the ship can't be placed, the error is the problem
+// of the first cell of the ship in reading order.
+func (b *Board) Place(c1, c2 coordinates.Coordinate) error {
+	lx, bx := c1.X, c2.X
+	if lx > bx {
+		lx, bx = bx, lx
+	}
+	ly, by := c1.Y, c2.Y
+	if ly > by {
+		ly, by = by, ly
+	}
+
+	for y := ly; y <= by; y++ {
+		for x := lx; x <= bx; x++ {
+			if x >= b.size || y >= b.size {
+				return errorOutOfBonds.withCoord(coordinates.Coordinate{X: x, Y: y})
+			}
+			i := b.index(x, y)
+			if b.ships.has(i) {
+				return errorCellIsOccupiedByShip.withCoord(coordinates.Coordinate{X: x, Y: y})
+			}
+			if b.reserved.has(i) {
+				return errorCellIsOccupiedNearby.withCoord(coordinates.Coordinate{X: x, Y: y})
+			}
+		}
+	}
+
+	b.shipCount++
+	b.shipsAlive++
+	for y := ly; y <= by; y++ {
+		for x := lx; x <= bx; x++ {
+			i := b.index(x, y)
+			b.ships.set(i)
+			b.owners[i] = uint8(b.shipCount)
+		}
+	}
+	b.alive[b.shipCount] = uint16((bx - lx + 1) * (by - ly + 1))
+
+	// reserve nearby cells, skip if out of bonds
+	if lx > 0 {
+		lx--
+	}
+	if ly > 0 {
+		ly--
+	}
+	for y := ly; y <= by+1 && y < b.size; y++ {
+		for x := lx; x <= bx+1 && x < b.size; x++ {
+			if i := b.index(x, y); !b.ships.has(i) {
+				b.reserved.set(i)
+			}
+		}
+	}
+	return nil
+}
+
+// Shot shoots the cell, results and errors are the same as of the Field.
+func (b *Board) Shot(c coordinates.Coordinate) (shotResult, error) {
+	if c.X >= b.size || c.Y >= b.size {
+		return shotResult{}, errorOutOfBonds.withCoord(c)
+	}
+	i := b.index(c.X, c.Y)
+	if b.shots.has(i) {
+		return shotResult{}, errorCellAlreadyShot.withCoord(c)
+	}
+	b.shots.set(i)
+
+	res := shotResult{}
+	if n := b.owners[i]; n != 0 {
+		res.Knock = true
+		b.alive[n]--
+		if b.alive[n] == 0 {
+			res.Destroy = true
+			b.shipsAlive--
+		}
+	}
+	res.End = b.shipsAlive == 0
+	return res, nil
+}
@@ -0,0 +1,259 @@
+package battlefield
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func coord(s string) coordinates.Coordinate {
+	c, _ := coordinates.ConvertCoordinate(s)
+	return c
+}
+
+func TestBoard_Place(t *testing.T) {
+	tests := []struct {
+		name    string
+		placed  [][2]string
+		c1, c2  string
+		wantErr error
+	}{
+		{
+			name: "success",
+			c1:   "A1",
+			c2:   "B2",
+		},
+		{
+			name: "success, reversed corners",
+			c1:   "C3",
+			c2:   "A3",
+		},
+		{
+			name:    "error, out of bonds",
+			c1:      "B3",
+			c2:      "D3",
+			wantErr: errorOutOfBonds.withCoord(coord("D3")),
+		},
+		{
+			name:    "error, the first problem in reading order",
+			placed:  [][2]string{{"B2", "B2"}},
+			c1:      "A2",
+			c2:      "C2",
+			wantErr: errorCellIsOccupiedNearby.withCoord(coord("A2")),
+		},
+		{
+			name:    "error, occupied by ship",
+			placed:  [][2]string{{"A1", "C1"}},
+			c1:      "A1",
+			c2:      "A3",
+			wantErr: errorCellIsOccupiedByShip.withCoord(coord("A1")),
+		},
+		{
+			name:    "error, occupied nearby",
+			placed:  [][2]string{{"A1", "A1"}},
+			c1:      "B2",
+			c2:      "C2",
+			wantErr: errorCellIsOccupiedNearby.withCoord(coord("B2")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBoard(3)
+			for _, p := range tt.placed {
+				assert.NoError(t, b.Place(coord(p[0]), coord(p[1])))
+			}
+			before := *b
+
+			err := b.Place(coord(tt.c1), coord(tt.c2))
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				assert.Equal(t, before, *b)
+				return
+			}
+			assert.Equal(t, len(tt.placed)+1, b.ShipsAlive())
+		})
+	}
+}
+
+func TestBoard_Shot(t *testing.T) {
+	b := NewBoard(3)
+	assert.Equal(t, uint(3), b.Size())
+	assert.NoError(t, b.Place(coord("A1"), coord("A2")))
+	assert.NoError(t, b.Place(coord("C3"), coord("C3")))
+
+	tests := []struct {
+		coord   string
+		want    shotResult
+		wantErr error
+	}{
+		{coord: "B1", want: shotResult{}},
+		{coord: "A1", want: shotResult{Knock: true}},
+		{coord: "A1", wantErr: errorCellAlreadyShot.withCoord(coord("A1"))},
+		{coord: "D1", wantErr: errorOutOfBonds.withCoord(coord("D1"))},
+		{coord: "A2", want: shotResult{Knock: true, Destroy: true}},
+		{coord: "C3", want: shotResult{Knock: true, Destroy: true, End: true}},
+	}
+
+	for _, tt := range tests {
+		res, err := b.Shot(coord(tt.coord))
+		assert.Equal(t, tt.wantErr, err, tt.coord)
+		assert.Equal(t, tt.want, res, tt.coord)
+	}
+	assert.Equal(t, 0, b.ShipsAlive())
+	assert.Equal(t, 4, b.Shots())
+
+	b.Reset()
+	assert.Equal(t, NewBoard(3), b)
+}
+
+// randomShip returns ship with random corners, possibly out of bonds.
+func randomShip(rng *rand.Rand, size uint) (c1, c2 coordinates.Coordinate) {
+	c1 = coordinates.Coordinate{X: uint(rng.Intn(int(size))), Y: uint(rng.Intn(int(size)))}
+	c2 = coordinates.Coordinate{X: c1.X + uint(rng.Intn(3)), Y: c1.Y}
+	if rng.Intn(2) == 0 {
+		c2 = coordinates.Coordinate{X: c1.X, Y: c1.Y + uint(rng.Intn(3))}
+	}
+	if rng.Intn(2) == 0 {
+		c1, c2 = c2, c1
+	}
+	return c1, c2
+}
+
+// TestBoard_Equivalence plays random games on the Board and on the Field
+// and checks that they give the same results.
+func TestBoard_Equivalence(t *testing.T) {
+	for seed := int64(0); seed < 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		size := uint(1 + rng.Intn(int(maxFieldSize)))
+
+		b := NewBoard(size)
+		field := NewField(size).field
+		var placed []string
+		for i := 0; i < 30; i++ {
+			c1, c2 := randomShip(rng, size)
+			sh := newShip(c1, c2)
+
+			err := b.Place(c1, c2)
+			problems := placeShip(field, size, sh)
+			if len(problems) == 0 {
+				assert.NoError(t, err, "seed %d", seed)
+				placed = append(placed, sh.String())
+				continue
+			}
+			assert.Equal(t, problems[0], err, "seed %d", seed)
+		}
+		if len(placed) == 0 {
+			continue
+		}
+
+		l := logrus.New()
+		l.SetOutput(ioutil.Discard)
+		s := NewService(l)
+		assert.NoError(t, s.createField(fieldOptions{size: size}, caller{}))
+		assert.NoError(t, s.addShipsByCoordinates(strings.Join(placed, ","), caller{}))
+		assert.Equal(t, len(placed), b.ShipsAlive())
+
+		for !s.f.gameIsOver {
+			c := coordinates.Coordinate{X: uint(rng.Intn(int(size) + 1)), Y: uint(rng.Intn(int(size)))}
+			c.X %= maxFieldSize
+
+			want, wantErr := s.shot(c.String(), caller{})
+			got, err := b.Shot(c)
+			assert.Equal(t, wantErr, err, "seed %d, shot %s", seed, c)
+			assert.Equal(t, want, got, "seed %d, shot %s", seed, c)
+		}
+		assert.Equal(t, 0, b.ShipsAlive())
+	}
+}
+
+// classicFleet is the classic fleet in the format of AddShipsRequest.
+const classicFleet = "A1 A4,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10"
+
+// benchmarkShots are all cells of the classic field in random order.
+func benchmarkShots() []coordinates.Coordinate {
+	shots := make([]coordinates.Coordinate, 0, 100)
+	for x := uint(0); x < 10; x++ {
+		for y := uint(0); y < 10; y++ {
+			shots = append(shots, coordinates.Coordinate{X: x, Y: y})
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(shots), func(i, j int) { shots[i], shots[j] = shots[j], shots[i] })
+	return shots
+}
+
+// BenchmarkService_Game plays the classic game on the Field.
+func BenchmarkService_Game(b *testing.B) {
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+	shots := benchmarkShots()
+	names := make([]string, len(shots))
+	for i, c := range shots {
+		names[i] = c.String()
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewService(l)
+		_ = s.createField(fieldOptions{size: 10, rules: RulesClassic}, caller{})
+		_ = s.addShipsByCoordinates(classicFleet, caller{})
+		for _, c := range names {
+			if res, _ := s.shot(c, caller{}); res.End {
+				break
+			}
+		}
+	}
+}
+
+// BenchmarkBoard_Game plays the classic game on the Board.
+func BenchmarkBoard_Game(b *testing.B) {
+	shots := benchmarkShots()
+	ships, _ := makeShipsFromCoords(classicFleet, coordinates.Square)
+	board := NewBoard(10)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		board.Reset()
+		for _, sh := range ships {
+			_ = board.Place(sh.c[0], sh.c[1])
+		}
+		for _, c := range shots {
+			if res, _ := board.Shot(c); res.End {
+				break
+			}
+		}
+	}
+}
+
+// BenchmarkPlaceShip places the classic fleet on the Field.
+func BenchmarkPlaceShip(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ships, _ := makeShipsFromCoords(classicFleet, coordinates.Square)
+		field := NewField(10).field
+		for _, sh := range ships {
+			_ = placeShip(field, 10, sh)
+		}
+	}
+}
+
+// BenchmarkBoard_Place places the classic fleet on the Board.
+func BenchmarkBoard_Place(b *testing.B) {
+	ships, _ := makeShipsFromCoords(classicFleet, coordinates.Square)
+	board := NewBoard(10)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		board.Reset()
+		for _, sh := range ships {
+			_ = board.Place(sh.c[0], sh.c[1])
+		}
+	}
+}
@@ -2,16 +2,36 @@ package battlefield
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type service interface {
-	createField(size uint) error
-	clearField() error
-	addShipsByCoordinates(coords string) error
-	shot(coordinate string) (shotResult, error)
+	createField(opts fieldOptions, cl caller) error
+	clearField(cl caller) error
+	addShipsByCoordinates(coords string, cl caller) error
+	autoPlaceShips(lengths []int, cl caller) error
+	validateShips(coords string, cl caller) ([]HTTPError, error)
+	exportShips(cl caller) (Layout, error)
+	importShips(l Layout, cl caller) error
+	moveShip(id int, direction string, cl caller) (string, error)
+	repairShip(id int, coordinate string, cl caller) (int, error)
+	placeMines(coords string, cl caller) error
+	pauseGame(id string, cl caller) error
+	resumeGame(id string, cl caller) error
+	pausedGames(cl caller) ([]pausedGame, error)
+	history(q historyQuery, cl caller) ([]archivedGame, int, error)
+	historyRecord(id string, cl caller) (archivedGame, error)
+	historyReplay(id string, cl caller) (archivedGame, []replayFrame, error)
+	shot(coordinate string, cl caller) (shotResult, error)
+	sonar(coordinate string, cl caller) (bool, error)
+	radar(line string, cl caller) (int, error)
+	bomb(coordinate string, cl caller) ([]cellShot, error)
 	state() state
+	stats() statsReport
+	heatmap(size uint) (heatmap, error)
+	view(cl caller, fog bool) fieldView
 }
 
 // NewEndpoints creates new Endpoints.
@@ -28,6 +48,32 @@ type Endpoints struct {
 // CreateFieldRequest collect params for createField request.
 type CreateFieldRequest struct {
 	Size uint `json:"range"`
+	// Rules is the rules preset, "free" if empty.
+	Rules string `json:"rules,omitempty" enums:"free,classic,tetromino"`
+	// Grid is the grid of the field, "square" if empty.
+	Grid string `json:"grid,omitempty" enums:"square,hex"`
+	// Wrap makes the field a torus: ships may cross its edges
+	// and cells at opposite edges touch each other.
+	Wrap bool `json:"wrap,omitempty"`
+	// Advanced gives the attacker limited weapons: sonar, radar and bomb.
+	Advanced bool `json:"advanced,omitempty"`
+	// Moving lets the owner move one undamaged ship by one cell
+	// after every move of the attacker.
+	Moving bool `json:"moving,omitempty"`
+	// Repairs is the number of hit cells the owner can repair on every ship
+	// after moves of the attacker.
+	Repairs int `json:"repairs,omitempty"`
+	// Mines is the number of mines the owner hides among cells without ships.
+	Mines int `json:"mines,omitempty"`
+	// MinePenalty is the penalty of the attacker who hits a mine, "skip" if empty.
+	// "retaliate" gives the owner a free turn instead of the shot and needs moves or repairs.
+	MinePenalty string `json:"mine_penalty,omitempty" enums:"retaliate,skip"`
+	// Seed makes random moves of the game reproducible, random if empty.
+	Seed *int64 `json:"seed,omitempty"`
+	// Terrain is islands and reefs of the field.
+	Terrain *Terrain `json:"terrain,omitempty"`
+	// Map is the name of the terrain preset, can't be set with Terrain.
+	Map string `json:"map,omitempty" enums:"archipelago,strait"`
 }
 
 // CreateFieldResponse created for swagger docs.
@@ -38,10 +84,18 @@ func (r CreateFieldResponse) StatusCode() int {
 	return http.StatusCreated
 }
 
-func (e Endpoints) createFieldEndpoint(r CreateFieldRequest) (CreateFieldResponse, error) {
+func (e Endpoints) createFieldEndpoint(cl caller, r CreateFieldRequest) (CreateFieldResponse, error) {
 	e.logger.WithField("CreateFieldRequest", r).Debug("Endpoints: createFieldEndpoint started")
 
-	err := e.service.createField(r.Size)
+	seed := newSeed()
+	if r.Seed != nil {
+		seed = *r.Seed
+	}
+	opts := fieldOptions{size: r.Size, rules: r.Rules, grid: r.Grid, wrap: r.Wrap, advanced: r.Advanced, moving: r.Moving, repairs: r.Repairs, mines: r.Mines, minePenalty: r.MinePenalty, seed: seed, mapName: r.Map}
+	if r.Terrain != nil {
+		opts.terrain = *r.Terrain
+	}
+	err := e.service.createField(opts, cl)
 	return CreateFieldResponse{}, err
 }
 
@@ -53,10 +107,10 @@ func (r ClearFieldResponse) StatusCode() int {
 	return http.StatusOK
 }
 
-func (e Endpoints) clearFieldEndpoint() (ClearFieldResponse, error) {
+func (e Endpoints) clearFieldEndpoint(cl caller) (ClearFieldResponse, error) {
 	e.logger.Debug("Endpoints: clearFieldEndpoint started")
 
-	err := e.service.clearField()
+	err := e.service.clearField(cl)
 	return ClearFieldResponse{}, err
 }
 
@@ -73,13 +127,142 @@ func (r AddShipsResponse) StatusCode() int {
 	return http.StatusCreated
 }
 
-func (e Endpoints) addShipsEndpoint(req AddShipsRequest) (AddShipsResponse, error) {
+func (e Endpoints) addShipsEndpoint(cl caller, req AddShipsRequest) (AddShipsResponse, error) {
 	e.logger.Debug("Endpoints: addShipsEndpoint started")
 
-	err := e.service.addShipsByCoordinates(req.Coords)
+	err := e.service.addShipsByCoordinates(req.Coords, cl)
+	return AddShipsResponse{}, err
+}
+
+// AutoPlaceShipsRequest collect params for autoPlaceShips request.
+type AutoPlaceShipsRequest struct {
+	// Ships are lengths of ships, the rules fleet is used if empty.
+	Ships []int `json:"ships,omitempty"`
+}
+
+func (e Endpoints) autoPlaceShipsEndpoint(cl caller, req AutoPlaceShipsRequest) (AddShipsResponse, error) {
+	e.logger.Debug("Endpoints: autoPlaceShipsEndpoint started")
+
+	err := e.service.autoPlaceShips(req.Ships, cl)
 	return AddShipsResponse{}, err
 }
 
+// ValidateShipsResponse lists all problems of ships placement.
+type ValidateShipsResponse struct {
+	Valid    bool        `json:"valid"`
+	Problems []HTTPError `json:"problems"`
+}
+
+func (e Endpoints) validateShipsEndpoint(cl caller, req AddShipsRequest) (ValidateShipsResponse, error) {
+	e.logger.Debug("Endpoints: validateShipsEndpoint started")
+
+	problems, err := e.service.validateShips(req.Coords, cl)
+	if err != nil {
+		return ValidateShipsResponse{}, err
+	}
+	if problems == nil {
+		problems = []HTTPError{}
+	}
+	return ValidateShipsResponse{
+		Valid:    len(problems) == 0,
+		Problems: problems,
+	}, nil
+}
+
+func (e Endpoints) exportShipsEndpoint(cl caller) (Layout, error) {
+	e.logger.Debug("Endpoints: exportShipsEndpoint started")
+
+	return e.service.exportShips(cl)
+}
+
+func (e Endpoints) importShipsEndpoint(cl caller, l Layout) (AddShipsResponse, error) {
+	e.logger.Debug("Endpoints: importShipsEndpoint started")
+
+	err := e.service.importShips(l, cl)
+	return AddShipsResponse{}, err
+}
+
+// MoveShipRequest collect params for moveShip request.
+type MoveShipRequest struct {
+	// ID is zero-based index of the ship in the order it was added,
+	// it's taken from the path.
+	ID int `json:"-"`
+	// Direction is up, down, left or right on the square grid,
+	// left, right, up-left, up-right, down-left or down-right on the hex grid.
+	Direction string `json:"direction" enums:"up,down,left,right,up-left,up-right,down-left,down-right"`
+}
+
+// MoveShipResponse contains the ship at the new place.
+type MoveShipResponse struct {
+	// Ship is the ship in the format of AddShipsRequest, e.g. "A2 A5".
+	Ship string `json:"ship"`
+}
+
+// StatusCode implements StatusCoder.
+func (r MoveShipResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) moveShipEndpoint(cl caller, req MoveShipRequest) (MoveShipResponse, error) {
+	e.logger.WithField("MoveShipRequest", req).Debug("Endpoints: moveShipEndpoint started")
+
+	sh, err := e.service.moveShip(req.ID, req.Direction, cl)
+	if err != nil {
+		return MoveShipResponse{}, err
+	}
+	return MoveShipResponse{Ship: sh}, nil
+}
+
+// RepairShipRequest collect params for repairShip request.
+type RepairShipRequest struct {
+	// ID is zero-based index of the ship in the order it was added,
+	// it's taken from the path.
+	ID int `json:"-"`
+	// Coord is the hit cell of the ship, e.g. "A1".
+	Coord string `json:"coord"`
+}
+
+// RepairShipResponse reports repairs left for the ship.
+type RepairShipResponse struct {
+	Repairs int `json:"repairs"`
+}
+
+// StatusCode implements StatusCoder.
+func (r RepairShipResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) repairShipEndpoint(cl caller, req RepairShipRequest) (RepairShipResponse, error) {
+	e.logger.Debug("Endpoints: repairShipEndpoint started")
+
+	left, err := e.service.repairShip(req.ID, req.Coord, cl)
+	if err != nil {
+		return RepairShipResponse{}, err
+	}
+	return RepairShipResponse{Repairs: left}, nil
+}
+
+// PlaceMinesRequest collect params for placeMines request.
+type PlaceMinesRequest struct {
+	// Coords are cells of mines separated by commas, e.g. "C3,E5".
+	Coords string `json:"Coordinates"`
+}
+
+// PlaceMinesResponse created for swagger docs.
+type PlaceMinesResponse struct{}
+
+// StatusCode implements StatusCoder.
+func (r PlaceMinesResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+func (e Endpoints) placeMinesEndpoint(cl caller, req PlaceMinesRequest) (PlaceMinesResponse, error) {
+	e.logger.Debug("Endpoints: placeMinesEndpoint started")
+
+	err := e.service.placeMines(req.Coords, cl)
+	return PlaceMinesResponse{}, err
+}
+
 // ShotRequest collect params for shot request.
 type ShotRequest struct {
 	Coord string `json:"coord"`
@@ -90,6 +273,17 @@ type ShotResponse struct {
 	Destroy bool `json:"destroy"`
 	Knock   bool `json:"knock"`
 	End     bool `json:"end"`
+	// Terrain is the kind of terrain absorbed the shot,
+	// empty if the shot hit the water or a ship.
+	Terrain string `json:"terrain,omitempty" enums:"reef"`
+	// Mine is set if the shot hit the mine, Penalty is the penalty
+	// of the attacker: the owner gets a free turn or the attacker
+	// loses the next turn.
+	Mine    bool   `json:"mine,omitempty"`
+	Penalty string `json:"penalty,omitempty" enums:"retaliate,skip"`
+	// Skipped is set if the shot spent the turn lost on the mine
+	// and wasn't fired.
+	Skipped bool `json:"skipped,omitempty"`
 }
 
 // StatusCode implements StatusCoder.
@@ -97,10 +291,10 @@ func (r ShotResponse) StatusCode() int {
 	return http.StatusOK
 }
 
-func (e Endpoints) shotEndpoint(req ShotRequest) (ShotResponse, error) {
+func (e Endpoints) shotEndpoint(cl caller, req ShotRequest) (ShotResponse, error) {
 	e.logger.Debug("Endpoints: shotEndpoint started")
 
-	res, err := e.service.shot(req.Coord)
+	res, err := e.service.shot(req.Coord, cl)
 	if err != nil {
 		return ShotResponse{}, err
 	}
@@ -108,15 +302,127 @@ func (e Endpoints) shotEndpoint(req ShotRequest) (ShotResponse, error) {
 		Destroy: res.Destroy,
 		Knock:   res.Knock,
 		End:     res.End,
+		Terrain: res.Terrain,
+		Mine:    res.Mine,
+		Penalty: res.Penalty,
+		Skipped: res.Skipped,
 	}, nil
 }
 
+// SonarResponse reports if ships are found by sonar.
+type SonarResponse struct {
+	// Found is true if any ship cell which isn't shot yet is in the area.
+	Found bool `json:"found"`
+}
+
+// StatusCode implements StatusCoder.
+func (r SonarResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) sonarEndpoint(cl caller, req ShotRequest) (SonarResponse, error) {
+	e.logger.Debug("Endpoints: sonarEndpoint started")
+
+	found, err := e.service.sonar(req.Coord, cl)
+	if err != nil {
+		return SonarResponse{}, err
+	}
+	return SonarResponse{Found: found}, nil
+}
+
+// RadarRequest collect params for radar request.
+type RadarRequest struct {
+	// Line is the column letter, e.g. "C", or the row number, e.g. "3".
+	Line string `json:"line"`
+}
+
+// RadarResponse reports ships found by radar.
+type RadarResponse struct {
+	// Cells is the number of ship cells which aren't shot yet on the line.
+	Cells int `json:"cells"`
+}
+
+// StatusCode implements StatusCoder.
+func (r RadarResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) radarEndpoint(cl caller, req RadarRequest) (RadarResponse, error) {
+	e.logger.Debug("Endpoints: radarEndpoint started")
+
+	n, err := e.service.radar(req.Line, cl)
+	if err != nil {
+		return RadarResponse{}, err
+	}
+	return RadarResponse{Cells: n}, nil
+}
+
+// BombShot is the result of the bomb at one cell.
+type BombShot struct {
+	Coord   string `json:"coord"`
+	Destroy bool   `json:"destroy"`
+	Knock   bool   `json:"knock"`
+	Terrain string `json:"terrain,omitempty" enums:"reef"`
+	Mine    bool   `json:"mine,omitempty"`
+	Penalty string `json:"penalty,omitempty" enums:"retaliate,skip"`
+}
+
+// BombResponse contains results of the bomb at every shot cell.
+type BombResponse struct {
+	Shots []BombShot `json:"shots"`
+	End   bool       `json:"end"`
+}
+
+// StatusCode implements StatusCoder.
+func (r BombResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) bombEndpoint(cl caller, req ShotRequest) (BombResponse, error) {
+	e.logger.Debug("Endpoints: bombEndpoint started")
+
+	shots, err := e.service.bomb(req.Coord, cl)
+	if err != nil {
+		return BombResponse{}, err
+	}
+	resp := BombResponse{Shots: make([]BombShot, len(shots))}
+	for i, sh := range shots {
+		resp.Shots[i] = BombShot{
+			Coord:   sh.coord.String(),
+			Destroy: sh.Destroy,
+			Knock:   sh.Knock,
+			Terrain: sh.Terrain,
+			Mine:    sh.Mine,
+			Penalty: sh.Penalty,
+		}
+		resp.End = resp.End || sh.End
+	}
+	return resp, nil
+}
+
 // StateResponse defines state response.
 type StateResponse struct {
-	ShipCount int `json:"ship_count"`
-	Destroyed int `json:"destroyed"`
-	Knocked   int `json:"knocked"`
-	ShotCount int `json:"shot_count"`
+	// Game identifies the current game, empty if the field is not set.
+	Game string `json:"game,omitempty"`
+	// Version is incremented on every change of the game.
+	Version uint64 `json:"version"`
+	// Seed is the seed of random moves of the game.
+	Seed      int64 `json:"seed"`
+	ShipCount int   `json:"ship_count"`
+	Destroyed int   `json:"destroyed"`
+	Knocked   int   `json:"knocked"`
+	ShotCount int   `json:"shot_count"`
+	// MineCount is the number of hidden mines, MinesHit is the number
+	// of mines hit, Retaliations is the number of free turns of the owner left.
+	MineCount    int `json:"mine_count,omitempty"`
+	MinesHit     int `json:"mines_hit,omitempty"`
+	Retaliations int `json:"retaliations,omitempty"`
+	// Weapons are weapons left, omitted if the advanced mode is off.
+	Weapons map[string]int `json:"weapons,omitempty"`
+	// Paused is set while the game is paused, Clock is the play time
+	// of the game in seconds, it's stopped while the game is paused.
+	Paused bool  `json:"paused,omitempty"`
+	Clock  int64 `json:"clock,omitempty"`
 }
 
 // StatusCode implements StatusCoder.
@@ -129,9 +435,401 @@ func (e Endpoints) stateEndpoint() StateResponse {
 
 	state := e.service.state()
 	return StateResponse{
-		ShipCount: state.shipCount,
-		Destroyed: state.destroyed,
-		Knocked:   state.knocked,
-		ShotCount: state.shotCount,
+		Game:         state.game,
+		Version:      state.version,
+		Seed:         state.seed,
+		ShipCount:    state.shipCount,
+		Destroyed:    state.destroyed,
+		Knocked:      state.knocked,
+		ShotCount:    state.shotCount,
+		Weapons:      state.weapons,
+		MineCount:    state.mineCount,
+		MinesHit:     state.minesHit,
+		Retaliations: state.retaliations,
+		Paused:       state.paused,
+		Clock:        int64(state.clock / time.Second),
+	}
+}
+
+// PauseGameResponse created for swagger docs.
+type PauseGameResponse struct{}
+
+// StatusCode implements StatusCoder.
+func (r PauseGameResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) pauseGameEndpoint(cl caller, id string) (PauseGameResponse, error) {
+	e.logger.WithField("game", id).Debug("Endpoints: pauseGameEndpoint started")
+
+	err := e.service.pauseGame(id, cl)
+	return PauseGameResponse{}, err
+}
+
+func (e Endpoints) resumeGameEndpoint(cl caller, id string) (PauseGameResponse, error) {
+	e.logger.WithField("game", id).Debug("Endpoints: resumeGameEndpoint started")
+
+	err := e.service.resumeGame(id, cl)
+	return PauseGameResponse{}, err
+}
+
+// PausedGame describes the paused game.
+type PausedGame struct {
+	ID       string    `json:"id"`
+	Owner    string    `json:"owner,omitempty"`
+	Attacker string    `json:"attacker,omitempty"`
+	Size     uint      `json:"range"`
+	Rules    string    `json:"rules"`
+	PausedAt time.Time `json:"paused_at"`
+	// Clock is the play time of the game in seconds.
+	Clock int64 `json:"clock"`
+}
+
+// PausedGamesResponse lists paused games of the player.
+type PausedGamesResponse struct {
+	Games []PausedGame `json:"games"`
+}
+
+// StatusCode implements StatusCoder.
+func (r PausedGamesResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) pausedGamesEndpoint(cl caller) (PausedGamesResponse, error) {
+	e.logger.Debug("Endpoints: pausedGamesEndpoint started")
+
+	games, err := e.service.pausedGames(cl)
+	if err != nil {
+		return PausedGamesResponse{}, err
+	}
+	resp := PausedGamesResponse{Games: make([]PausedGame, len(games))}
+	for i, g := range games {
+		resp.Games[i] = PausedGame{
+			ID:       g.id,
+			Owner:    g.owner,
+			Attacker: g.attacker,
+			Size:     g.size,
+			Rules:    g.rules,
+			PausedAt: g.pausedAt,
+			Clock:    int64(g.clock / time.Second),
+		}
+	}
+	return resp, nil
+}
+
+// HistoryRequest filters archived games, empty fields match all games.
+type HistoryRequest struct {
+	// Player is the owner or the attacker of the game.
+	Player string
+	// From and To limit the time the game is finished, To is excluded.
+	From time.Time
+	To   time.Time
+	// Rules is the rules preset of the game.
+	Rules string
+	// Outcome is the outcome of the game.
+	Outcome string
+	// Offset is the number of skipped games, Limit is the page size.
+	Offset int
+	Limit  int
+}
+
+// HistoryGame describes the archived game.
+type HistoryGame struct {
+	ID       string `json:"id"`
+	Owner    string `json:"owner,omitempty"`
+	Attacker string `json:"attacker,omitempty"`
+	Size     uint   `json:"range"`
+	Rules    string `json:"rules"`
+	// Outcome is "won" if the attacker destroyed all ships,
+	// "abandoned" if the game was paused for too long.
+	Outcome  string    `json:"outcome" enums:"won,abandoned"`
+	Created  time.Time `json:"created"`
+	Finished time.Time `json:"finished"`
+	// Duration is the play time of the game in seconds, pauses excluded.
+	Duration int64 `json:"duration"`
+	// Moves is the number of moves of both players.
+	Moves int `json:"moves"`
+}
+
+// HistoryResponse is the page of archived games from the last finished one.
+type HistoryResponse struct {
+	Games []HistoryGame `json:"games"`
+	// Total is the number of games matching the filters.
+	Total int `json:"total"`
+}
+
+// StatusCode implements StatusCoder.
+func (r HistoryResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+// HistoryMove is the recorded move of the game.
+type HistoryMove struct {
+	Kind   string    `json:"kind" enums:"ships,auto,mines,shot,sonar,radar,bomb,move,repair,skip,pause,resume"`
+	Player string    `json:"player,omitempty"`
+	Arg    string    `json:"arg"`
+	At     time.Time `json:"at"`
+}
+
+// HistoryRecordResponse is the archived game with everything to replay it:
+// the game is created with Game params and then moves are made in order.
+type HistoryRecordResponse struct {
+	HistoryGame
+	Game CreateFieldRequest `json:"game"`
+	Log  []HistoryMove      `json:"log"`
+	// Board is the final field as seen by the owner,
+	// Fog is the final field as seen by the attacker.
+	Board []string `json:"board"`
+	Fog   []string `json:"fog"`
+}
+
+// StatusCode implements StatusCoder.
+func (r HistoryRecordResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func newHistoryGame(g archivedGame) HistoryGame {
+	return HistoryGame{
+		ID:       g.ID,
+		Owner:    g.Owner,
+		Attacker: g.Attacker,
+		Size:     g.Size,
+		Rules:    g.Rules,
+		Outcome:  g.Outcome,
+		Created:  g.Created,
+		Finished: g.Finished,
+		Duration: int64(g.Clock / time.Second),
+		Moves:    g.Moves,
+	}
+}
+
+func (e Endpoints) historyEndpoint(cl caller, r HistoryRequest) (HistoryResponse, error) {
+	e.logger.WithField("HistoryRequest", r).Debug("Endpoints: historyEndpoint started")
+
+	q := historyQuery{player: r.Player, from: r.From, to: r.To, rules: r.Rules, outcome: r.Outcome, offset: r.Offset, limit: r.Limit}
+	games, total, err := e.service.history(q, cl)
+	if err != nil {
+		return HistoryResponse{}, err
+	}
+	resp := HistoryResponse{Games: make([]HistoryGame, len(games)), Total: total}
+	for i, g := range games {
+		resp.Games[i] = newHistoryGame(g)
+	}
+	return resp, nil
+}
+
+func (e Endpoints) historyRecordEndpoint(cl caller, id string) (HistoryRecordResponse, error) {
+	e.logger.WithField("game", id).Debug("Endpoints: historyRecordEndpoint started")
+
+	g, err := e.service.historyRecord(id, cl)
+	if err != nil {
+		return HistoryRecordResponse{}, err
+	}
+	seed := g.Seed
+	resp := HistoryRecordResponse{
+		HistoryGame: newHistoryGame(g),
+		Game: CreateFieldRequest{
+			Size:        g.Size,
+			Rules:       g.Rules,
+			Grid:        g.Grid,
+			Wrap:        g.Wrap,
+			Advanced:    g.Advanced,
+			Moving:      g.Moving,
+			Repairs:     g.Repairs,
+			Mines:       g.Mines,
+			MinePenalty: g.MinePenalty,
+			Seed:        &seed,
+			Terrain:     g.Terrain,
+		},
+		Log:   make([]HistoryMove, len(g.Log)),
+		Board: g.Board,
+		Fog:   g.Fog,
+	}
+	for i, ev := range g.Log {
+		resp.Log[i] = HistoryMove{Kind: ev.Kind, Player: ev.Player, Arg: ev.Arg, At: ev.At}
+	}
+	return resp, nil
+}
+
+// ReplayFrame is the field seen by the owner after the move.
+type ReplayFrame struct {
+	// Move is empty for the field before the first move.
+	Move  *HistoryMove `json:"move,omitempty"`
+	Board []string     `json:"board"`
+}
+
+// HistoryReplayResponse is the archived game replayed move by move,
+// it's rendered as an animated image or a page.
+type HistoryReplayResponse struct {
+	HistoryGame
+	Grid   string        `json:"grid"`
+	Frames []ReplayFrame `json:"frames"`
+}
+
+func (e Endpoints) historyReplayEndpoint(cl caller, id string) (HistoryReplayResponse, error) {
+	e.logger.WithField("game", id).Debug("Endpoints: historyReplayEndpoint started")
+
+	g, frames, err := e.service.historyReplay(id, cl)
+	if err != nil {
+		return HistoryReplayResponse{}, err
+	}
+	grid, _, _ := gridByName(g.Grid)
+	resp := HistoryReplayResponse{
+		HistoryGame: newHistoryGame(g),
+		Grid:        grid,
+		Frames:      make([]ReplayFrame, len(frames)),
+	}
+	for i, f := range frames {
+		resp.Frames[i].Board = f.rows
+		if f.move != nil {
+			resp.Frames[i].Move = &HistoryMove{Kind: f.move.Kind, Player: f.move.Player, Arg: f.move.Arg, At: f.move.At}
+		}
+	}
+	return resp, nil
+}
+
+// ShotStats describes accuracy of shots.
+type ShotStats struct {
+	Shots   int     `json:"shots"`
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	// LongestStreak is the longest sequence of hits in a row.
+	LongestStreak int `json:"longest_streak"`
+	// ShotsToFirstHit is zero if there are no hits,
+	// average of finished games for players.
+	ShotsToFirstHit float64 `json:"shots_to_first_hit"`
+	Sunk            int     `json:"sunk"`
+	// ShotsPerSunk is zero if no ships are sunk.
+	ShotsPerSunk float64 `json:"shots_per_sunk"`
+}
+
+func newShotStats(shots, hits, sunk, longestStreak int) ShotStats {
+	st := ShotStats{
+		Shots:         shots,
+		Hits:          hits,
+		Misses:        shots - hits,
+		LongestStreak: longestStreak,
+		Sunk:          sunk,
+	}
+	if shots > 0 {
+		st.HitRate = float64(hits) / float64(shots)
+	}
+	if sunk > 0 {
+		st.ShotsPerSunk = float64(shots) / float64(sunk)
+	}
+	return st
+}
+
+// PlayerStats describes finished games of the attacker.
+type PlayerStats struct {
+	Games int `json:"games"`
+	ShotStats
+}
+
+// StatsResponse defines stats response.
+type StatsResponse struct {
+	// Game is statistics of the current game, empty if ships are not placed.
+	Game *ShotStats `json:"game,omitempty"`
+	// Games is the number of finished games.
+	Games int `json:"games"`
+	// Players is statistics of finished games by attacker,
+	// "anonymous" if authentication is disabled.
+	Players map[string]PlayerStats `json:"players"`
+}
+
+// StatusCode implements StatusCoder.
+func (r StatsResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) statsEndpoint() StatsResponse {
+	e.logger.Debug("Endpoints: statsEndpoint started")
+
+	report := e.service.stats()
+	resp := StatsResponse{
+		Games:   report.games,
+		Players: make(map[string]PlayerStats, len(report.players)),
+	}
+	if report.playing {
+		g := report.game
+		st := newShotStats(g.shots, g.hits, g.sunk, g.longestStreak)
+		st.ShotsToFirstHit = float64(g.firstHit)
+		resp.Game = &st
+	}
+	for name, p := range report.players {
+		st := newShotStats(p.Shots, p.Hits, p.Sunk, p.LongestStreak)
+		if p.Games > 0 {
+			st.ShotsToFirstHit = float64(p.FirstHits) / float64(p.Games)
+		}
+		resp.Players[name] = PlayerStats{Games: p.Games, ShotStats: st}
+	}
+	return resp
+}
+
+// HeatmapResponse counts shots and hits of every cell across finished games.
+// Cells are indexed by row, then by column: Shots[1][0] is cell A2.
+type HeatmapResponse struct {
+	Size  uint    `json:"size"`
+	Games int     `json:"games"`
+	Shots [][]int `json:"shots"`
+	Hits  [][]int `json:"hits"`
+}
+
+// StatusCode implements StatusCoder.
+func (r HeatmapResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (e Endpoints) heatmapEndpoint(size uint) (HeatmapResponse, error) {
+	e.logger.WithField("size", size).Debug("Endpoints: heatmapEndpoint started")
+
+	h, err := e.service.heatmap(size)
+	if err != nil {
+		return HeatmapResponse{}, err
+	}
+	return HeatmapResponse{
+		Size:  uint(len(h.Shots)),
+		Games: h.Games,
+		Shots: h.Shots,
+		Hits:  h.Hits,
+	}, nil
+}
+
+// FieldResponse is the field as seen by the player. Rows are cells
+// row by row: "." water or unknown, "#" ship, "x" hit, "X" sunk ship,
+// "o" miss, "^" island, "~" reef, "*" shot reef.
+type FieldResponse struct {
+	Game    string `json:"game,omitempty"`
+	Version uint64 `json:"version"`
+	Size    uint   `json:"size"`
+	Grid    string `json:"grid" enums:"square,hex"`
+	// Fog is true if ships which are not hit are hidden.
+	Fog  bool     `json:"fog"`
+	Rows []string `json:"rows"`
+}
+
+// StatusCode implements StatusCoder.
+func (r FieldResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+// Text returns the field as text with column letters and row numbers,
+// rows of hex grid are shifted to show the rhombus.
+func (r FieldResponse) Text() []byte {
+	return renderField(r.Rows, r.Grid == GridHex)
+}
+
+func (e Endpoints) fieldEndpoint(cl caller, fog bool) FieldResponse {
+	e.logger.WithField("fog", fog).Debug("Endpoints: fieldEndpoint started")
+
+	v := e.service.view(cl, fog)
+	return FieldResponse{
+		Game:    v.game,
+		Version: v.version,
+		Size:    uint(len(v.rows)),
+		Grid:    v.grid,
+		Fog:     v.fog,
+		Rows:    v.rows,
 	}
 }
@@ -3,9 +3,13 @@ package battlefield
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+	"my/battleship/storage"
 )
 
 func TestNewEndpoints(t *testing.T) {
@@ -39,6 +43,90 @@ func TestCreateFieldEndpoint(t *testing.T) {
 			want:    CreateFieldResponse{},
 			wantErr: nil,
 		},
+		{
+			name:    "success, classic rules",
+			args:    args{req: CreateFieldRequest{Size: 10, Rules: RulesClassic}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, seed",
+			args:    args{req: CreateFieldRequest{Size: 3, Seed: func() *int64 { seed := int64(42); return &seed }()}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, hex grid",
+			args:    args{req: CreateFieldRequest{Size: 10, Rules: RulesClassic, Grid: GridHex}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, advanced",
+			args:    args{req: CreateFieldRequest{Size: 10, Advanced: true}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, repairs",
+			args:    args{req: CreateFieldRequest{Size: 10, Repairs: 2}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, moving",
+			args:    args{req: CreateFieldRequest{Size: 10, Moving: true}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, wrap",
+			args:    args{req: CreateFieldRequest{Size: 10, Rules: RulesClassic, Wrap: true}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "error, unknown grid",
+			args:    args{req: CreateFieldRequest{Size: 10, Grid: "triangle"}},
+			want:    CreateFieldResponse{},
+			wantErr: errorUnknownGrid,
+		},
+		{
+			name:    "success, map",
+			args:    args{req: CreateFieldRequest{Size: 10, Map: "strait"}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "success, terrain",
+			args:    args{req: CreateFieldRequest{Size: 3, Terrain: &Terrain{Islands: []string{"B2"}}}},
+			want:    CreateFieldResponse{},
+			wantErr: nil,
+		},
+		{
+			name:    "error, unknown map",
+			args:    args{req: CreateFieldRequest{Size: 10, Map: "atlantis"}},
+			want:    CreateFieldResponse{},
+			wantErr: errorUnknownMap,
+		},
+		{
+			name:    "error, map and terrain",
+			args:    args{req: CreateFieldRequest{Size: 10, Map: "strait", Terrain: &Terrain{Islands: []string{"B2"}}}},
+			want:    CreateFieldResponse{},
+			wantErr: errorInvalidTerrain,
+		},
+		{
+			name:    "error, classic rules with other size",
+			args:    args{req: CreateFieldRequest{Size: 8, Rules: RulesClassic}},
+			want:    CreateFieldResponse{},
+			wantErr: errorInvalidFieldSize,
+		},
+		{
+			name:    "error, unknown rules",
+			args:    args{req: CreateFieldRequest{Size: 8, Rules: "chess"}},
+			want:    CreateFieldResponse{},
+			wantErr: errorUnknownRules,
+		},
 		{
 			name:    "error, zero-size",
 			args:    args{req: CreateFieldRequest{Size: 0}},
@@ -61,9 +149,12 @@ func TestCreateFieldEndpoint(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := e.createFieldEndpoint(tt.args.req)
+			resp, err := e.createFieldEndpoint(caller{}, tt.args.req)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, resp)
+			if tt.args.req.Seed != nil {
+				assert.Equal(t, *tt.args.req.Seed, e.service.(*Service).f.seed)
+			}
 		})
 	}
 }
@@ -107,7 +198,7 @@ func TestClearFieldEndpoint(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := e.clearFieldEndpoint()
+			resp, err := e.clearFieldEndpoint(caller{admin: true})
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, resp)
 		})
@@ -167,13 +258,436 @@ func TestAddShipsEndpoint(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := e.addShipsEndpoint(tt.args.req)
+			resp, err := e.addShipsEndpoint(caller{}, tt.args.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestAutoPlaceShipsEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		req     AutoPlaceShipsRequest
+		want    AddShipsResponse
+		wantErr error
+	}{
+		{
+			name:  "success",
+			field: NewField(3),
+			req:   AutoPlaceShipsRequest{Ships: []int{2, 1}},
+			want:  AddShipsResponse{},
+		},
+		{
+			name:    "error, no fleet",
+			field:   NewField(3),
+			want:    AddShipsResponse{},
+			wantErr: errorInvalidInputParams,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger:  l,
+			service: &Service{f: tt.field, logger: l},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.autoPlaceShipsEndpoint(caller{}, tt.req)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, resp)
 		})
 	}
 }
 
+func TestValidateShipsEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		req     AddShipsRequest
+		want    ValidateShipsResponse
+		wantErr error
+	}{
+		{
+			name:  "success, valid",
+			field: Field{field: [][]cell{{{}}}, size: 1},
+			req:   AddShipsRequest{Coords: "A1 A1"},
+			want:  ValidateShipsResponse{Valid: true, Problems: []HTTPError{}},
+		},
+		{
+			name:  "success, invalid",
+			field: Field{field: [][]cell{{{}}}, size: 1},
+			req:   AddShipsRequest{Coords: "A1 A1,B1 B1"},
+			want: ValidateShipsResponse{Problems: []HTTPError{
+				errorOutOfBonds.withShip(1).withCoord(coordinates.Coordinate{X: 1, Y: 0}),
+			}},
+		},
+		{
+			name:    "error",
+			field:   Field{owner: "alice"},
+			req:     AddShipsRequest{Coords: "A1 A1"},
+			want:    ValidateShipsResponse{},
+			wantErr: errorNotBoardOwner,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger:  l,
+			service: &Service{f: tt.field, logger: l},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.validateShipsEndpoint(caller{player: "bob"}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestExportShipsEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		want    Layout
+		wantErr error
+	}{
+		{
+			name: "success",
+			field: Field{
+				size:       2,
+				rules:      RulesFree,
+				shipsAdded: true,
+				ships:      []*ship{newShip(coordinates.Coordinate{X: 0, Y: 0}, coordinates.Coordinate{X: 0, Y: 1})},
+			},
+			want: Layout{Size: 2, Rules: RulesFree, Ships: []string{"A1 A2"}},
+		},
+		{
+			name:    "error, ships not placed",
+			field:   Field{size: 2},
+			want:    Layout{},
+			wantErr: errorShipsNotPlaced,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger:  l,
+			service: &Service{f: tt.field, logger: l},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.exportShipsEndpoint(caller{})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestImportShipsEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Layout
+		want    AddShipsResponse
+		wantErr error
+	}{
+		{
+			name: "success",
+			req:  Layout{Size: 1, Rules: RulesFree, Ships: []string{"A1 A1"}},
+			want: AddShipsResponse{},
+		},
+		{
+			name:    "error",
+			req:     Layout{Size: 2, Rules: RulesFree, Ships: []string{"A1 A1"}},
+			want:    AddShipsResponse{},
+			wantErr: errorLayoutMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger: l,
+			service: &Service{
+				f:      Field{field: [][]cell{{{}}}, size: 1, rules: RulesFree},
+				logger: l,
+			},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.importShipsEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestMoveShipResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, MoveShipResponse{}.StatusCode())
+}
+
+func TestMoveShipEndpoint(t *testing.T) {
+	sh := newShip(coordinates.Coordinate{X: 0, Y: 0}, coordinates.Coordinate{X: 0, Y: 0})
+	newField := func() Field {
+		f := NewField(2)
+		f.moving = true
+		f.defenderTurn = true
+		f.shipsAdded = true
+		f.ships = []*ship{sh}
+		occupy(f.field, f.size, sh)
+		return f
+	}
+
+	tests := []struct {
+		name    string
+		field   Field
+		req     MoveShipRequest
+		want    MoveShipResponse
+		wantErr error
+	}{
+		{
+			name:  "success",
+			field: newField(),
+			req:   MoveShipRequest{ID: 0, Direction: DirectionRight},
+			want:  MoveShipResponse{Ship: "B1 B1"},
+		},
+		{
+			name:    "error, unknown ship",
+			field:   newField(),
+			req:     MoveShipRequest{ID: 1, Direction: DirectionRight},
+			wantErr: errorShipNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.moveShipEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestRepairShipResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, RepairShipResponse{}.StatusCode())
+}
+
+func TestRepairShipEndpoint(t *testing.T) {
+	newField := func() Field {
+		sh := newShip(coordinates.Coordinate{X: 0, Y: 0}, coordinates.Coordinate{X: 1, Y: 0})
+		sh.aliveCells = 1
+		sh.isKnocked = true
+		f := NewField(2)
+		f.repairs = 2
+		f.defenderTurn = true
+		f.shipsAdded = true
+		f.ships = []*ship{sh}
+		f.state.knocked = 1
+		occupy(f.field, f.size, sh)
+		f.field[0][0].shot = true
+		return f
+	}
+
+	tests := []struct {
+		name    string
+		field   Field
+		req     RepairShipRequest
+		want    RepairShipResponse
+		wantErr error
+	}{
+		{
+			name:  "success",
+			field: newField(),
+			req:   RepairShipRequest{ID: 0, Coord: "A1"},
+			want:  RepairShipResponse{Repairs: 1},
+		},
+		{
+			name:    "error, unknown ship",
+			field:   newField(),
+			req:     RepairShipRequest{ID: 1, Coord: "A1"},
+			wantErr: errorShipNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.repairShipEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestPauseGameEndpoint(t *testing.T) {
+	l := logrus.New()
+	s := NewService(l)
+	s.SetStore(storage.NewMemory())
+	alice := caller{player: "alice"}
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+	e := Endpoints{logger: l, service: s}
+
+	_, err := e.pauseGameEndpoint(alice, "0123456789abcdef")
+	assert.Equal(t, errorGameNotFound, err)
+	resp, err := e.pauseGameEndpoint(alice, s.f.id)
+	assert.NoError(t, err)
+	assert.Equal(t, PauseGameResponse{}, resp)
+	assert.True(t, e.stateEndpoint().Paused)
+
+	games, err := e.pausedGamesEndpoint(alice)
+	assert.NoError(t, err)
+	if assert.Len(t, games.Games, 1) {
+		assert.Equal(t, PausedGame{
+			ID:       s.f.id,
+			Owner:    "alice",
+			Size:     3,
+			Rules:    s.f.rules,
+			PausedAt: s.f.pausedAt,
+			Clock:    int64(s.f.clock(s.f.pausedAt) / time.Second),
+		}, games.Games[0])
+	}
+
+	resp, err = e.resumeGameEndpoint(alice, s.f.id)
+	assert.NoError(t, err)
+	assert.Equal(t, PauseGameResponse{}, resp)
+	assert.False(t, e.stateEndpoint().Paused)
+	_, err = e.resumeGameEndpoint(alice, s.f.id)
+	assert.Equal(t, errorGameNotPaused, err)
+}
+
+func TestHistoryEndpoints(t *testing.T) {
+	l := logrus.New()
+	s := NewService(l)
+	s.SetStore(storage.NewMemory())
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	assert.NoError(t, s.createField(fieldOptions{size: 2, seed: 7, mines: 1}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+	assert.NoError(t, s.placeMines("B2", alice))
+	_, err := s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+	e := Endpoints{logger: l, service: s}
+
+	game := HistoryGame{
+		ID:       s.f.id,
+		Owner:    "alice",
+		Attacker: "bob",
+		Size:     2,
+		Rules:    RulesFree,
+		Outcome:  OutcomeWon,
+		Created:  s.f.created,
+		Finished: s.f.log[2].At,
+		Duration: int64(s.f.clock(time.Now()) / time.Second),
+		Moves:    3,
+	}
+	resp, err := e.historyEndpoint(alice, HistoryRequest{Player: "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryResponse{Games: []HistoryGame{game}, Total: 1}, resp)
+	resp, err = e.historyEndpoint(alice, HistoryRequest{Player: "carol"})
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryResponse{Games: []HistoryGame{}}, resp)
+	// games of other players aren't listed
+	resp, err = e.historyEndpoint(caller{player: "carol"}, HistoryRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryResponse{Games: []HistoryGame{}}, resp)
+	_, err = e.historyEndpoint(alice, HistoryRequest{Outcome: "draw"})
+	assert.Equal(t, errorInvalidInputParams, err)
+
+	seed := int64(7)
+	record, err := e.historyRecordEndpoint(bob, s.f.id)
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryRecordResponse{
+		HistoryGame: game,
+		Game:        CreateFieldRequest{Size: 2, Rules: RulesFree, Mines: 1, MinePenalty: MinePenaltySkip, Seed: &seed},
+		Log: []HistoryMove{
+			{Kind: eventShips, Player: "alice", Arg: "A1 A1", At: s.f.log[0].At},
+			{Kind: eventMines, Player: "alice", Arg: "B2", At: s.f.log[1].At},
+			{Kind: eventShot, Player: "bob", Arg: "A1", At: s.f.log[2].At},
+		},
+		Board: []string{"X.", ".+"},
+		Fog:   []string{"X.", ".."},
+	}, record)
+	_, err = e.historyRecordEndpoint(bob, "0123456789abcdef")
+	assert.Equal(t, errorGameNotFound, err)
+	_, err = e.historyRecordEndpoint(caller{player: "carol"}, s.f.id)
+	assert.Equal(t, errorNotGamePlayer, err)
+}
+
+func TestHistoryReplayEndpoint(t *testing.T) {
+	l := logrus.New()
+	s := NewService(l)
+	s.SetStore(storage.NewMemory())
+	assert.NoError(t, s.createField(fieldOptions{size: 2, grid: GridHex}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{}))
+	_, err := s.shot("A1", caller{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+	e := Endpoints{logger: l, service: s}
+
+	resp, err := e.historyReplayEndpoint(caller{}, s.f.id)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.id, resp.ID)
+	assert.Equal(t, GridHex, resp.Grid)
+	assert.Equal(t, []ReplayFrame{
+		{Board: []string{"..", ".."}},
+		{Move: &HistoryMove{Kind: eventShips, Arg: "A1 A1", At: s.f.log[0].At}, Board: []string{"#.", ".."}},
+		{Move: &HistoryMove{Kind: eventShot, Arg: "A1", At: s.f.log[1].At}, Board: []string{"X.", ".."}},
+	}, resp.Frames)
+
+	_, err = e.historyReplayEndpoint(caller{}, "0123456789abcdef")
+	assert.Equal(t, errorGameNotFound, err)
+}
+
+func TestPlaceMinesResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusCreated, PlaceMinesResponse{}.StatusCode())
+}
+
+func TestPlaceMinesEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		req     PlaceMinesRequest
+		wantErr error
+	}{
+		{
+			name: "success",
+			field: Field{
+				field:      [][]cell{{{ship: &ship{aliveCells: 1}}, {}}},
+				size:       2,
+				shipsAdded: true,
+				mines:      1,
+			},
+			req: PlaceMinesRequest{Coords: "A2"},
+		},
+		{
+			name:    "error, mines are off",
+			field:   Field{field: [][]cell{{{}}}, size: 1, shipsAdded: true},
+			req:     PlaceMinesRequest{Coords: "A1"},
+			wantErr: errorMinesDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.placeMinesEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, PlaceMinesResponse{}, resp)
+		})
+	}
+}
+
 func TestShotsResponse_StatusCode(t *testing.T) {
 	want := http.StatusOK
 	got := ShotResponse{}.StatusCode()
@@ -209,6 +723,52 @@ func TestShotEndpoint(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "success, reef",
+			args: args{
+				field: Field{
+					field:      [][]cell{{{terrain: terrainReef}, {ship: &ship{aliveCells: 1}}}},
+					size:       2,
+					shipsAlive: 1,
+					shipsAdded: true,
+				},
+				req: ShotRequest{Coord: "A1"},
+			},
+			want:    ShotResponse{Terrain: TerrainReef},
+			wantErr: nil,
+		},
+		{
+			name: "success, mine",
+			args: args{
+				field: Field{
+					field:       [][]cell{{{mine: true}, {ship: &ship{aliveCells: 1}}}},
+					size:        2,
+					shipsAlive:  1,
+					shipsAdded:  true,
+					mines:       1,
+					minesAdded:  true,
+					minePenalty: MinePenaltySkip,
+				},
+				req: ShotRequest{Coord: "A1"},
+			},
+			want:    ShotResponse{Mine: true, Penalty: MinePenaltySkip},
+			wantErr: nil,
+		},
+		{
+			name: "success, turn lost on the mine",
+			args: args{
+				field: Field{
+					field:      [][]cell{{{}, {ship: &ship{aliveCells: 1}}}},
+					size:       2,
+					shipsAlive: 1,
+					shipsAdded: true,
+					turnLost:   true,
+				},
+				req: ShotRequest{Coord: "A1"},
+			},
+			want:    ShotResponse{Skipped: true},
+			wantErr: nil,
+		},
 		{
 			name: "error",
 			args: args{
@@ -233,7 +793,165 @@ func TestShotEndpoint(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := e.shotEndpoint(tt.args.req)
+			resp, err := e.shotEndpoint(caller{}, tt.args.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestSonarResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, SonarResponse{}.StatusCode())
+}
+
+func TestSonarEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		req     ShotRequest
+		want    SonarResponse
+		wantErr error
+	}{
+		{
+			name: "success, found",
+			field: Field{
+				field:      [][]cell{{{}, {}}, {{}, {ship: &ship{aliveCells: 1}}}},
+				size:       2,
+				shipsAlive: 1,
+				shipsAdded: true,
+				weapons:    newInventory(true),
+			},
+			req:  ShotRequest{Coord: "A1"},
+			want: SonarResponse{Found: true},
+		},
+		{
+			name: "error, weapons disabled",
+			field: Field{
+				field:      [][]cell{{{ship: &ship{aliveCells: 1}}}},
+				size:       1,
+				shipsAlive: 1,
+				shipsAdded: true,
+			},
+			req:     ShotRequest{Coord: "A1"},
+			wantErr: errorWeaponsDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.sonarEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestRadarResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, RadarResponse{}.StatusCode())
+}
+
+func TestRadarEndpoint(t *testing.T) {
+	sh := &ship{aliveCells: 2}
+	tests := []struct {
+		name    string
+		field   Field
+		req     RadarRequest
+		want    RadarResponse
+		wantErr error
+	}{
+		{
+			name: "success",
+			field: Field{
+				field:      [][]cell{{{ship: sh}, {ship: sh}}, {{}, {}}},
+				size:       2,
+				shipsAlive: 1,
+				shipsAdded: true,
+				weapons:    newInventory(true),
+			},
+			req:  RadarRequest{Line: "A"},
+			want: RadarResponse{Cells: 2},
+		},
+		{
+			name: "error, invalid line",
+			field: Field{
+				field:      [][]cell{{{ship: &ship{aliveCells: 1}}}},
+				size:       1,
+				shipsAlive: 1,
+				shipsAdded: true,
+				weapons:    newInventory(true),
+			},
+			req:     RadarRequest{Line: "B"},
+			wantErr: errorInvalidCoordinate,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.radarEndpoint(caller{}, tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestBombResponse_StatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, BombResponse{}.StatusCode())
+}
+
+func TestBombEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		req     ShotRequest
+		want    BombResponse
+		wantErr error
+	}{
+		{
+			name: "success",
+			field: Field{
+				field:       [][]cell{{{terrain: terrainReef}, {ship: &ship{aliveCells: 1}}}, {{mine: true}, {}}},
+				size:        2,
+				shipsAlive:  1,
+				shipsAdded:  true,
+				weapons:     newInventory(true),
+				minePenalty: MinePenaltyRetaliate,
+			},
+			req: ShotRequest{Coord: "A1"},
+			want: BombResponse{
+				Shots: []BombShot{
+					{Coord: "A1", Terrain: TerrainReef},
+					{Coord: "B1", Mine: true, Penalty: MinePenaltyRetaliate},
+					{Coord: "A2", Knock: true, Destroy: true},
+				},
+				End: true,
+			},
+		},
+		{
+			name: "error, weapon exhausted",
+			field: Field{
+				field:      [][]cell{{{ship: &ship{aliveCells: 1}}}},
+				size:       1,
+				shipsAlive: 1,
+				shipsAdded: true,
+				weapons:    map[string]int{WeaponBomb: 0},
+			},
+			req:     ShotRequest{Coord: "A1"},
+			wantErr: errorWeaponExhausted,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{logger: l, service: &Service{f: tt.field, logger: l}}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.bombEndpoint(caller{}, tt.req)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, resp)
 		})
@@ -259,6 +977,7 @@ func TestStateEndpoint(t *testing.T) {
 		{
 			name: "success",
 			args: args{f: Field{
+				id: "42",
 				state: state{
 					knocked:   1,
 					destroyed: 1,
@@ -268,12 +987,31 @@ func TestStateEndpoint(t *testing.T) {
 			},
 			},
 			want: StateResponse{
+				Game:      "42",
 				Knocked:   1,
 				Destroyed: 1,
 				ShipCount: 3,
 				ShotCount: 5,
 			},
 		},
+		{
+			name: "success, weapons",
+			args: args{f: Field{id: "42", weapons: map[string]int{WeaponSonar: 1, WeaponRadar: 0, WeaponBomb: 1}}},
+			want: StateResponse{
+				Game:    "42",
+				Weapons: map[string]int{WeaponSonar: 1, WeaponRadar: 0, WeaponBomb: 1},
+			},
+		},
+		{
+			name: "success, mines",
+			args: args{f: Field{id: "42", state: state{mineCount: 3, minesHit: 2, retaliations: 1}}},
+			want: StateResponse{
+				Game:         "42",
+				MineCount:    3,
+				MinesHit:     2,
+				Retaliations: 1,
+			},
+		},
 		{
 			name: "success, game is not started",
 			args: args{f: Field{}},
@@ -299,3 +1037,187 @@ func TestStateEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestStatsResponse_StatusCode(t *testing.T) {
+	want := http.StatusOK
+	got := StatsResponse{}.StatusCode()
+	assert.Equal(t, want, got)
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		service *Service
+		want    StatsResponse
+	}{
+		{
+			name:    "success, no games",
+			service: &Service{},
+			want:    StatsResponse{Players: map[string]PlayerStats{}},
+		},
+		{
+			name: "success",
+			service: &Service{
+				f: Field{
+					shipsAdded: true,
+					stats:      gameStats{shots: 4, hits: 2, sunk: 1, longestStreak: 2, firstHit: 3},
+				},
+				total: aggregate{
+					Games: 2,
+					Players: map[string]*playerStats{
+						"bob": {Games: 2, Shots: 10, Hits: 5, Sunk: 4, LongestStreak: 3, FirstHits: 3},
+					},
+				},
+			},
+			want: StatsResponse{
+				Game: &ShotStats{
+					Shots:           4,
+					Hits:            2,
+					Misses:          2,
+					HitRate:         0.5,
+					LongestStreak:   2,
+					ShotsToFirstHit: 3,
+					Sunk:            1,
+					ShotsPerSunk:    4,
+				},
+				Games: 2,
+				Players: map[string]PlayerStats{
+					"bob": {Games: 2, ShotStats: ShotStats{
+						Shots:           10,
+						Hits:            5,
+						Misses:          5,
+						HitRate:         0.5,
+						LongestStreak:   3,
+						ShotsToFirstHit: 1.5,
+						Sunk:            4,
+						ShotsPerSunk:    2.5,
+					}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		tt.service.logger = l
+		e := Endpoints{logger: l, service: tt.service}
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, e.statsEndpoint())
+		})
+	}
+}
+
+func TestHeatmapResponse_StatusCode(t *testing.T) {
+	want := http.StatusOK
+	got := HeatmapResponse{}.StatusCode()
+	assert.Equal(t, want, got)
+}
+
+func TestHeatmapEndpoint(t *testing.T) {
+	total := aggregate{Heatmaps: map[uint]*heatmap{
+		1: {Games: 3, Shots: [][]int{{3}}, Hits: [][]int{{2}}},
+	}}
+
+	tests := []struct {
+		name    string
+		size    uint
+		field   Field
+		want    HeatmapResponse
+		wantErr error
+	}{
+		{
+			name: "success",
+			size: 1,
+			want: HeatmapResponse{Size: 1, Games: 3, Shots: [][]int{{3}}, Hits: [][]int{{2}}},
+		},
+		{
+			name:  "success, size of the current field",
+			field: Field{size: 1},
+			want:  HeatmapResponse{Size: 1, Games: 3, Shots: [][]int{{3}}, Hits: [][]int{{2}}},
+		},
+		{
+			name: "success, no games",
+			size: 2,
+			want: HeatmapResponse{Size: 2, Shots: [][]int{{0, 0}, {0, 0}}, Hits: [][]int{{0, 0}, {0, 0}}},
+		},
+		{
+			name:    "error, field is not set",
+			want:    HeatmapResponse{},
+			wantErr: errorInvalidFieldSize,
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger:  l,
+			service: &Service{f: tt.field, total: total, logger: l},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := e.heatmapEndpoint(tt.size)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestFieldResponse_StatusCode(t *testing.T) {
+	want := http.StatusOK
+	got := FieldResponse{}.StatusCode()
+	assert.Equal(t, want, got)
+}
+
+func TestFieldResponse_Text(t *testing.T) {
+	resp := FieldResponse{Size: 2, Rows: []string{"#.", ".^"}}
+	assert.Equal(t, "  A B\n1 # .\n2 . ^\n", string(resp.Text()))
+}
+
+func TestFieldEndpoint(t *testing.T) {
+	field := func() Field {
+		f := NewField(2)
+		f.id = "42"
+		f.version = 3
+		f.owner = "alice"
+		f.field[0][0] = cell{occupied: true, ship: &ship{aliveCells: 1}}
+		f.field[1][1].terrain = terrainIsland
+		return f
+	}
+
+	tests := []struct {
+		name string
+		cl   caller
+		fog  bool
+		want FieldResponse
+	}{
+		{
+			name: "success, owner",
+			cl:   caller{player: "alice"},
+			want: FieldResponse{Game: "42", Version: 3, Size: 2, Grid: GridSquare, Rows: []string{"#.", ".^"}},
+		},
+		{
+			name: "success, owner in fog",
+			cl:   caller{player: "alice"},
+			fog:  true,
+			want: FieldResponse{Game: "42", Version: 3, Size: 2, Grid: GridSquare, Fog: true, Rows: []string{"..", ".^"}},
+		},
+		{
+			name: "success, attacker",
+			cl:   caller{player: "bob"},
+			want: FieldResponse{Game: "42", Version: 3, Size: 2, Grid: GridSquare, Fog: true, Rows: []string{"..", ".^"}},
+		},
+	}
+
+	for _, tt := range tests {
+		l := logrus.New()
+		e := Endpoints{
+			logger:  l,
+			service: &Service{f: field(), logger: l},
+		}
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, e.fieldEndpoint(tt.cl, tt.fog))
+		})
+	}
+}
@@ -2,12 +2,89 @@ package battlefield
 
 import (
 	"encoding/json"
+
+	"my/battleship/coordinates"
+)
+
+// ErrorCode is stable machine-readable identifier of the error.
+type ErrorCode string
+
+// Error codes of HTTPError.
+const (
+	CodeInvalidInputParams    ErrorCode = "INVALID_INPUT_PARAMS"
+	CodeInvalidFieldSize      ErrorCode = "INVALID_FIELD_SIZE"
+	CodeFieldAlreadySet       ErrorCode = "FIELD_ALREADY_SET"
+	CodeInvalidCoordinate     ErrorCode = "INVALID_COORDINATE"
+	CodeCellOccupiedByShip    ErrorCode = "CELL_OCCUPIED_BY_SHIP"
+	CodeCellOccupiedNearby    ErrorCode = "CELL_OCCUPIED_NEARBY"
+	CodeShipsAlreadyAdded     ErrorCode = "SHIPS_ALREADY_ADDED"
+	CodeOutOfBounds           ErrorCode = "OUT_OF_BOUNDS"
+	CodeCellAlreadyShot       ErrorCode = "CELL_ALREADY_SHOT"
+	CodeShipsNotPlaced        ErrorCode = "SHIPS_NOT_PLACED"
+	CodeUnauthorized          ErrorCode = "UNAUTHORIZED"
+	CodeInvalidCredentials    ErrorCode = "INVALID_CREDENTIALS"
+	CodeTokenExpired          ErrorCode = "TOKEN_EXPIRED"
+	CodeAdminRequired         ErrorCode = "ADMIN_REQUIRED"
+	CodeNotBoardOwner         ErrorCode = "NOT_BOARD_OWNER"
+	CodeNotYourTurn           ErrorCode = "NOT_YOUR_TURN"
+	CodeTooManyRequests       ErrorCode = "TOO_MANY_REQUESTS"
+	CodeRequestTooLarge       ErrorCode = "REQUEST_TOO_LARGE"
+	CodeServerDraining        ErrorCode = "SERVER_DRAINING"
+	CodeStoreUnavailable      ErrorCode = "STORE_UNAVAILABLE"
+	CodeInvalidIdempotencyKey ErrorCode = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused  ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeVersionMismatch       ErrorCode = "VERSION_MISMATCH"
+	CodeUnknownRules          ErrorCode = "UNKNOWN_RULES"
+	CodeShipNotStraight       ErrorCode = "SHIP_NOT_STRAIGHT"
+	CodeFleetMismatch         ErrorCode = "FLEET_MISMATCH"
+	CodeInvalidLayout         ErrorCode = "INVALID_LAYOUT"
+	CodeLayoutMismatch        ErrorCode = "LAYOUT_MISMATCH"
+	CodeFleetDoesNotFit       ErrorCode = "FLEET_DOES_NOT_FIT"
+	CodeShipNotConnected      ErrorCode = "SHIP_NOT_CONNECTED"
+	CodeShapeNotAllowed       ErrorCode = "SHAPE_NOT_ALLOWED"
+	CodeUnknownMap            ErrorCode = "UNKNOWN_MAP"
+	CodeInvalidTerrain        ErrorCode = "INVALID_TERRAIN"
+	CodeShipOnTerrain         ErrorCode = "SHIP_ON_TERRAIN"
+	CodeCellNotShootable      ErrorCode = "CELL_NOT_SHOOTABLE"
+	CodeUnknownGrid           ErrorCode = "UNKNOWN_GRID"
+	CodeGridNotSupported      ErrorCode = "GRID_NOT_SUPPORTED"
+	CodeWeaponsDisabled       ErrorCode = "WEAPONS_DISABLED"
+	CodeWeaponExhausted       ErrorCode = "WEAPON_EXHAUSTED"
+	CodeMovesDisabled         ErrorCode = "MOVES_DISABLED"
+	CodeShipNotFound          ErrorCode = "SHIP_NOT_FOUND"
+	CodeShipDamaged           ErrorCode = "SHIP_DAMAGED"
+	CodeMinesDisabled         ErrorCode = "MINES_DISABLED"
+	CodeMinesNotPlaced        ErrorCode = "MINES_NOT_PLACED"
+	CodeMinesAlreadyPlaced    ErrorCode = "MINES_ALREADY_PLACED"
+	CodeMinesMismatch         ErrorCode = "MINES_MISMATCH"
+	CodeCellHasMine           ErrorCode = "CELL_HAS_MINE"
+	CodeTurnLost              ErrorCode = "TURN_LOST"
+	CodeRetaliateNotSupported ErrorCode = "RETALIATE_NOT_SUPPORTED"
+	CodeRepairsDisabled       ErrorCode = "REPAIRS_DISABLED"
+	CodeRepairsExhausted      ErrorCode = "REPAIRS_EXHAUSTED"
+	CodeShipDestroyed         ErrorCode = "SHIP_DESTROYED"
+	CodeCellNotDamaged        ErrorCode = "CELL_NOT_DAMAGED"
+	CodeGameNotFound          ErrorCode = "GAME_NOT_FOUND"
+	CodeNotGamePlayer         ErrorCode = "NOT_GAME_PLAYER"
+	CodeGameIsOver            ErrorCode = "GAME_IS_OVER"
+	CodeGamePaused            ErrorCode = "GAME_PAUSED"
+	CodeGameNotPaused         ErrorCode = "GAME_NOT_PAUSED"
 )
 
 // HTTPError represents json error with http code and error.
 type HTTPError struct {
-	Err  string `json:"err"`
-	Code int    `json:"-"`
+	ErrCode ErrorCode     `json:"code" enums:"INVALID_INPUT_PARAMS,INVALID_FIELD_SIZE,FIELD_ALREADY_SET,INVALID_COORDINATE,CELL_OCCUPIED_BY_SHIP,CELL_OCCUPIED_NEARBY,SHIPS_ALREADY_ADDED,OUT_OF_BOUNDS,CELL_ALREADY_SHOT,SHIPS_NOT_PLACED,UNAUTHORIZED,INVALID_CREDENTIALS,TOKEN_EXPIRED,ADMIN_REQUIRED,NOT_BOARD_OWNER,NOT_YOUR_TURN,TOO_MANY_REQUESTS,REQUEST_TOO_LARGE,SERVER_DRAINING,STORE_UNAVAILABLE,INVALID_IDEMPOTENCY_KEY,IDEMPOTENCY_KEY_REUSED,VERSION_MISMATCH,UNKNOWN_RULES,SHIP_NOT_STRAIGHT,FLEET_MISMATCH,INVALID_LAYOUT,LAYOUT_MISMATCH,FLEET_DOES_NOT_FIT,SHIP_NOT_CONNECTED,SHAPE_NOT_ALLOWED,UNKNOWN_MAP,INVALID_TERRAIN,SHIP_ON_TERRAIN,CELL_NOT_SHOOTABLE,UNKNOWN_GRID,GRID_NOT_SUPPORTED,WEAPONS_DISABLED,WEAPON_EXHAUSTED,MOVES_DISABLED,SHIP_NOT_FOUND,SHIP_DAMAGED,MINES_DISABLED,MINES_NOT_PLACED,MINES_ALREADY_PLACED,MINES_MISMATCH,CELL_HAS_MINE,TURN_LOST,RETALIATE_NOT_SUPPORTED,REPAIRS_DISABLED,REPAIRS_EXHAUSTED,SHIP_DESTROYED,CELL_NOT_DAMAGED,GAME_NOT_FOUND,NOT_GAME_PLAYER,GAME_IS_OVER,GAME_PAUSED,GAME_NOT_PAUSED"`
+	Err     string        `json:"err"`
+	Details *ErrorDetails `json:"details,omitempty"`
+	Code    int           `json:"-"`
+}
+
+// ErrorDetails describes what caused the error.
+type ErrorDetails struct {
+	// Ship is zero-based index of the ship in the request.
+	Ship *int `json:"ship,omitempty"`
+	// Coord is the coordinate caused the error, e.g. "A1".
+	Coord string `json:"coord,omitempty"`
 }
 
 // Error is implementation of error interface.
@@ -20,11 +97,43 @@ func (e HTTPError) StatusCode() int {
 	return e.Code
 }
 
+// Is reports if target is HTTPError with the same error code,
+// so errors.Is matches errors regardless of details.
+func (e HTTPError) Is(target error) bool {
+	t, ok := target.(HTTPError)
+	return ok && t.ErrCode == e.ErrCode
+}
+
+// withShip returns copy of the error referring to the ship
+// with provided index in the request.
+func (e HTTPError) withShip(i int) HTTPError {
+	d := ErrorDetails{}
+	if e.Details != nil {
+		d = *e.Details
+	}
+	d.Ship = &i
+	e.Details = &d
+	return e
+}
+
+// withCoord returns copy of the error referring to the coordinate.
+func (e HTTPError) withCoord(c coordinates.Coordinate) HTTPError {
+	d := ErrorDetails{}
+	if e.Details != nil {
+		d = *e.Details
+	}
+	d.Coord = c.String()
+	e.Details = &d
+	return e
+}
+
 // MarshalJSON is implementation of Marshaller interface.
 func (e HTTPError) MarshalJSON() ([]byte, error) {
 	type t struct {
-		Err  string `json:"err,omitempty"`
-		Code int    `json:"-"`
+		ErrCode ErrorCode     `json:"code,omitempty"`
+		Err     string        `json:"err,omitempty"`
+		Details *ErrorDetails `json:"details,omitempty"`
+		Code    int           `json:"-"`
 	}
 
 	// Casting is needed because json.Marshal(e) creates infinite recursion
@@ -34,52 +143,350 @@ func (e HTTPError) MarshalJSON() ([]byte, error) {
 
 var (
 	errorInvalidInputParams = HTTPError{
-		Err:  "invalid input params",
-		Code: 400,
+		ErrCode: CodeInvalidInputParams,
+		Err:     "invalid input params",
+		Code:    400,
 	}
 
 	errorInvalidFieldSize = HTTPError{
-		Err:  "field size is invalid",
-		Code: 400,
+		ErrCode: CodeInvalidFieldSize,
+		Err:     "field size is invalid",
+		Code:    400,
 	}
 
 	errorFieldAlreadySet = HTTPError{
-		Err:  "field is already set",
-		Code: 409,
+		ErrCode: CodeFieldAlreadySet,
+		Err:     "field is already set",
+		Code:    409,
 	}
 
 	errorInvalidCoordinate = HTTPError{
-		Err:  "invalid coordinate provided",
-		Code: 400,
+		ErrCode: CodeInvalidCoordinate,
+		Err:     "invalid coordinate provided",
+		Code:    400,
 	}
 
 	errorCellIsOccupiedByShip = HTTPError{
-		Err:  "can't place ships on top of each other",
-		Code: 400,
+		ErrCode: CodeCellOccupiedByShip,
+		Err:     "can't place ships on top of each other",
+		Code:    400,
 	}
 
 	errorCellIsOccupiedNearby = HTTPError{
-		Err:  "can't place ships close to each other",
-		Code: 400,
+		ErrCode: CodeCellOccupiedNearby,
+		Err:     "can't place ships close to each other",
+		Code:    400,
 	}
 
 	errorShipsAlreadyAdded = HTTPError{
-		Err:  "ships are already added",
-		Code: 400,
+		ErrCode: CodeShipsAlreadyAdded,
+		Err:     "ships are already added",
+		Code:    400,
 	}
 
 	errorOutOfBonds = HTTPError{
-		Err:  "out of bonds",
-		Code: 400,
+		ErrCode: CodeOutOfBounds,
+		Err:     "out of bonds",
+		Code:    400,
 	}
 
 	errorCellAlreadyShot = HTTPError{
-		Err:  "cell was already shot",
-		Code: 400,
+		ErrCode: CodeCellAlreadyShot,
+		Err:     "cell was already shot",
+		Code:    400,
 	}
 
 	errorShipsNotPlaced = HTTPError{
-		Err:  "ships not placed yet",
-		Code: 400,
+		ErrCode: CodeShipsNotPlaced,
+		Err:     "ships not placed yet",
+		Code:    400,
+	}
+
+	errorUnauthorized = HTTPError{
+		ErrCode: CodeUnauthorized,
+		Err:     "authentication required",
+		Code:    401,
+	}
+
+	errorInvalidCredentials = HTTPError{
+		ErrCode: CodeInvalidCredentials,
+		Err:     "invalid credentials",
+		Code:    401,
+	}
+
+	errorTokenExpired = HTTPError{
+		ErrCode: CodeTokenExpired,
+		Err:     "token expired",
+		Code:    401,
+	}
+
+	errorAdminRequired = HTTPError{
+		ErrCode: CodeAdminRequired,
+		Err:     "admin role required",
+		Code:    403,
+	}
+
+	errorNotBoardOwner = HTTPError{
+		ErrCode: CodeNotBoardOwner,
+		Err:     "only board owner can do this",
+		Code:    403,
+	}
+
+	errorNotYourTurn = HTTPError{
+		ErrCode: CodeNotYourTurn,
+		Err:     "it is not your turn",
+		Code:    403,
+	}
+
+	errorTooManyRequests = HTTPError{
+		ErrCode: CodeTooManyRequests,
+		Err:     "too many requests",
+		Code:    429,
+	}
+
+	errorRequestTooLarge = HTTPError{
+		ErrCode: CodeRequestTooLarge,
+		Err:     "request body is too large",
+		Code:    413,
+	}
+
+	errorDraining = HTTPError{
+		ErrCode: CodeServerDraining,
+		Err:     "server is shutting down",
+		Code:    503,
+	}
+
+	errorStoreUnavailable = HTTPError{
+		ErrCode: CodeStoreUnavailable,
+		Err:     "store is unavailable",
+		Code:    503,
+	}
+
+	errorInvalidIdempotencyKey = HTTPError{
+		ErrCode: CodeInvalidIdempotencyKey,
+		Err:     "idempotency key is too long",
+		Code:    400,
+	}
+
+	errorIdempotencyKeyReused = HTTPError{
+		ErrCode: CodeIdempotencyKeyReused,
+		Err:     "idempotency key is used for other request",
+		Code:    422,
+	}
+
+	errorVersionMismatch = HTTPError{
+		ErrCode: CodeVersionMismatch,
+		Err:     "game version doesn't match If-Match",
+		Code:    412,
+	}
+
+	errorUnknownRules = HTTPError{
+		ErrCode: CodeUnknownRules,
+		Err:     "unknown rules",
+		Code:    400,
+	}
+
+	errorShipNotStraight = HTTPError{
+		ErrCode: CodeShipNotStraight,
+		Err:     "ships should be one cell wide",
+		Code:    400,
+	}
+
+	errorFleetMismatch = HTTPError{
+		ErrCode: CodeFleetMismatch,
+		Err:     "ships don't match the rules",
+		Code:    400,
+	}
+
+	errorInvalidLayout = HTTPError{
+		ErrCode: CodeInvalidLayout,
+		Err:     "invalid layout",
+		Code:    400,
+	}
+
+	errorLayoutMismatch = HTTPError{
+		ErrCode: CodeLayoutMismatch,
+		Err:     "layout doesn't match the field",
+		Code:    409,
+	}
+
+	errorFleetDoesNotFit = HTTPError{
+		ErrCode: CodeFleetDoesNotFit,
+		Err:     "ships don't fit the field",
+		Code:    400,
+	}
+
+	errorShipNotConnected = HTTPError{
+		ErrCode: CodeShipNotConnected,
+		Err:     "ship cells are not connected",
+		Code:    400,
+	}
+
+	errorShapeNotAllowed = HTTPError{
+		ErrCode: CodeShapeNotAllowed,
+		Err:     "ship shape is not allowed",
+		Code:    400,
+	}
+
+	errorUnknownMap = HTTPError{
+		ErrCode: CodeUnknownMap,
+		Err:     "unknown map",
+		Code:    400,
+	}
+
+	errorInvalidTerrain = HTTPError{
+		ErrCode: CodeInvalidTerrain,
+		Err:     "invalid terrain",
+		Code:    400,
+	}
+
+	errorShipOnTerrain = HTTPError{
+		ErrCode: CodeShipOnTerrain,
+		Err:     "can't place ships on terrain",
+		Code:    400,
+	}
+
+	errorCellNotShootable = HTTPError{
+		ErrCode: CodeCellNotShootable,
+		Err:     "can't shoot at island",
+		Code:    400,
+	}
+
+	errorUnknownGrid = HTTPError{
+		ErrCode: CodeUnknownGrid,
+		Err:     "unknown grid",
+		Code:    400,
+	}
+
+	errorGridNotSupported = HTTPError{
+		ErrCode: CodeGridNotSupported,
+		Err:     "rules don't support the grid",
+		Code:    400,
+	}
+
+	errorWeaponsDisabled = HTTPError{
+		ErrCode: CodeWeaponsDisabled,
+		Err:     "weapons are disabled in the game",
+		Code:    400,
+	}
+
+	errorWeaponExhausted = HTTPError{
+		ErrCode: CodeWeaponExhausted,
+		Err:     "no weapon left",
+		Code:    400,
+	}
+
+	errorMovesDisabled = HTTPError{
+		ErrCode: CodeMovesDisabled,
+		Err:     "ships can't move in the game",
+		Code:    400,
+	}
+
+	errorShipNotFound = HTTPError{
+		ErrCode: CodeShipNotFound,
+		Err:     "ship not found",
+		Code:    404,
+	}
+
+	errorShipDamaged = HTTPError{
+		ErrCode: CodeShipDamaged,
+		Err:     "can't move damaged ship",
+		Code:    400,
+	}
+
+	errorMinesDisabled = HTTPError{
+		ErrCode: CodeMinesDisabled,
+		Err:     "mines are disabled in the game",
+		Code:    400,
+	}
+
+	errorMinesNotPlaced = HTTPError{
+		ErrCode: CodeMinesNotPlaced,
+		Err:     "mines not placed yet",
+		Code:    400,
+	}
+
+	errorMinesAlreadyPlaced = HTTPError{
+		ErrCode: CodeMinesAlreadyPlaced,
+		Err:     "mines already placed",
+		Code:    400,
+	}
+
+	errorMinesMismatch = HTTPError{
+		ErrCode: CodeMinesMismatch,
+		Err:     "number of mines doesn't match the game",
+		Code:    400,
+	}
+
+	errorCellHasMine = HTTPError{
+		ErrCode: CodeCellHasMine,
+		Err:     "cell holds a mine",
+		Code:    400,
+	}
+
+	errorTurnLost = HTTPError{
+		ErrCode: CodeTurnLost,
+		Err:     "turn is lost on the mine",
+		Code:    403,
+	}
+
+	errorRetaliateNotSupported = HTTPError{
+		ErrCode: CodeRetaliateNotSupported,
+		Err:     "retaliate penalty needs moving or repairs",
+		Code:    400,
+	}
+
+	errorRepairsDisabled = HTTPError{
+		ErrCode: CodeRepairsDisabled,
+		Err:     "ships can't be repaired in the game",
+		Code:    400,
+	}
+
+	errorRepairsExhausted = HTTPError{
+		ErrCode: CodeRepairsExhausted,
+		Err:     "no repairs left for the ship",
+		Code:    400,
+	}
+
+	errorShipDestroyed = HTTPError{
+		ErrCode: CodeShipDestroyed,
+		Err:     "can't repair destroyed ship",
+		Code:    400,
+	}
+
+	errorCellNotDamaged = HTTPError{
+		ErrCode: CodeCellNotDamaged,
+		Err:     "cell of the ship is not hit",
+		Code:    400,
+	}
+
+	errorGameNotFound = HTTPError{
+		ErrCode: CodeGameNotFound,
+		Err:     "game not found",
+		Code:    404,
+	}
+
+	errorNotGamePlayer = HTTPError{
+		ErrCode: CodeNotGamePlayer,
+		Err:     "only players of the game can do this",
+		Code:    403,
+	}
+
+	errorGameIsOver = HTTPError{
+		ErrCode: CodeGameIsOver,
+		Err:     "game is over",
+		Code:    400,
+	}
+
+	errorGamePaused = HTTPError{
+		ErrCode: CodeGamePaused,
+		Err:     "game is paused",
+		Code:    409,
+	}
+
+	errorGameNotPaused = HTTPError{
+		ErrCode: CodeGameNotPaused,
+		Err:     "game is not paused",
+		Code:    409,
 	}
 )
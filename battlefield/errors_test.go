@@ -1,10 +1,13 @@
 package battlefield
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
 )
 
 func TestHTTPError_Error(t *testing.T) {
@@ -63,6 +66,246 @@ func TestHTTPError_Error(t *testing.T) {
 			e:    errorShipsNotPlaced,
 			want: "ships not placed yet",
 		},
+		{
+			name: "errorUnauthorized",
+			e:    errorUnauthorized,
+			want: "authentication required",
+		},
+		{
+			name: "errorInvalidCredentials",
+			e:    errorInvalidCredentials,
+			want: "invalid credentials",
+		},
+		{
+			name: "errorTokenExpired",
+			e:    errorTokenExpired,
+			want: "token expired",
+		},
+		{
+			name: "errorAdminRequired",
+			e:    errorAdminRequired,
+			want: "admin role required",
+		},
+		{
+			name: "errorNotBoardOwner",
+			e:    errorNotBoardOwner,
+			want: "only board owner can do this",
+		},
+		{
+			name: "errorNotYourTurn",
+			e:    errorNotYourTurn,
+			want: "it is not your turn",
+		},
+		{
+			name: "errorTooManyRequests",
+			e:    errorTooManyRequests,
+			want: "too many requests",
+		},
+		{
+			name: "errorRequestTooLarge",
+			e:    errorRequestTooLarge,
+			want: "request body is too large",
+		},
+		{
+			name: "errorDraining",
+			e:    errorDraining,
+			want: "server is shutting down",
+		},
+		{
+			name: "errorStoreUnavailable",
+			e:    errorStoreUnavailable,
+			want: "store is unavailable",
+		},
+		{
+			name: "errorInvalidIdempotencyKey",
+			e:    errorInvalidIdempotencyKey,
+			want: "idempotency key is too long",
+		},
+		{
+			name: "errorIdempotencyKeyReused",
+			e:    errorIdempotencyKeyReused,
+			want: "idempotency key is used for other request",
+		},
+		{
+			name: "errorVersionMismatch",
+			e:    errorVersionMismatch,
+			want: "game version doesn't match If-Match",
+		},
+		{
+			name: "errorUnknownRules",
+			e:    errorUnknownRules,
+			want: "unknown rules",
+		},
+		{
+			name: "errorShipNotStraight",
+			e:    errorShipNotStraight,
+			want: "ships should be one cell wide",
+		},
+		{
+			name: "errorFleetMismatch",
+			e:    errorFleetMismatch,
+			want: "ships don't match the rules",
+		},
+		{
+			name: "errorInvalidLayout",
+			e:    errorInvalidLayout,
+			want: "invalid layout",
+		},
+		{
+			name: "errorLayoutMismatch",
+			e:    errorLayoutMismatch,
+			want: "layout doesn't match the field",
+		},
+		{
+			name: "errorFleetDoesNotFit",
+			e:    errorFleetDoesNotFit,
+			want: "ships don't fit the field",
+		},
+		{
+			name: "errorShipNotConnected",
+			e:    errorShipNotConnected,
+			want: "ship cells are not connected",
+		},
+		{
+			name: "errorShapeNotAllowed",
+			e:    errorShapeNotAllowed,
+			want: "ship shape is not allowed",
+		},
+		{
+			name: "errorUnknownMap",
+			e:    errorUnknownMap,
+			want: "unknown map",
+		},
+		{
+			name: "errorInvalidTerrain",
+			e:    errorInvalidTerrain,
+			want: "invalid terrain",
+		},
+		{
+			name: "errorShipOnTerrain",
+			e:    errorShipOnTerrain,
+			want: "can't place ships on terrain",
+		},
+		{
+			name: "errorCellNotShootable",
+			e:    errorCellNotShootable,
+			want: "can't shoot at island",
+		},
+		{
+			name: "errorUnknownGrid",
+			e:    errorUnknownGrid,
+			want: "unknown grid",
+		},
+		{
+			name: "errorGridNotSupported",
+			e:    errorGridNotSupported,
+			want: "rules don't support the grid",
+		},
+		{
+			name: "errorWeaponsDisabled",
+			e:    errorWeaponsDisabled,
+			want: "weapons are disabled in the game",
+		},
+		{
+			name: "errorWeaponExhausted",
+			e:    errorWeaponExhausted,
+			want: "no weapon left",
+		},
+		{
+			name: "errorMovesDisabled",
+			e:    errorMovesDisabled,
+			want: "ships can't move in the game",
+		},
+		{
+			name: "errorShipNotFound",
+			e:    errorShipNotFound,
+			want: "ship not found",
+		},
+		{
+			name: "errorShipDamaged",
+			e:    errorShipDamaged,
+			want: "can't move damaged ship",
+		},
+		{
+			name: "errorMinesDisabled",
+			e:    errorMinesDisabled,
+			want: "mines are disabled in the game",
+		},
+		{
+			name: "errorMinesNotPlaced",
+			e:    errorMinesNotPlaced,
+			want: "mines not placed yet",
+		},
+		{
+			name: "errorMinesAlreadyPlaced",
+			e:    errorMinesAlreadyPlaced,
+			want: "mines already placed",
+		},
+		{
+			name: "errorMinesMismatch",
+			e:    errorMinesMismatch,
+			want: "number of mines doesn't match the game",
+		},
+		{
+			name: "errorCellHasMine",
+			e:    errorCellHasMine,
+			want: "cell holds a mine",
+		},
+		{
+			name: "errorTurnLost",
+			e:    errorTurnLost,
+			want: "turn is lost on the mine",
+		},
+		{
+			name: "errorRepairsDisabled",
+			e:    errorRepairsDisabled,
+			want: "ships can't be repaired in the game",
+		},
+		{
+			name: "errorRepairsExhausted",
+			e:    errorRepairsExhausted,
+			want: "no repairs left for the ship",
+		},
+		{
+			name: "errorShipDestroyed",
+			e:    errorShipDestroyed,
+			want: "can't repair destroyed ship",
+		},
+		{
+			name: "errorCellNotDamaged",
+			e:    errorCellNotDamaged,
+			want: "cell of the ship is not hit",
+		},
+		{
+			name: "errorGameNotFound",
+			e:    errorGameNotFound,
+			want: "game not found",
+		},
+		{
+			name: "errorNotGamePlayer",
+			e:    errorNotGamePlayer,
+			want: "only players of the game can do this",
+		},
+		{
+			name: "errorGameIsOver",
+			e:    errorGameIsOver,
+			want: "game is over",
+		},
+		{
+			name: "errorGamePaused",
+			e:    errorGamePaused,
+			want: "game is paused",
+		},
+		{
+			name: "errorGameNotPaused",
+			e:    errorGameNotPaused,
+			want: "game is not paused",
+		},
+		{
+			name: "errorRetaliateNotSupported",
+			e:    errorRetaliateNotSupported,
+			want: "retaliate penalty needs moving or repairs",
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,6 +372,246 @@ func TestHTTPError_StatusCode(t *testing.T) {
 			e:    errorShipsNotPlaced,
 			want: http.StatusBadRequest,
 		},
+		{
+			name: "errorUnauthorized",
+			e:    errorUnauthorized,
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "errorInvalidCredentials",
+			e:    errorInvalidCredentials,
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "errorTokenExpired",
+			e:    errorTokenExpired,
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "errorAdminRequired",
+			e:    errorAdminRequired,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "errorNotBoardOwner",
+			e:    errorNotBoardOwner,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "errorNotYourTurn",
+			e:    errorNotYourTurn,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "errorTooManyRequests",
+			e:    errorTooManyRequests,
+			want: http.StatusTooManyRequests,
+		},
+		{
+			name: "errorRequestTooLarge",
+			e:    errorRequestTooLarge,
+			want: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "errorDraining",
+			e:    errorDraining,
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "errorStoreUnavailable",
+			e:    errorStoreUnavailable,
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "errorInvalidIdempotencyKey",
+			e:    errorInvalidIdempotencyKey,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorIdempotencyKeyReused",
+			e:    errorIdempotencyKeyReused,
+			want: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "errorVersionMismatch",
+			e:    errorVersionMismatch,
+			want: http.StatusPreconditionFailed,
+		},
+		{
+			name: "errorUnknownRules",
+			e:    errorUnknownRules,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShipNotStraight",
+			e:    errorShipNotStraight,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorFleetMismatch",
+			e:    errorFleetMismatch,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorInvalidLayout",
+			e:    errorInvalidLayout,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorLayoutMismatch",
+			e:    errorLayoutMismatch,
+			want: http.StatusConflict,
+		},
+		{
+			name: "errorFleetDoesNotFit",
+			e:    errorFleetDoesNotFit,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShipNotConnected",
+			e:    errorShipNotConnected,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShapeNotAllowed",
+			e:    errorShapeNotAllowed,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorUnknownMap",
+			e:    errorUnknownMap,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorInvalidTerrain",
+			e:    errorInvalidTerrain,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShipOnTerrain",
+			e:    errorShipOnTerrain,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorCellNotShootable",
+			e:    errorCellNotShootable,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorUnknownGrid",
+			e:    errorUnknownGrid,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorGridNotSupported",
+			e:    errorGridNotSupported,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorWeaponsDisabled",
+			e:    errorWeaponsDisabled,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorWeaponExhausted",
+			e:    errorWeaponExhausted,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorMovesDisabled",
+			e:    errorMovesDisabled,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShipNotFound",
+			e:    errorShipNotFound,
+			want: http.StatusNotFound,
+		},
+		{
+			name: "errorShipDamaged",
+			e:    errorShipDamaged,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorMinesDisabled",
+			e:    errorMinesDisabled,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorMinesNotPlaced",
+			e:    errorMinesNotPlaced,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorMinesAlreadyPlaced",
+			e:    errorMinesAlreadyPlaced,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorMinesMismatch",
+			e:    errorMinesMismatch,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorCellHasMine",
+			e:    errorCellHasMine,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorTurnLost",
+			e:    errorTurnLost,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "errorRepairsDisabled",
+			e:    errorRepairsDisabled,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorRepairsExhausted",
+			e:    errorRepairsExhausted,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorShipDestroyed",
+			e:    errorShipDestroyed,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorCellNotDamaged",
+			e:    errorCellNotDamaged,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorGameNotFound",
+			e:    errorGameNotFound,
+			want: http.StatusNotFound,
+		},
+		{
+			name: "errorNotGamePlayer",
+			e:    errorNotGamePlayer,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "errorGameIsOver",
+			e:    errorGameIsOver,
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "errorGamePaused",
+			e:    errorGamePaused,
+			want: http.StatusConflict,
+		},
+		{
+			name: "errorGameNotPaused",
+			e:    errorGameNotPaused,
+			want: http.StatusConflict,
+		},
+		{
+			name: "errorRetaliateNotSupported",
+			e:    errorRetaliateNotSupported,
+			want: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -149,61 +632,349 @@ func TestHTTPError_MarshalJSON(t *testing.T) {
 		{
 			name:    "errorInvalidInputParams",
 			e:       errorInvalidInputParams,
-			want:    `{"err":"invalid input params"}`,
+			want:    `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorInvalidFieldSize",
 			e:       errorInvalidFieldSize,
-			want:    `{"err":"field size is invalid"}`,
+			want:    `{"code":"INVALID_FIELD_SIZE","err":"field size is invalid"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorFieldAlreadySet",
 			e:       errorFieldAlreadySet,
-			want:    `{"err":"field is already set"}`,
+			want:    `{"code":"FIELD_ALREADY_SET","err":"field is already set"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorInvalidCoordinate",
 			e:       errorInvalidCoordinate,
-			want:    `{"err":"invalid coordinate provided"}`,
+			want:    `{"code":"INVALID_COORDINATE","err":"invalid coordinate provided"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorCellIsOccupiedByShip",
 			e:       errorCellIsOccupiedByShip,
-			want:    `{"err":"can't place ships on top of each other"}`,
+			want:    `{"code":"CELL_OCCUPIED_BY_SHIP","err":"can't place ships on top of each other"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorCellIsOccupiedNearby",
 			e:       errorCellIsOccupiedNearby,
-			want:    `{"err":"can't place ships close to each other"}`,
+			want:    `{"code":"CELL_OCCUPIED_NEARBY","err":"can't place ships close to each other"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorShipsAlreadyAdded",
 			e:       errorShipsAlreadyAdded,
-			want:    `{"err":"ships are already added"}`,
+			want:    `{"code":"SHIPS_ALREADY_ADDED","err":"ships are already added"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorOutOfBonds",
 			e:       errorOutOfBonds,
-			want:    `{"err":"out of bonds"}`,
+			want:    `{"code":"OUT_OF_BOUNDS","err":"out of bonds"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorCellAlreadyShot",
 			e:       errorCellAlreadyShot,
-			want:    `{"err":"cell was already shot"}`,
+			want:    `{"code":"CELL_ALREADY_SHOT","err":"cell was already shot"}`,
 			wantErr: nil,
 		},
 		{
 			name:    "errorShipsNotPlaced",
 			e:       errorShipsNotPlaced,
-			want:    `{"err":"ships not placed yet"}`,
+			want:    `{"code":"SHIPS_NOT_PLACED","err":"ships not placed yet"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorUnauthorized",
+			e:       errorUnauthorized,
+			want:    `{"code":"UNAUTHORIZED","err":"authentication required"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorInvalidCredentials",
+			e:       errorInvalidCredentials,
+			want:    `{"code":"INVALID_CREDENTIALS","err":"invalid credentials"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorTokenExpired",
+			e:       errorTokenExpired,
+			want:    `{"code":"TOKEN_EXPIRED","err":"token expired"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorAdminRequired",
+			e:       errorAdminRequired,
+			want:    `{"code":"ADMIN_REQUIRED","err":"admin role required"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorNotBoardOwner",
+			e:       errorNotBoardOwner,
+			want:    `{"code":"NOT_BOARD_OWNER","err":"only board owner can do this"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorNotYourTurn",
+			e:       errorNotYourTurn,
+			want:    `{"code":"NOT_YOUR_TURN","err":"it is not your turn"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorTooManyRequests",
+			e:       errorTooManyRequests,
+			want:    `{"code":"TOO_MANY_REQUESTS","err":"too many requests"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorRequestTooLarge",
+			e:       errorRequestTooLarge,
+			want:    `{"code":"REQUEST_TOO_LARGE","err":"request body is too large"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorDraining",
+			e:       errorDraining,
+			want:    `{"code":"SERVER_DRAINING","err":"server is shutting down"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorStoreUnavailable",
+			e:       errorStoreUnavailable,
+			want:    `{"code":"STORE_UNAVAILABLE","err":"store is unavailable"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorInvalidIdempotencyKey",
+			e:       errorInvalidIdempotencyKey,
+			want:    `{"code":"INVALID_IDEMPOTENCY_KEY","err":"idempotency key is too long"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorIdempotencyKeyReused",
+			e:       errorIdempotencyKeyReused,
+			want:    `{"code":"IDEMPOTENCY_KEY_REUSED","err":"idempotency key is used for other request"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorVersionMismatch",
+			e:       errorVersionMismatch,
+			want:    `{"code":"VERSION_MISMATCH","err":"game version doesn't match If-Match"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorUnknownRules",
+			e:       errorUnknownRules,
+			want:    `{"code":"UNKNOWN_RULES","err":"unknown rules"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipNotStraight",
+			e:       errorShipNotStraight,
+			want:    `{"code":"SHIP_NOT_STRAIGHT","err":"ships should be one cell wide"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorFleetMismatch",
+			e:       errorFleetMismatch,
+			want:    `{"code":"FLEET_MISMATCH","err":"ships don't match the rules"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorInvalidLayout",
+			e:       errorInvalidLayout,
+			want:    `{"code":"INVALID_LAYOUT","err":"invalid layout"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorLayoutMismatch",
+			e:       errorLayoutMismatch,
+			want:    `{"code":"LAYOUT_MISMATCH","err":"layout doesn't match the field"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorFleetDoesNotFit",
+			e:       errorFleetDoesNotFit,
+			want:    `{"code":"FLEET_DOES_NOT_FIT","err":"ships don't fit the field"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipNotConnected",
+			e:       errorShipNotConnected,
+			want:    `{"code":"SHIP_NOT_CONNECTED","err":"ship cells are not connected"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShapeNotAllowed",
+			e:       errorShapeNotAllowed,
+			want:    `{"code":"SHAPE_NOT_ALLOWED","err":"ship shape is not allowed"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorUnknownMap",
+			e:       errorUnknownMap,
+			want:    `{"code":"UNKNOWN_MAP","err":"unknown map"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorInvalidTerrain",
+			e:       errorInvalidTerrain,
+			want:    `{"code":"INVALID_TERRAIN","err":"invalid terrain"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipOnTerrain",
+			e:       errorShipOnTerrain,
+			want:    `{"code":"SHIP_ON_TERRAIN","err":"can't place ships on terrain"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorCellNotShootable",
+			e:       errorCellNotShootable,
+			want:    `{"code":"CELL_NOT_SHOOTABLE","err":"can't shoot at island"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorUnknownGrid",
+			e:       errorUnknownGrid,
+			want:    `{"code":"UNKNOWN_GRID","err":"unknown grid"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorGridNotSupported",
+			e:       errorGridNotSupported,
+			want:    `{"code":"GRID_NOT_SUPPORTED","err":"rules don't support the grid"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorWeaponsDisabled",
+			e:       errorWeaponsDisabled,
+			want:    `{"code":"WEAPONS_DISABLED","err":"weapons are disabled in the game"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorWeaponExhausted",
+			e:       errorWeaponExhausted,
+			want:    `{"code":"WEAPON_EXHAUSTED","err":"no weapon left"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorMovesDisabled",
+			e:       errorMovesDisabled,
+			want:    `{"code":"MOVES_DISABLED","err":"ships can't move in the game"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipNotFound",
+			e:       errorShipNotFound,
+			want:    `{"code":"SHIP_NOT_FOUND","err":"ship not found"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipDamaged",
+			e:       errorShipDamaged,
+			want:    `{"code":"SHIP_DAMAGED","err":"can't move damaged ship"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorMinesDisabled",
+			e:       errorMinesDisabled,
+			want:    `{"code":"MINES_DISABLED","err":"mines are disabled in the game"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorMinesNotPlaced",
+			e:       errorMinesNotPlaced,
+			want:    `{"code":"MINES_NOT_PLACED","err":"mines not placed yet"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorMinesAlreadyPlaced",
+			e:       errorMinesAlreadyPlaced,
+			want:    `{"code":"MINES_ALREADY_PLACED","err":"mines already placed"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorMinesMismatch",
+			e:       errorMinesMismatch,
+			want:    `{"code":"MINES_MISMATCH","err":"number of mines doesn't match the game"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorCellHasMine",
+			e:       errorCellHasMine,
+			want:    `{"code":"CELL_HAS_MINE","err":"cell holds a mine"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorTurnLost",
+			e:       errorTurnLost,
+			want:    `{"code":"TURN_LOST","err":"turn is lost on the mine"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorRepairsDisabled",
+			e:       errorRepairsDisabled,
+			want:    `{"code":"REPAIRS_DISABLED","err":"ships can't be repaired in the game"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorRepairsExhausted",
+			e:       errorRepairsExhausted,
+			want:    `{"code":"REPAIRS_EXHAUSTED","err":"no repairs left for the ship"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorShipDestroyed",
+			e:       errorShipDestroyed,
+			want:    `{"code":"SHIP_DESTROYED","err":"can't repair destroyed ship"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorCellNotDamaged",
+			e:       errorCellNotDamaged,
+			want:    `{"code":"CELL_NOT_DAMAGED","err":"cell of the ship is not hit"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorGameNotFound",
+			e:       errorGameNotFound,
+			want:    `{"code":"GAME_NOT_FOUND","err":"game not found"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorNotGamePlayer",
+			e:       errorNotGamePlayer,
+			want:    `{"code":"NOT_GAME_PLAYER","err":"only players of the game can do this"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorGameIsOver",
+			e:       errorGameIsOver,
+			want:    `{"code":"GAME_IS_OVER","err":"game is over"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorGamePaused",
+			e:       errorGamePaused,
+			want:    `{"code":"GAME_PAUSED","err":"game is paused"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorGameNotPaused",
+			e:       errorGameNotPaused,
+			want:    `{"code":"GAME_NOT_PAUSED","err":"game is not paused"}`,
+			wantErr: nil,
+		},
+		{
+			name:    "errorRetaliateNotSupported",
+			e:       errorRetaliateNotSupported,
+			want:    `{"code":"RETALIATE_NOT_SUPPORTED","err":"retaliate penalty needs moving or repairs"}`,
 			wantErr: nil,
 		},
 	}
@@ -216,3 +987,14 @@ func TestHTTPError_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPError_Is(t *testing.T) {
+	err := errorOutOfBonds.withShip(2).withCoord(coordinates.Coordinate{X: 1, Y: 9})
+	assert.True(t, errors.Is(err, errorOutOfBonds))
+	assert.False(t, errors.Is(err, errorCellAlreadyShot))
+	assert.Nil(t, errorOutOfBonds.Details)
+
+	got, err2 := err.MarshalJSON()
+	assert.NoError(t, err2)
+	assert.JSONEq(t, `{"code":"OUT_OF_BOUNDS","err":"out of bonds","details":{"ship":2,"coord":"B10"}}`, string(got))
+}
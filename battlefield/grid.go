@@ -0,0 +1,38 @@
+package battlefield
+
+import (
+	"my/battleship/coordinates"
+)
+
+// Grid kinds.
+const (
+	// GridSquare is the classic field of square cells.
+	GridSquare = "square"
+	// GridHex is the field of hexes, see coordinates.Hex.
+	GridHex = "hex"
+)
+
+// grids are geometries of the field by name.
+var grids = map[string]coordinates.Grid{
+	GridSquare: coordinates.Square,
+	GridHex:    coordinates.Hexagonal,
+}
+
+// gridByName returns the grid, GridSquare is used if name is empty.
+func gridByName(name string) (string, coordinates.Grid, bool) {
+	if name == "" {
+		name = GridSquare
+	}
+	g, ok := grids[name]
+	return name, g, ok
+}
+
+// geometry returns the grid of the field, wrapped around
+// its edges if the field is a torus.
+func (f Field) geometry() coordinates.Grid {
+	_, g, _ := gridByName(f.grid)
+	if f.wrap {
+		return coordinates.Torus(g, f.size)
+	}
+	return g
+}
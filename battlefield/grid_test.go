@@ -0,0 +1,224 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestGridByName(t *testing.T) {
+	name, g, ok := gridByName("")
+	assert.Equal(t, GridSquare, name)
+	assert.Equal(t, coordinates.Square, g)
+	assert.True(t, ok)
+
+	name, g, ok = gridByName(GridHex)
+	assert.Equal(t, GridHex, name)
+	assert.Equal(t, coordinates.Hexagonal, g)
+	assert.True(t, ok)
+
+	_, _, ok = gridByName("triangle")
+	assert.False(t, ok)
+}
+
+func TestService_CreateField_Grid(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    fieldOptions
+		wantErr error
+	}{
+		{
+			name: "success, hex",
+			opts: fieldOptions{size: 10, rules: RulesClassic, grid: GridHex},
+		},
+		{
+			name: "success, hex with terrain",
+			opts: fieldOptions{size: 10, grid: GridHex, mapName: "archipelago"},
+		},
+		{
+			name:    "error, unknown grid",
+			opts:    fieldOptions{size: 10, grid: "triangle"},
+			wantErr: errorUnknownGrid,
+		},
+		{
+			name:    "error, shapes on hex",
+			opts:    fieldOptions{size: 10, rules: RulesTetromino, grid: GridHex},
+			wantErr: errorGridNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.Equal(t, tt.wantErr, s.createField(tt.opts, caller{}))
+		})
+	}
+}
+
+func TestService_HexGame(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 5, grid: GridHex}, caller{}))
+
+	// B1 and A2 share a side of hexes
+	err := s.addShipsByCoordinates("B1 B1,A2 A2", caller{})
+	assert.Equal(t, errorCellIsOccupiedNearby.withShip(1).withCoord(coordinates.Coordinate{X: 0, Y: 1}), err)
+
+	// A1 and B2 touch by corner on the square grid only, E1 D2 is the diagonal line
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1,B2 B2,E1 D2,D4 D5", caller{}))
+	l, err := s.exportShips(caller{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A1 A1", "B2 B2", "E1+D2", "D4 D5"}, l.Ships)
+
+	for _, c := range []string{"A1", "B2", "E1", "D2", "D4"} {
+		_, err := s.shot(c, caller{})
+		assert.NoError(t, err, c)
+	}
+	res, err := s.shot("D5", caller{})
+	assert.NoError(t, err)
+	assert.Equal(t, shotResult{Knock: true, Destroy: true, End: true}, res)
+
+	// the game is replayed on the same grid
+	snap := s.f.snapshot()
+	assert.Equal(t, GridHex, snap.Grid)
+	f, err := replay(logrus.New(), snap)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.state, f.state)
+}
+
+func TestService_AutoPlaceShips_Hex(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		s := NewService(logrus.New())
+		assert.NoError(t, s.createField(fieldOptions{size: 10, rules: RulesClassic, grid: GridHex, seed: seed}, caller{}))
+		assert.NoError(t, s.autoPlaceShips(nil, caller{}), "seed %d", seed)
+	}
+}
+
+func TestService_ImportShips_Grid(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3, grid: GridHex}, caller{}))
+	assert.Equal(t, errorLayoutMismatch, s.importShips(Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A1"}}, caller{}))
+	assert.NoError(t, s.importShips(Layout{Size: 3, Rules: RulesFree, Grid: GridHex, Ships: []string{"C1 A3"}}, caller{}))
+
+	l, err := s.exportShips(caller{})
+	assert.NoError(t, err)
+	assert.Equal(t, Layout{Size: 3, Rules: RulesFree, Grid: GridHex, Ships: []string{"C1+B2+A3"}}, l)
+}
+
+func TestField_Geometry_Wrap(t *testing.T) {
+	f := NewField(10)
+	assert.Equal(t, coordinates.Square, f.geometry())
+	f.wrap = true
+	assert.Equal(t, coordinates.Torus(coordinates.Square, 10), f.geometry())
+}
+
+func TestService_AddShips_Wrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		grid    string
+		coords  string
+		wantErr error
+	}{
+		{
+			name:   "success, ship crosses A1",
+			coords: "J1 B1",
+		},
+		{
+			name:   "success, ship crosses J1",
+			coords: "J9 J2",
+		},
+		{
+			name:   "success, ship crosses A10",
+			coords: "A9 A1",
+		},
+		{
+			name:   "success, ship crosses J10",
+			coords: "I10 A10",
+		},
+		{
+			name:   "success, ship covers all corners",
+			coords: "J10 A1",
+		},
+		{
+			name:   "success, ship of cells crosses the corner",
+			coords: "J10+A10+A1",
+		},
+		{
+			name:    "error, ships touch across the right edge",
+			coords:  "J5 J5,A6 A6",
+			wantErr: errorCellIsOccupiedNearby.withShip(1).withCoord(coordinates.Coordinate{X: 0, Y: 5}),
+		},
+		{
+			name:    "error, ships touch across corners",
+			coords:  "A1 A1,J10 J10",
+			wantErr: errorCellIsOccupiedNearby.withShip(1).withCoord(coordinates.Coordinate{X: 9, Y: 9}),
+		},
+		{
+			name:    "error, ships overlap across the bottom edge",
+			coords:  "C9 C2,C1 D1",
+			wantErr: errorCellIsOccupiedByShip.withShip(1).withCoord(coordinates.Coordinate{X: 2, Y: 0}),
+		},
+		{
+			name:    "error, hex ships touch across corners",
+			grid:    GridHex,
+			coords:  "A1 A1,J2 J2",
+			wantErr: errorCellIsOccupiedNearby.withShip(1).withCoord(coordinates.Coordinate{X: 9, Y: 1}),
+		},
+		{
+			name:   "success, hex ships touch by corner only",
+			grid:   GridHex,
+			coords: "A1 A1,J10 J10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(fieldOptions{size: 10, grid: tt.grid, wrap: true}, caller{}))
+			assert.Equal(t, tt.wantErr, s.addShipsByCoordinates(tt.coords, caller{}))
+		})
+	}
+}
+
+func TestService_WrapGame(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 10, wrap: true}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("J1 B1,E5 E5", caller{}))
+
+	for _, c := range []string{"J1", "A1", "E5"} {
+		_, err := s.shot(c, caller{})
+		assert.NoError(t, err, c)
+	}
+	res, err := s.shot("B1", caller{})
+	assert.NoError(t, err)
+	assert.Equal(t, shotResult{Knock: true, Destroy: true, End: true}, res)
+
+	l, err := s.exportShips(caller{})
+	assert.NoError(t, err)
+	assert.Equal(t, Layout{Size: 10, Rules: RulesFree, Wrap: true, Ships: []string{"A1+B1+J1", "E5 E5"}}, l)
+
+	// the game is replayed on the torus
+	snap := s.f.snapshot()
+	assert.True(t, snap.Wrap)
+	f, err := replay(logrus.New(), snap)
+	assert.NoError(t, err)
+	assert.True(t, f.wrap)
+	assert.Equal(t, s.f.state, f.state)
+}
+
+func TestService_AutoPlaceShips_Wrap(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		s := NewService(logrus.New())
+		assert.NoError(t, s.createField(fieldOptions{size: 10, rules: RulesClassic, wrap: true, seed: seed}, caller{}))
+		assert.NoError(t, s.autoPlaceShips(nil, caller{}), "seed %d", seed)
+	}
+}
+
+func TestService_ImportShips_Wrap(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3, wrap: true}, caller{}))
+	assert.Equal(t, errorLayoutMismatch, s.importShips(Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A1"}}, caller{}))
+	assert.NoError(t, s.importShips(Layout{Size: 3, Rules: RulesFree, Wrap: true, Ships: []string{"C1+A1"}}, caller{}))
+}
@@ -2,8 +2,14 @@ package battlefield
 
 import (
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,18 +29,65 @@ func NewHandlers(l *logrus.Logger, e Endpoints) Handlers {
 	return Handlers{logger: l, e: e}
 }
 
+// Register registers API routes in the router.
+func (h Handlers) Register(r *mux.Router) {
+	r.HandleFunc("/create-matrix", h.CreateBattleField).Methods("POST")
+	r.HandleFunc("/clear", h.ClearBattleField).Methods("POST")
+	r.HandleFunc("/ship", h.AddShips).Methods("POST")
+	r.HandleFunc("/ship/auto", h.AutoPlaceShips).Methods("POST")
+	r.HandleFunc("/ship/validate", h.ValidateShips).Methods("POST")
+	r.HandleFunc("/ship/export", h.ExportShips).Methods("GET")
+	r.HandleFunc("/ship/import", h.ImportShips).Methods("POST")
+	r.HandleFunc("/ship/{id}/move", h.MoveShip).Methods("POST")
+	r.HandleFunc("/ship/{id}/repair", h.RepairShip).Methods("POST")
+	r.HandleFunc("/mines", h.PlaceMines).Methods("POST")
+	r.HandleFunc("/shot", h.Shot).Methods("POST")
+	r.HandleFunc("/weapon/sonar", h.Sonar).Methods("POST")
+	r.HandleFunc("/weapon/radar", h.Radar).Methods("POST")
+	r.HandleFunc("/weapon/bomb", h.Bomb).Methods("POST")
+	r.HandleFunc("/games/paused", h.PausedGames).Methods("GET")
+	r.HandleFunc("/history", h.History).Methods("GET")
+	r.HandleFunc("/history/{id}", h.HistoryRecord).Methods("GET")
+	r.HandleFunc("/history/{id}/replay", h.HistoryReplay).Methods("GET")
+	r.HandleFunc("/games/{id}/pause", h.PauseGame).Methods("POST")
+	r.HandleFunc("/games/{id}/resume", h.ResumeGame).Methods("POST")
+	r.HandleFunc("/state", h.State).Methods("GET")
+	r.HandleFunc("/field", h.Field).Methods("GET")
+	r.HandleFunc("/stats", h.Stats).Methods("GET")
+	r.HandleFunc("/stats/heatmap", h.Heatmap).Methods("GET")
+}
+
 // CreateBattleField handles request for creating battlefield
 // @Title CreateBattleField
 // @Tags BattleField
 // @Accept json
-// @Description create new battlefield with provided size
+// @Description create new battlefield with provided size.
+// @Description grid is square or hex, hexes are addressed like squares, see coordinates.Hex.
+// @Description wrap makes the field a torus: ships may cross its edges and cells at opposite edges touch.
+// @Description advanced gives the attacker limited weapons, see /weapon endpoints.
+// @Description moving lets the owner move ships, see /ship/{id}/move.
+// @Description repairs is the number of hit cells the owner can repair on every ship, see /ship/{id}/repair.
+// @Description mines is the number of mines the owner hides, see /mines, the attacker who hits a mine
+// @Description loses the next turn with "skip" mine_penalty, the default, or gives the owner a free turn
+// @Description to move or repair a ship with "retaliate", which needs moving or repairs.
+// @Description islands and reefs are set with terrain or with the name of the map,
+// @Description ships can't be placed on them, islands can't be shot, reefs absorb shots.
 // @Summary create new battlefield
 // @Success 201
 // @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
 // @Failure 409 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
 // @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /create-matrix [post]
 // @Param model body battlefield.CreateFieldRequest true "createParams"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
 func (h Handlers) CreateBattleField(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Handlers: CreateBattleField started")
 
@@ -42,10 +95,10 @@ func (h Handlers) CreateBattleField(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		h.logger.Errorf("Handlers: CreateBattleField: can't decode request: %v", err)
-		handleErrorResponse(w, errorInvalidInputParams)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
 		return
 	}
-	resp, err := h.e.createFieldEndpoint(req)
+	resp, err := h.e.createFieldEndpoint(callerFromRequest(r), req)
 	if err != nil {
 		h.logger.Errorf("Handlers: CreateBattleField: can't create Field: %v", err)
 		handleErrorResponse(w, err)
@@ -61,14 +114,24 @@ func (h Handlers) CreateBattleField(w http.ResponseWriter, r *http.Request) {
 // @Tags BattleField
 // @Accept json
 // @Description clear the battlefield
+// @Description requires admin role
 // @Summary clear the battlefield
 // @Success 200
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
 // @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /clear [post]
-func (h Handlers) ClearBattleField(w http.ResponseWriter, _ *http.Request) {
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) ClearBattleField(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Handlers: ClearBattleField started")
 
-	resp, err := h.e.clearFieldEndpoint()
+	resp, err := h.e.clearFieldEndpoint(callerFromRequest(r))
 	if err != nil {
 		h.logger.Errorf("Handlers: ClearBattleField: can't clear Field: %v", err)
 		handleErrorResponse(w, err)
@@ -86,15 +149,27 @@ func (h Handlers) ClearBattleField(w http.ResponseWriter, _ *http.Request) {
 // @Description add ships to battlefield
 // @Description input params should be like this:
 // @Description "A1 B2,C4 C6,E7 F8" where first coordinate is one corner of ship, second - other.
-// @Description ships can be square or rectangular
+// @Description ships of other shapes are set cell by cell: "A1+A2+B2", cells should be connected.
+// @Description on hex grid two cells on one axis set the straight line, e.g. "C1 A3", other cells set the rhombus.
 // @Description ships can't be placed on top of each other and near each other.
+// @Description only the player who created the battlefield can add ships.
 // @Summary add ships to battlefield
 // @Success 201
 // @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
 // @Failure 409 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
 // @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /ship [post]
 // @Param model body battlefield.AddShipsRequest true "coordinates"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
 func (h Handlers) AddShips(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Handlers: AddShips started")
 
@@ -102,10 +177,10 @@ func (h Handlers) AddShips(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		h.logger.Errorf("Handlers: AddShips: can't decode request: %v", err)
-		handleErrorResponse(w, errorInvalidInputParams)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
 		return
 	}
-	resp, err := h.e.addShipsEndpoint(req)
+	resp, err := h.e.addShipsEndpoint(callerFromRequest(r), req)
 	if err != nil {
 		h.logger.Errorf("Handlers: AddShips: can't add ships: %v", err)
 		handleErrorResponse(w, err)
@@ -116,18 +191,369 @@ func (h Handlers) AddShips(w http.ResponseWriter, r *http.Request) {
 	handleOKResponse(w, resp)
 }
 
+// AutoPlaceShips handles request for random placement of ships
+// @Title AutoPlaceShips
+// @Tags Ships
+// @Accept json
+// @Description place straight ships of provided lengths randomly, the fleet of the rules is used
+// @Description if lengths are empty. placement depends only on the seed of the game and previous moves.
+// @Description only the player who created the battlefield can add ships.
+// @Summary place ships randomly
+// @Success 201
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/auto [post]
+// @Param model body battlefield.AutoPlaceShipsRequest false "lengths of ships"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) AutoPlaceShips(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: AutoPlaceShips started")
+
+	req := AutoPlaceShipsRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		h.logger.Errorf("Handlers: AutoPlaceShips: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.autoPlaceShipsEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: AutoPlaceShips: can't place ships: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("SHIPS PLACED RANDOMLY")
+	handleOKResponse(w, resp)
+}
+
+// ValidateShips handles request for validating ships placement
+// @Title ValidateShips
+// @Tags Ships
+// @Accept json
+// @Description check ships placement without adding ships to battlefield.
+// @Description all problems are returned: invalid coordinates, out of bounds,
+// @Description overlapping and nearby ships, ships not allowed by the rules.
+// @Description only the player who created the battlefield can validate ships.
+// @Summary validate ships placement
+// @Success 200 {object} battlefield.ValidateShipsResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/validate [post]
+// @Param model body battlefield.AddShipsRequest true "coordinates"
+func (h Handlers) ValidateShips(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: ValidateShips started")
+
+	req := AddShipsRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: ValidateShips: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.validateShipsEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: ValidateShips: can't validate ships: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	handleOKResponse(w, resp)
+}
+
+// ExportShips handles request for exporting ships
+// @Title ExportShips
+// @Tags Ships
+// @Produce json
+// @Produce plain
+// @Description export placed ships in JSON or text layout format, see battlefield.Layout.
+// @Description only the player who created the battlefield can export ships.
+// @Summary export placed ships
+// @Success 200 {object} battlefield.Layout
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/export [get]
+// @Param format query string false "layout format" Enums(json, text)
+func (h Handlers) ExportShips(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: ExportShips started")
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		h.logger.Errorf("Handlers: ExportShips: unknown format %q", format)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+	resp, err := h.e.exportShipsEndpoint(callerFromRequest(r))
+	if err != nil {
+		h.logger.Errorf("Handlers: ExportShips: can't export ships: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(resp.Text())
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// ImportShips handles request for importing ships
+// @Title ImportShips
+// @Tags Ships
+// @Accept json
+// @Accept plain
+// @Description add ships to battlefield from JSON or text layout, see battlefield.Layout.
+// @Description text layout is expected if Content-Type is text/plain.
+// @Description layout size and rules should match the battlefield.
+// @Description only the player who created the battlefield can add ships.
+// @Summary import ships to battlefield
+// @Success 201
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 409 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/import [post]
+// @Param model body battlefield.Layout true "layout"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) ImportShips(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: ImportShips started")
+
+	req := Layout{}
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain") {
+		var b []byte
+		b, err = ioutil.ReadAll(r.Body)
+		if err == nil {
+			req, err = ParseLayoutText(b)
+		}
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&req)
+	}
+	if err != nil {
+		h.logger.Errorf("Handlers: ImportShips: can't decode layout: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidLayout))
+		return
+	}
+	resp, err := h.e.importShipsEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: ImportShips: can't import ships: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("SHIPS IMPORTED")
+	handleOKResponse(w, resp)
+}
+
+// MoveShip handles request for moving the ship
+// @Title MoveShip
+// @Tags BattleField
+// @Accept json
+// @Description move the undamaged ship by one cell: up, down, left or right on the square grid,
+// @Description left, right or diagonally on the hex grid.
+// @Description ships are numbered from zero in the order they were added, see /ship/export.
+// @Description the owner may reply with one move after every move of the attacker if the game
+// @Description is created with moving, the attacker doesn't wait for the reply.
+// @Description The ship is checked like the placed one and can't move onto cells which are already shot.
+// @Summary move the ship
+// @Success 200 {object} battlefield.MoveShipResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/{id}/move [post]
+// @Param id path int true "index of the ship"
+// @Param model body battlefield.MoveShipRequest true "direction"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) MoveShip(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: MoveShip started")
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.logger.Errorf("Handlers: MoveShip: invalid ship id: %v", err)
+		handleErrorResponse(w, errorShipNotFound)
+		return
+	}
+	req := MoveShipRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: MoveShip: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	req.ID = id
+	resp, err := h.e.moveShipEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: MoveShip: can't move the ship: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("SHIP %d MOVED %s TO %s", id, req.Direction, resp.Ship)
+	handleOKResponse(w, resp)
+}
+
+// RepairShip handles request for repairing the ship
+// @Title RepairShip
+// @Tags BattleField
+// @Accept json
+// @Description repair the hit cell of the damaged ship which is not destroyed yet, e.g. "A1".
+// @Description the owner may repair or move one ship after every move of the attacker if the game
+// @Description is created with repairs, every ship can be repaired the number of times of the game.
+// @Description the repaired cell is not shot anymore, the attacker sees the hit until they shoot it again.
+// @Summary repair the ship
+// @Success 200 {object} battlefield.RepairShipResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ship/{id}/repair [post]
+// @Param id path int true "index of the ship"
+// @Param model body battlefield.RepairShipRequest true "hit cell"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) RepairShip(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: RepairShip started")
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.logger.Errorf("Handlers: RepairShip: invalid ship id: %v", err)
+		handleErrorResponse(w, errorShipNotFound)
+		return
+	}
+	req := RepairShipRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: RepairShip: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	req.ID = id
+	resp, err := h.e.repairShipEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: RepairShip: can't repair the ship: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("SHIP %d REPAIRED AT %s, REPAIRS LEFT - %d", id, req.Coord, resp.Repairs)
+	handleOKResponse(w, resp)
+}
+
+// PlaceMines handles request for hiding mines
+// @Title PlaceMines
+// @Tags BattleField
+// @Accept json
+// @Description hide mines in cells without ships, e.g. "C3,E5", mines can't be placed on islands.
+// @Description the game is created with the number of mines, they are placed after ships and before the first shot.
+// @Description only the player who created the battlefield can place mines.
+// @Summary hide mines on the battlefield
+// @Success 201
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /mines [post]
+// @Param model body battlefield.PlaceMinesRequest true "coordinates"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) PlaceMines(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: PlaceMines started")
+
+	req := PlaceMinesRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: PlaceMines: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.placeMinesEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: PlaceMines: can't place mines: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("MINES PLACED")
+	handleOKResponse(w, resp)
+}
+
 // Shot handles request for make a shot
 // @Title Shot
 // @Tags Battle
 // @Accept json
 // @Description make a shot to provided coordinate
 // @Description example: "A1"
+// @Description owner of the battlefield can't shoot, the first player who shoots
+// @Description becomes the attacker and only they can shoot further.
+// @Description islands can't be shot, shots at reefs are absorbed and reported in terrain.
+// @Description shots at mines are reported with the penalty, the next shot of the attacker who lost
+// @Description the turn is not fired and is reported as skipped, weapons fail with TURN_LOST until then.
 // @Summary make a shot to provided coordinate
 // @Success 200
 // @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
 // @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /shot [post]
 // @Param model body battlefield.ShotRequest true "shot coordinates"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
 func (h Handlers) Shot(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Handlers: Shot started")
 
@@ -135,10 +561,10 @@ func (h Handlers) Shot(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		h.logger.Errorf("Handlers: Shot: can't decode request: %v", err)
-		handleErrorResponse(w, errorInvalidInputParams)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
 		return
 	}
-	resp, err := h.e.shotEndpoint(req)
+	resp, err := h.e.shotEndpoint(callerFromRequest(r), req)
 	if err != nil {
 		h.logger.Errorf("Handlers: Shot: can't make a shot: %v", err)
 		handleErrorResponse(w, err)
@@ -157,19 +583,564 @@ func (h Handlers) Shot(w http.ResponseWriter, r *http.Request) {
 	handleOKResponse(w, resp)
 }
 
+// Sonar handles request for the sonar ping
+// @Title Sonar
+// @Tags Battle
+// @Accept json
+// @Description ping the coordinate, e.g. "B2", and cells touching it: the 3x3 area on the square grid
+// @Description or six neighbours on the hex grid. Report if any ship which isn't shot yet is there.
+// @Description Cells are not marked as shot.
+// @Description available in the advanced mode only, the number of uses is in the state.
+// @Summary ping the area with sonar
+// @Success 200 {object} battlefield.SonarResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /weapon/sonar [post]
+// @Param model body battlefield.ShotRequest true "center of the area"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) Sonar(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Sonar started")
+
+	req := ShotRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Sonar: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.sonarEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Sonar: can't ping: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("SONAR AT %s, FOUND - %t", req.Coord, resp.Found)
+	handleOKResponse(w, resp)
+}
+
+// Radar handles request for the radar scan
+// @Title Radar
+// @Tags Battle
+// @Accept json
+// @Description scan the column, e.g. "C", or the row, e.g. "3", and count ship cells
+// @Description which aren't shot yet there. Cells are not marked as shot.
+// @Description available in the advanced mode only, the number of uses is in the state.
+// @Summary scan the line with radar
+// @Success 200 {object} battlefield.RadarResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /weapon/radar [post]
+// @Param model body battlefield.RadarRequest true "line to scan"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) Radar(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Radar started")
+
+	req := RadarRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Radar: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.radarEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Radar: can't scan: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("RADAR AT %s, CELLS - %d", req.Line, resp.Cells)
+	handleOKResponse(w, resp)
+}
+
+// Bomb handles request for the area bomb
+// @Title Bomb
+// @Tags Battle
+// @Accept json
+// @Description drop the bomb: shoot at the coordinate, e.g. "B2", and at cells sharing a side with it,
+// @Description four on the square grid or six on the hex grid. Cells already shot and islands are skipped,
+// @Description every shot is counted in the state like the shot at one cell.
+// @Description available in the advanced mode only, the number of uses is in the state.
+// @Summary drop the bomb
+// @Success 200 {object} battlefield.BombResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 413 {object} battlefield.HTTPError
+// @Failure 422 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /weapon/bomb [post]
+// @Param model body battlefield.ShotRequest true "center of the bomb"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) Bomb(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Bomb started")
+
+	req := ShotRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Bomb: can't decode request: %v", err)
+		handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+		return
+	}
+	resp, err := h.e.bombEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: Bomb: can't drop the bomb: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("BOMB AT %s, SHOTS - %d", req.Coord, len(resp.Shots))
+	if resp.End {
+		h.logger.Info("GAME OVER")
+	}
+	handleOKResponse(w, resp)
+}
+
+// PauseGame handles request for pausing the game
+// @Title PauseGame
+// @Tags Games
+// @Description pause the current game with the identifier from the state: the clock is stopped,
+// @Description moves fail with GAME_PAUSED until the game is resumed, and the game is saved at once.
+// @Description another game can be created while the game is paused.
+// @Description only players of the game can pause it, games paused for too long are archived.
+// @Summary pause the game
+// @Success 200
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 409 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /games/{id}/pause [post]
+// @Param id path string true "game identifier"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) PauseGame(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: PauseGame started")
+
+	id := mux.Vars(r)["id"]
+	resp, err := h.e.pauseGameEndpoint(callerFromRequest(r), id)
+	if err != nil {
+		h.logger.Errorf("Handlers: PauseGame: can't pause the game: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("GAME %s PAUSED", id)
+	handleOKResponse(w, resp)
+}
+
+// ResumeGame handles request for resuming the paused game
+// @Title ResumeGame
+// @Tags Games
+// @Description resume the paused game, see /games/paused. The current game is resumed in place,
+// @Description other games are resumed if the current game is finished or paused.
+// @Description only players of the game can resume it.
+// @Summary resume the paused game
+// @Success 200
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 409 {object} battlefield.HTTPError
+// @Failure 412 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /games/{id}/resume [post]
+// @Param id path string true "game identifier"
+// @Param Idempotency-Key header string false "unique request ID, retried request gets the original response"
+// @Param If-Match header string false "ETag of the expected game version"
+func (h Handlers) ResumeGame(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: ResumeGame started")
+
+	id := mux.Vars(r)["id"]
+	resp, err := h.e.resumeGameEndpoint(callerFromRequest(r), id)
+	if err != nil {
+		h.logger.Errorf("Handlers: ResumeGame: can't resume the game: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	h.logger.Infof("GAME %s RESUMED", id)
+	handleOKResponse(w, resp)
+}
+
+// PausedGames handles request for listing paused games
+// @Title PausedGames
+// @Tags Games
+// @Produce json
+// @Description list paused games of the player, all paused games are listed for the admin.
+// @Summary list paused games
+// @Success 200 {object} battlefield.PausedGamesResponse
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /games/paused [get]
+func (h Handlers) PausedGames(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: PausedGames started")
+
+	resp, err := h.e.pausedGamesEndpoint(callerFromRequest(r))
+	if err != nil {
+		h.logger.Errorf("Handlers: PausedGames: can't list paused games: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// History handles request for the history of games
+// @Title History
+// @Tags Games
+// @Produce json
+// @Description list archived games of the player from the last finished one, the admin sees all of them.
+// @Description Finished games are archived when the game is saved, games paused for too long are archived too.
+// @Description dates are RFC 3339 times or YYYY-MM-DD dates in UTC, the game is finished since from and before to.
+// @Summary list archived games
+// @Success 200 {object} battlefield.HistoryResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /history [get]
+// @Param player query string false "owner or attacker of the game"
+// @Param from query string false "earliest finish date"
+// @Param to query string false "latest finish date, excluded"
+// @Param rules query string false "rules preset" Enums(free, classic, tetromino)
+// @Param outcome query string false "outcome of the game" Enums(won, abandoned)
+// @Param offset query int false "number of skipped games"
+// @Param limit query int false "page size, 20 if empty, at most 100"
+func (h Handlers) History(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: History started")
+
+	q := r.URL.Query()
+	req := HistoryRequest{Player: q.Get("player"), Rules: q.Get("rules"), Outcome: q.Get("outcome")}
+	var err error
+	if req.From, err = parseHistoryTime(q.Get("from")); err == nil {
+		req.To, err = parseHistoryTime(q.Get("to"))
+	}
+	if err == nil && q.Get("offset") != "" {
+		req.Offset, err = strconv.Atoi(q.Get("offset"))
+	}
+	if err == nil && q.Get("limit") != "" {
+		req.Limit, err = strconv.Atoi(q.Get("limit"))
+	}
+	if err != nil {
+		h.logger.Errorf("Handlers: History: invalid query: %v", err)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+
+	resp, err := h.e.historyEndpoint(callerFromRequest(r), req)
+	if err != nil {
+		h.logger.Errorf("Handlers: History: can't list games: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// parseHistoryTime parses RFC 3339 time or date, zero time is returned if s is empty.
+func parseHistoryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// HistoryRecord handles request for the archived game
+// @Title HistoryRecord
+// @Tags Games
+// @Produce json
+// @Description get the archived game with all moves and final boards. The game is replayed
+// @Description by creating the field with game params and making moves of the log in order.
+// @Description only players of the game and the admin can get it.
+// @Summary get the archived game
+// @Success 200 {object} battlefield.HistoryRecordResponse
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /history/{id} [get]
+// @Param id path string true "game identifier"
+func (h Handlers) HistoryRecord(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: HistoryRecord started")
+
+	resp, err := h.e.historyRecordEndpoint(callerFromRequest(r), mux.Vars(r)["id"])
+	if err != nil {
+		h.logger.Errorf("Handlers: HistoryRecord: can't get the game: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// HistoryReplay handles request for the replay of the archived game
+// @Title HistoryReplay
+// @Tags Games
+// @Produce html
+// @Produce gif
+// @Description replay the archived game move by move: the field seen by the owner after every move,
+// @Description targets of shots and weapons are outlined.
+// @Description gif is the animated image, html is the page with a slider over moves, it doesn't load other resources.
+// @Description only players of the game and the admin can replay it.
+// @Summary replay the archived game
+// @Success 200 {string} string
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 403 {object} battlefield.HTTPError
+// @Failure 404 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Failure 503 {object} battlefield.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /history/{id}/replay [get]
+// @Param id path string true "game identifier"
+// @Param format query string false "replay format, html if empty" Enums(html, gif)
+func (h Handlers) HistoryReplay(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: HistoryReplay started")
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "html" && format != "gif" {
+		h.logger.Errorf("Handlers: HistoryReplay: unknown format %q", format)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+	resp, err := h.e.historyReplayEndpoint(callerFromRequest(r), mux.Vars(r)["id"])
+	if err != nil {
+		h.logger.Errorf("Handlers: HistoryReplay: can't replay the game: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	render, contentType := renderReplayHTML, "text/html; charset=utf-8"
+	if format == "gif" {
+		render, contentType = renderReplayGIF, "image/gif"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := render(w, resp); err != nil {
+		h.logger.Errorf("Handlers: HistoryReplay: can't render the replay: %v", err)
+	}
+}
+
 // State handles request for state request
 // @Title State
 // @Tags BattleField
 // @Accept json
 // @Description get the state of current game
+// @Description game version is returned in ETag header, the state is not sent
+// @Description if it matches If-None-Match header.
 // @Summary get the state of current game
 // @Success 200
+// @Success 304
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
 // @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /state [get]
-func (h Handlers) State(w http.ResponseWriter, _ *http.Request) {
+// @Param If-None-Match header string false "ETag of the known game version"
+func (h Handlers) State(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("Handlers: State started")
 
 	resp := h.e.stateEndpoint()
+	tag := etag(resp.Game, resp.Version)
+	w.Header().Set("ETag", tag)
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// Field handles request for the field view
+// @Title Field
+// @Tags BattleField
+// @Produce json
+// @Produce plain
+// @Description get the field as seen by the player, terrain is visible to everyone.
+// @Description only the owner of the battlefield sees ships, others see hits and misses only.
+// @Description game version is returned in ETag header as in /state, the field is not sent
+// @Description if it matches If-None-Match header.
+// @Summary get the field view
+// @Success 200 {object} battlefield.FieldResponse
+// @Success 304
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /field [get]
+// @Param fog query bool false "hide ships from the owner too"
+// @Param format query string false "field format" Enums(json, text)
+// @Param If-None-Match header string false "ETag of the known field view"
+func (h Handlers) Field(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Field started")
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "text" {
+		h.logger.Errorf("Handlers: Field: unknown format %q", format)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+	var fog bool
+	if v := q.Get("fog"); v != "" {
+		var err error
+		fog, err = strconv.ParseBool(v)
+		if err != nil {
+			h.logger.Errorf("Handlers: Field: invalid fog: %v", err)
+			handleErrorResponse(w, errorInvalidInputParams)
+			return
+		}
+	}
+
+	resp := h.e.fieldEndpoint(callerFromRequest(r), fog)
+	// every view has the ETag of the game, so it can be sent in If-Match
+	// of the next move; the views differ by the caller and the fog query
+	tag := etag(resp.Game, resp.Version)
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Vary", "Authorization, X-API-Key")
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matchETag(inm, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(resp.Text())
+		return
+	}
+	handleOKResponse(w, resp)
+}
+
+// Stats handles request for statistics
+// @Title Stats
+// @Tags Stats
+// @Produce json
+// @Description get accuracy of shots in the current game and of finished games by player
+// @Summary get statistics of games
+// @Success 200 {object} battlefield.StatsResponse
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /stats [get]
+func (h Handlers) Stats(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Stats started")
+
+	handleOKResponse(w, h.e.statsEndpoint())
+}
+
+// Heatmap handles request for heatmap of shots
+// @Title Heatmap
+// @Tags Stats
+// @Produce json
+// @Produce png
+// @Description get number of shots and hits of every cell across finished games of the field size.
+// @Description png image shows shots or hits depending on kind, more frequent cells are redder.
+// @Summary get heatmap of shots
+// @Success 200 {object} battlefield.HeatmapResponse
+// @Failure 400 {object} battlefield.HTTPError
+// @Failure 401 {object} battlefield.HTTPError
+// @Failure 429 {object} battlefield.HTTPError
+// @Failure 500 {string} string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /stats/heatmap [get]
+// @Param size query int false "field size, size of the current field if empty"
+// @Param format query string false "heatmap format" Enums(json, png)
+// @Param kind query string false "cells to show in png" Enums(shots, hits)
+func (h Handlers) Heatmap(w http.ResponseWriter, r *http.Request) {
+	h.logger.Debug("Handlers: Heatmap started")
+
+	q := r.URL.Query()
+	format, kind := q.Get("format"), q.Get("kind")
+	if format != "" && format != "json" && format != "png" {
+		h.logger.Errorf("Handlers: Heatmap: unknown format %q", format)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+	if kind != "" && kind != "shots" && kind != "hits" {
+		h.logger.Errorf("Handlers: Heatmap: unknown kind %q", kind)
+		handleErrorResponse(w, errorInvalidInputParams)
+		return
+	}
+	var size uint64
+	if v := q.Get("size"); v != "" {
+		var err error
+		size, err = strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			h.logger.Errorf("Handlers: Heatmap: invalid size: %v", err)
+			handleErrorResponse(w, errorInvalidInputParams)
+			return
+		}
+	}
+	resp, err := h.e.heatmapEndpoint(uint(size))
+	if err != nil {
+		h.logger.Errorf("Handlers: Heatmap: can't get heatmap: %v", err)
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if format == "png" {
+		cells := resp.Shots
+		if kind == "hits" {
+			cells = resp.Hits
+		}
+		w.Header().Set("Content-Type", "image/png")
+		if err := renderHeatmap(w, cells); err != nil {
+			h.logger.Errorf("Handlers: Heatmap: can't render heatmap: %v", err)
+		}
+		return
+	}
 	handleOKResponse(w, resp)
 }
 
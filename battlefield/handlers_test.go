@@ -1,16 +1,21 @@
 package battlefield
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"my/battleship/coordinates"
 )
 
 func TestNewHandlers(t *testing.T) {
@@ -43,12 +48,30 @@ func TestHandlers_CreateBattleField(t *testing.T) {
 			args: args{
 				url:    "/create-matrix",
 				method: http.MethodPost,
-				body:   fmt.Sprintf(`{"range": %d}`, maxFieldSize-1),
+				body:   fmt.Sprintf(`{"range": %d, "seed": 42}`, maxFieldSize-1),
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"createField",
+					fieldOptions{size: maxFieldSize - 1, seed: 42},
+					caller{admin: true},
+				).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "{}",
+		},
+		{
+			name: "success, random seed",
+			args: args{
+				url:    "/create-matrix",
+				method: http.MethodPost,
+				body:   `{"range": 3}`,
 			},
 			setup: func() {
 				testifyServiceMock.On(
 					"createField",
-					maxFieldSize-1,
+					mock.MatchedBy(func(opts fieldOptions) bool { return opts.size == 3 }),
+					caller{admin: true},
 				).Return(nil).Once()
 			},
 			wantStatus: http.StatusCreated,
@@ -63,51 +86,54 @@ func TestHandlers_CreateBattleField(t *testing.T) {
 			},
 			setup:      func() {},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"invalid input params"}`,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
 		},
 		{
 			name: "error, service errorInvalidFieldSize error",
 			args: args{
 				url:    "/create-matrix",
 				method: http.MethodPost,
-				body:   fmt.Sprintf(`{"range": %d}`, maxFieldSize+1),
+				body:   fmt.Sprintf(`{"range": %d, "seed": 42}`, maxFieldSize+1),
 			},
 			setup: func() {
 				testifyServiceMock.On(
 					"createField",
-					maxFieldSize+1,
+					fieldOptions{size: maxFieldSize + 1, seed: 42},
+					caller{admin: true},
 				).Return(errorInvalidFieldSize).Once()
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"field size is invalid"}`,
+			wantBody:   `{"code":"INVALID_FIELD_SIZE","err":"field size is invalid"}`,
 		},
 		{
 			name: "error, service errorFieldAlreadySet error",
 			args: args{
 				url:    "/create-matrix",
 				method: http.MethodPost,
-				body:   fmt.Sprintf(`{"range": %d}`, maxFieldSize-1),
+				body:   fmt.Sprintf(`{"range": %d, "seed": 42}`, maxFieldSize-1),
 			},
 			setup: func() {
 				testifyServiceMock.On(
 					"createField",
-					maxFieldSize-1,
+					fieldOptions{size: maxFieldSize - 1, seed: 42},
+					caller{admin: true},
 				).Return(errorFieldAlreadySet).Once()
 			},
 			wantStatus: http.StatusConflict,
-			wantBody:   `{"err":"field is already set"}`,
+			wantBody:   `{"code":"FIELD_ALREADY_SET","err":"field is already set"}`,
 		},
 		{
 			name: "error, service general error",
 			args: args{
 				url:    "/create-matrix",
 				method: http.MethodPost,
-				body:   fmt.Sprintf(`{"range": %d}`, maxFieldSize+1),
+				body:   fmt.Sprintf(`{"range": %d, "seed": 42}`, maxFieldSize+1),
 			},
 			setup: func() {
 				testifyServiceMock.On(
 					"createField",
-					maxFieldSize+1,
+					fieldOptions{size: maxFieldSize + 1, seed: 42},
+					caller{admin: true},
 				).Return(errors.New("something went wrong")).Once()
 			},
 			wantStatus: http.StatusInternalServerError,
@@ -166,6 +192,7 @@ func TestHandlers_ClearBattleField(t *testing.T) {
 			setup: func() {
 				testifyServiceMock.On(
 					"clearField",
+					caller{admin: true},
 				).Return(nil).Once()
 			},
 			wantStatus: http.StatusOK,
@@ -180,6 +207,7 @@ func TestHandlers_ClearBattleField(t *testing.T) {
 			setup: func() {
 				testifyServiceMock.On(
 					"clearField",
+					caller{admin: true},
 				).Return(errors.New("something went wrong")).Once()
 			},
 			wantStatus: http.StatusInternalServerError,
@@ -241,6 +269,7 @@ func TestHandlers_AddShips(t *testing.T) {
 				testifyServiceMock.On(
 					"addShipsByCoordinates",
 					"A1 A1",
+					caller{admin: true},
 				).Return(nil).Once()
 			},
 			wantStatus: http.StatusCreated,
@@ -255,7 +284,7 @@ func TestHandlers_AddShips(t *testing.T) {
 			},
 			setup:      func() {},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"invalid input params"}`,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
 		},
 		{
 			name: "error, service error",
@@ -268,10 +297,11 @@ func TestHandlers_AddShips(t *testing.T) {
 				testifyServiceMock.On(
 					"addShipsByCoordinates",
 					"A1 A1",
+					caller{admin: true},
 				).Return(errorShipsAlreadyAdded).Once()
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"ships are already added"}`,
+			wantBody:   `{"code":"SHIPS_ALREADY_ADDED","err":"ships are already added"}`,
 		},
 		{
 			name: "error, service general error",
@@ -284,6 +314,7 @@ func TestHandlers_AddShips(t *testing.T) {
 				testifyServiceMock.On(
 					"addShipsByCoordinates",
 					"A1 A1",
+					caller{admin: true},
 				).Return(errors.New("something went wrong")).Once()
 			},
 			wantStatus: http.StatusInternalServerError,
@@ -318,84 +349,191 @@ func TestHandlers_AddShips(t *testing.T) {
 	}
 }
 
-func TestHandlers_Shot(t *testing.T) {
+func TestHandlers_AutoPlaceShips(t *testing.T) {
 	testifyServiceMock := NewTestifyServiceMock(t)
 
-	type args struct {
-		method string
-		url    string
-		body   string
+	tests := []struct {
+		name       string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			body: `{"ships": [3, 1]}`,
+			setup: func() {
+				testifyServiceMock.On("autoPlaceShips", []int{3, 1}, caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "{}",
+		},
+		{
+			name: "success, empty body",
+			setup: func() {
+				testifyServiceMock.On("autoPlaceShips", []int(nil), caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "{}",
+		},
+		{
+			name:       "error, invalid request body",
+			body:       `{"ships": "3"}`,
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"ships": [4]}`,
+			setup: func() {
+				testifyServiceMock.On("autoPlaceShips", []int{4}, caller{admin: true}).Return(errorFleetDoesNotFit).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"FLEET_DOES_NOT_FIT","err":"ships don't fit the field"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/ship/auto", handlers.AutoPlaceShips)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/ship/auto", strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
 	}
+}
+
+func TestHandlers_ValidateShips(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
 
 	tests := []struct {
 		name       string
-		args       args
+		body       string
 		setup      func()
 		wantStatus int
 		wantBody   string
 	}{
 		{
-			name: "success",
-			args: args{
-				url:    "/shot",
-				method: http.MethodPost,
-				body:   `{"coord": "A1"}`,
+			name: "success, valid",
+			body: `{"Coordinates": "A1 A1"}`,
+			setup: func() {
+				testifyServiceMock.On("validateShips", "A1 A1", caller{admin: true}).Return(nil, nil).Once()
 			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"valid":true,"problems":[]}`,
+		},
+		{
+			name: "success, invalid",
+			body: `{"Coordinates": "A1 A1,Z1 Z1"}`,
 			setup: func() {
-				testifyServiceMock.On(
-					"shot",
-					"A1",
-				).Return(shotResult{
-					Destroy: true,
-					Knock:   true,
-					End:     true,
+				testifyServiceMock.On("validateShips", "A1 A1,Z1 Z1", caller{admin: true}).Return([]HTTPError{
+					errorOutOfBonds.withShip(1).withCoord(coordinates.Coordinate{X: 25, Y: 0}),
 				}, nil).Once()
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `{"destroy":true,"knock":true,"end":true}`,
+			wantBody:   `{"valid":false,"problems":[{"code":"OUT_OF_BOUNDS","err":"out of bonds","details":{"ship":1,"coord":"Z1"}}]}`,
 		},
 		{
-			name: "error, invalid request body",
-			args: args{
-				url:    "/shot",
-				method: http.MethodPost,
-				body:   "{totally not a valid json]",
-			},
+			name:       "error, invalid request body",
+			body:       `{"Coordinates": 1}`,
 			setup:      func() {},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"invalid input params"}`,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
 		},
 		{
 			name: "error, service error",
-			args: args{
-				url:    "/shot",
-				method: http.MethodPost,
-				body:   `{"coord": "A1"}`,
+			body: `{"Coordinates": "A1 A1"}`,
+			setup: func() {
+				testifyServiceMock.On("validateShips", "A1 A1", caller{admin: true}).Return(nil, errorNotBoardOwner).Once()
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"code":"NOT_BOARD_OWNER","err":"only board owner can do this"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/ship/validate", handlers.ValidateShips)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/ship/validate", strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_ExportShips(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	layout := Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2", "C3 C3"}}
+
+	tests := []struct {
+		name            string
+		url             string
+		setup           func()
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success, json",
+			url:  "/ship/export",
+			setup: func() {
+				testifyServiceMock.On("exportShips", caller{admin: true}).Return(layout, nil).Once()
 			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"size":3,"rules":"free","ships":["A1 A2","C3 C3"]}`,
+		},
+		{
+			name: "success, text",
+			url:  "/ship/export?format=text",
 			setup: func() {
-				testifyServiceMock.On(
-					"shot",
-					"A1",
-				).Return(shotResult{}, errorShipsNotPlaced).Once()
+				testifyServiceMock.On("exportShips", caller{admin: true}).Return(layout, nil).Once()
 			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "size 3\nrules free\nship A1 A2\nship C3 C3",
+		},
+		{
+			name:       "error, unknown format",
+			url:        "/ship/export?format=xml",
+			setup:      func() {},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   `{"err":"ships not placed yet"}`,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
 		},
 		{
-			name: "error, service general error",
-			args: args{
-				url:    "/shot",
-				method: http.MethodPost,
-				body:   `{"coord": "A1"}`,
-			},
+			name: "error, service error",
+			url:  "/ship/export",
 			setup: func() {
-				testifyServiceMock.On(
-					"shot",
-					"A1",
-				).Return(shotResult{}, errors.New("something went wrong")).Once()
+				testifyServiceMock.On("exportShips", caller{admin: true}).Return(Layout{}, errorShipsNotPlaced).Once()
 			},
-			wantStatus: http.StatusInternalServerError,
-			wantBody:   "something went wrong",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"SHIPS_NOT_PLACED","err":"ships not placed yet"}`,
 		},
 	}
 
@@ -405,7 +543,7 @@ func TestHandlers_Shot(t *testing.T) {
 	endpoints := NewEndpoints(logger, testifyServiceMock)
 	handlers := NewHandlers(logger, endpoints)
 
-	r.HandleFunc("/shot", handlers.Shot)
+	r.HandleFunc("/ship/export", handlers.ExportShips)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -413,66 +551,147 @@ func TestHandlers_Shot(t *testing.T) {
 			defer testifyServiceMock.AssertExpectations(t)
 
 			res := httptest.NewRecorder()
-			req, _ := http.NewRequest(
-				tt.args.method,
-				tt.args.url,
-				strings.NewReader(tt.args.body),
-			)
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
 			r.ServeHTTP(res, req)
 
 			assert.Equal(t, tt.wantStatus, res.Code)
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, res.Header().Get("Content-Type"))
+			}
 			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
 		})
 	}
 }
 
-func TestHandlers_State(t *testing.T) {
+func TestHandlers_ImportShips(t *testing.T) {
 	testifyServiceMock := NewTestifyServiceMock(t)
+	layout := Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2", "C3 C3"}}
 
-	type args struct {
-		method string
-		url    string
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		setup       func()
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name: "success, json",
+			body: `{"size":3,"rules":"free","ships":["A1 A2","C3 C3"]}`,
+			setup: func() {
+				testifyServiceMock.On("importShips", layout, caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{}`,
+		},
+		{
+			name:        "success, text",
+			contentType: "text/plain; charset=utf-8",
+			body:        "size 3\nrules free\nship A1 A2\nship C3 C3\n",
+			setup: func() {
+				testifyServiceMock.On("importShips", layout, caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{}`,
+		},
+		{
+			name:        "error, invalid text",
+			contentType: "text/plain",
+			body:        "ships A1 A2\n",
+			setup:       func() {},
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `{"code":"INVALID_LAYOUT","err":"invalid layout"}`,
+		},
+		{
+			name:       "error, invalid json",
+			body:       `{"size":"three"}`,
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_LAYOUT","err":"invalid layout"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"size":3,"rules":"free","ships":["A1 A2","C3 C3"]}`,
+			setup: func() {
+				testifyServiceMock.On("importShips", layout, caller{admin: true}).Return(errorLayoutMismatch).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"code":"LAYOUT_MISMATCH","err":"layout doesn't match the field"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/ship/import", handlers.ImportShips)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/ship/import", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
 	}
+}
+
+func TestHandlers_MoveShip(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
 
 	tests := []struct {
 		name       string
-		args       args
+		url        string
+		body       string
 		setup      func()
 		wantStatus int
 		wantBody   string
 	}{
 		{
 			name: "success",
-			args: args{
-				url:    "/state",
-				method: http.MethodGet,
-			},
+			url:  "/ship/1/move",
+			body: `{"direction": "down"}`,
 			setup: func() {
-				testifyServiceMock.On(
-					"state",
-				).Return(state{
-					knocked:   1,
-					destroyed: 1,
-					shipCount: 3,
-					shotCount: 5,
-				}).Once()
+				testifyServiceMock.On("moveShip", 1, DirectionDown, caller{admin: true}).Return("C2 C4", nil).Once()
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `{"ship_count":3,"destroyed":1,"knocked":1,"shot_count":5}`,
+			wantBody:   `{"ship":"C2 C4"}`,
 		},
 		{
-			name: "success, game is not started",
-			args: args{
-				url:    "/state",
-				method: http.MethodGet,
-			},
+			name:       "error, invalid ship id",
+			url:        "/ship/first/move",
+			body:       `{"direction": "down"}`,
+			setup:      func() {},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"SHIP_NOT_FOUND","err":"ship not found"}`,
+		},
+		{
+			name:       "error, invalid request body",
+			url:        "/ship/1/move",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			url:  "/ship/0/move",
+			body: `{"direction": "up"}`,
 			setup: func() {
-				testifyServiceMock.On(
-					"state",
-				).Return(state{}).Once()
+				testifyServiceMock.On("moveShip", 0, DirectionUp, caller{admin: true}).Return("", errorShipDamaged.withShip(0)).Once()
 			},
-			wantStatus: http.StatusOK,
-			wantBody:   `{"ship_count":0,"destroyed":0,"knocked":0,"shot_count":0}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"SHIP_DAMAGED","err":"can't move damaged ship","details":{"ship":0}}`,
 		},
 	}
 
@@ -482,7 +701,7 @@ func TestHandlers_State(t *testing.T) {
 	endpoints := NewEndpoints(logger, testifyServiceMock)
 	handlers := NewHandlers(logger, endpoints)
 
-	r.HandleFunc("/state", handlers.State)
+	r.HandleFunc("/ship/{id}/move", handlers.MoveShip)
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -490,15 +709,1184 @@ func TestHandlers_State(t *testing.T) {
 			defer testifyServiceMock.AssertExpectations(t)
 
 			res := httptest.NewRecorder()
-			req, _ := http.NewRequest(
-				tt.args.method,
-				tt.args.url,
-				nil,
-			)
+			req, _ := http.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
 			r.ServeHTTP(res, req)
 
-			assert.Equal(t, res.Code, tt.wantStatus)
-			assert.JSONEq(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
 		})
 	}
 }
+
+func TestHandlers_RepairShip(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			url:  "/ship/1/repair",
+			body: `{"coord": "C2"}`,
+			setup: func() {
+				testifyServiceMock.On("repairShip", 1, "C2", caller{admin: true}).Return(2, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"repairs":2}`,
+		},
+		{
+			name:       "error, invalid ship id",
+			url:        "/ship/first/repair",
+			body:       `{"coord": "C2"}`,
+			setup:      func() {},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"SHIP_NOT_FOUND","err":"ship not found"}`,
+		},
+		{
+			name:       "error, invalid request body",
+			url:        "/ship/1/repair",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			url:  "/ship/0/repair",
+			body: `{"coord": "A1"}`,
+			setup: func() {
+				testifyServiceMock.On("repairShip", 0, "A1", caller{admin: true}).Return(0, errorRepairsExhausted.withShip(0)).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"REPAIRS_EXHAUSTED","err":"no repairs left for the ship","details":{"ship":0}}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/ship/{id}/repair", handlers.RepairShip)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_PauseGame(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success, pause",
+			url:  "/games/0123456789abcdef/pause",
+			setup: func() {
+				testifyServiceMock.On("pauseGame", "0123456789abcdef", caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "{}",
+		},
+		{
+			name: "success, resume",
+			url:  "/games/0123456789abcdef/resume",
+			setup: func() {
+				testifyServiceMock.On("resumeGame", "0123456789abcdef", caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "{}",
+		},
+		{
+			name: "error, game is paused",
+			url:  "/games/0123456789abcdef/pause",
+			setup: func() {
+				testifyServiceMock.On("pauseGame", "0123456789abcdef", caller{admin: true}).Return(errorGamePaused).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"code":"GAME_PAUSED","err":"game is paused"}`,
+		},
+		{
+			name: "error, unknown game",
+			url:  "/games/fedcba9876543210/resume",
+			setup: func() {
+				testifyServiceMock.On("resumeGame", "fedcba9876543210", caller{admin: true}).Return(errorGameNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"GAME_NOT_FOUND","err":"game not found"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/games/{id}/pause", handlers.PauseGame)
+	r.HandleFunc("/games/{id}/resume", handlers.ResumeGame)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.url, nil)
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_PausedGames(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	pausedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			setup: func() {
+				games := []pausedGame{{id: "0123456789abcdef", owner: "alice", size: 10, rules: RulesClassic, pausedAt: pausedAt, clock: 90 * time.Second}}
+				testifyServiceMock.On("pausedGames", caller{admin: true}).Return(games, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"games":[{"id":"0123456789abcdef","owner":"alice","range":10,"rules":"classic","paused_at":"2020-01-02T03:04:05Z","clock":90}]}`,
+		},
+		{
+			name: "success, no games",
+			setup: func() {
+				testifyServiceMock.On("pausedGames", caller{admin: true}).Return([]pausedGame{}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"games":[]}`,
+		},
+		{
+			name: "error, store is unavailable",
+			setup: func() {
+				testifyServiceMock.On("pausedGames", caller{admin: true}).Return(nil, errorStoreUnavailable).Once()
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"code":"STORE_UNAVAILABLE","err":"store is unavailable"}`,
+		},
+	}
+
+	logger := logrus.New()
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/games/paused", nil)
+			handlers.PausedGames(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_History(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	finished := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	game := archivedGame{
+		snapshot: snapshot{ID: "0123456789abcdef", Size: 10, Rules: RulesClassic, Owner: "alice", Created: finished.Add(-time.Hour), Log: []event{{}, {}}},
+		Attacker: "bob",
+		Outcome:  OutcomeWon,
+		Finished: finished,
+		Clock:    90 * time.Second,
+		Moves:    2,
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			url:  "/history?player=bob&from=2020-01-01&to=2020-01-03T00:00:00Z&rules=classic&outcome=won&offset=10&limit=5",
+			setup: func() {
+				q := historyQuery{
+					player:  "bob",
+					from:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					to:      time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC),
+					rules:   RulesClassic,
+					outcome: OutcomeWon,
+					offset:  10,
+					limit:   5,
+				}
+				testifyServiceMock.On("history", q, caller{admin: true}).Return([]archivedGame{game}, 11, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"games":[{"id":"0123456789abcdef","owner":"alice","attacker":"bob","range":10,"rules":"classic","outcome":"won","created":"2020-01-02T02:04:05Z","finished":"2020-01-02T03:04:05Z","duration":90,"moves":2}],"total":11}`,
+		},
+		{
+			name: "success, no filters",
+			url:  "/history",
+			setup: func() {
+				testifyServiceMock.On("history", historyQuery{}, caller{admin: true}).Return([]archivedGame{}, 0, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"games":[],"total":0}`,
+		},
+		{
+			name:       "error, invalid date",
+			url:        "/history?from=yesterday",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name:       "error, invalid limit",
+			url:        "/history?limit=ten",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			url:  "/history?outcome=draw",
+			setup: func() {
+				testifyServiceMock.On("history", historyQuery{outcome: "draw"}, caller{admin: true}).Return(nil, 0, errorInvalidInputParams).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+	}
+
+	logger := logrus.New()
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			handlers.History(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_HistoryRecord(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	game := archivedGame{
+		snapshot: snapshot{
+			ID:      "0123456789abcdef",
+			Size:    2,
+			Rules:   RulesFree,
+			Seed:    7,
+			Owner:   "alice",
+			Created: at,
+			Log: []event{
+				{Kind: eventShips, Player: "alice", Arg: "A1 A1", At: at},
+				{Kind: eventShot, Player: "bob", Arg: "A1", At: at.Add(time.Minute)},
+			},
+		},
+		Attacker: "bob",
+		Outcome:  OutcomeWon,
+		Finished: at.Add(time.Minute),
+		Clock:    time.Minute,
+		Moves:    2,
+		Board:    []string{"X.", ".."},
+		Fog:      []string{"X.", ".."},
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			url:  "/history/0123456789abcdef",
+			setup: func() {
+				testifyServiceMock.On("historyRecord", "0123456789abcdef", caller{admin: true}).Return(game, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"id":"0123456789abcdef","owner":"alice","attacker":"bob","range":2,"rules":"free","outcome":"won",` +
+				`"created":"2020-01-02T03:04:05Z","finished":"2020-01-02T03:05:05Z","duration":60,"moves":2,` +
+				`"game":{"range":2,"rules":"free","seed":7},` +
+				`"log":[{"kind":"ships","player":"alice","arg":"A1 A1","at":"2020-01-02T03:04:05Z"},{"kind":"shot","player":"bob","arg":"A1","at":"2020-01-02T03:05:05Z"}],` +
+				`"board":["X.",".."],"fog":["X.",".."]}`,
+		},
+		{
+			name: "error, unknown game",
+			url:  "/history/fedcba9876543210",
+			setup: func() {
+				testifyServiceMock.On("historyRecord", "fedcba9876543210", caller{admin: true}).Return(archivedGame{}, errorGameNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":"GAME_NOT_FOUND","err":"game not found"}`,
+		},
+		{
+			name: "error, not a player",
+			url:  "/history/fedcba9876543211",
+			setup: func() {
+				testifyServiceMock.On("historyRecord", "fedcba9876543211", caller{admin: true}).Return(archivedGame{}, errorNotGamePlayer).Once()
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"code":"NOT_GAME_PLAYER","err":"only players of the game can do this"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/history/{id}", handlers.HistoryRecord)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_HistoryReplay(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	game := archivedGame{snapshot: snapshot{ID: "0123456789abcdef", Size: 2}, Outcome: OutcomeWon}
+	frames := []replayFrame{
+		{rows: []string{"#.", ".."}},
+		{move: &event{Kind: eventShot, Arg: "A1"}, rows: []string{"X.", ".."}},
+	}
+
+	tests := []struct {
+		name            string
+		url             string
+		setup           func()
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success, html by default",
+			url:  "/history/0123456789abcdef/replay",
+			setup: func() {
+				testifyServiceMock.On("historyReplay", "0123456789abcdef", caller{admin: true}).Return(game, frames, nil).Once()
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "<!DOCTYPE html>",
+		},
+		{
+			name: "success, gif",
+			url:  "/history/0123456789abcdef/replay?format=gif",
+			setup: func() {
+				testifyServiceMock.On("historyReplay", "0123456789abcdef", caller{admin: true}).Return(game, frames, nil).Once()
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "image/gif",
+			wantBody:        "GIF89a",
+		},
+		{
+			name:            "error, unknown format",
+			url:             "/history/0123456789abcdef/replay?format=svg",
+			setup:           func() {},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, unknown game",
+			url:  "/history/fedcba9876543210/replay?format=gif",
+			setup: func() {
+				testifyServiceMock.On("historyReplay", "fedcba9876543210", caller{admin: true}).Return(archivedGame{}, nil, errorGameNotFound).Once()
+			},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json; charset=utf-8",
+			wantBody:        `{"code":"GAME_NOT_FOUND","err":"game not found"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/history/{id}/replay", handlers.HistoryReplay)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantContentType, res.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(res.Body.String(), tt.wantBody), res.Body.String())
+		})
+	}
+}
+
+func TestHandlers_PlaceMines(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			body: `{"Coordinates": "C3,E5"}`,
+			setup: func() {
+				testifyServiceMock.On("placeMines", "C3,E5", caller{admin: true}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "{}",
+		},
+		{
+			name:       "error, invalid request body",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"Coordinates": "C3"}`,
+			setup: func() {
+				testifyServiceMock.On("placeMines", "C3", caller{admin: true}).Return(errorMinesMismatch).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"MINES_MISMATCH","err":"number of mines doesn't match the game"}`,
+		},
+	}
+
+	logger := logrus.New()
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/mines", strings.NewReader(tt.body))
+			handlers.PlaceMines(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Shot(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	type args struct {
+		method string
+		url    string
+		body   string
+	}
+
+	tests := []struct {
+		name       string
+		args       args
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			args: args{
+				url:    "/shot",
+				method: http.MethodPost,
+				body:   `{"coord": "A1"}`,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"shot",
+					"A1",
+					caller{admin: true},
+				).Return(shotResult{
+					Destroy: true,
+					Knock:   true,
+					End:     true,
+				}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"destroy":true,"knock":true,"end":true}`,
+		},
+		{
+			name: "error, invalid request body",
+			args: args{
+				url:    "/shot",
+				method: http.MethodPost,
+				body:   "{totally not a valid json]",
+			},
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			args: args{
+				url:    "/shot",
+				method: http.MethodPost,
+				body:   `{"coord": "A1"}`,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"shot",
+					"A1",
+					caller{admin: true},
+				).Return(shotResult{}, errorShipsNotPlaced).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"SHIPS_NOT_PLACED","err":"ships not placed yet"}`,
+		},
+		{
+			name: "error, service general error",
+			args: args{
+				url:    "/shot",
+				method: http.MethodPost,
+				body:   `{"coord": "A1"}`,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"shot",
+					"A1",
+					caller{admin: true},
+				).Return(shotResult{}, errors.New("something went wrong")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "something went wrong",
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/shot", handlers.Shot)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(
+				tt.args.method,
+				tt.args.url,
+				strings.NewReader(tt.args.body),
+			)
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Sonar(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			body: `{"coord": "B2"}`,
+			setup: func() {
+				testifyServiceMock.On("sonar", "B2", caller{admin: true}).Return(true, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"found":true}`,
+		},
+		{
+			name:       "error, invalid request body",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"coord": "B2"}`,
+			setup: func() {
+				testifyServiceMock.On("sonar", "B2", caller{admin: true}).Return(false, errorWeaponsDisabled).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"WEAPONS_DISABLED","err":"weapons are disabled in the game"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/weapon/sonar", handlers.Sonar)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/weapon/sonar", strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Radar(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			body: `{"line": "C"}`,
+			setup: func() {
+				testifyServiceMock.On("radar", "C", caller{admin: true}).Return(3, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"cells":3}`,
+		},
+		{
+			name:       "error, invalid request body",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"line": "C"}`,
+			setup: func() {
+				testifyServiceMock.On("radar", "C", caller{admin: true}).Return(0, errorWeaponExhausted).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"WEAPON_EXHAUSTED","err":"no weapon left"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/weapon/radar", handlers.Radar)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/weapon/radar", strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Bomb(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			body: `{"coord": "A1"}`,
+			setup: func() {
+				testifyServiceMock.On("bomb", "A1", caller{admin: true}).Return([]cellShot{{coord: coordinates.Coordinate{X: 1, Y: 0}, shotResult: shotResult{Knock: true}}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"shots":[{"coord":"B1","destroy":false,"knock":true}],"end":false}`,
+		},
+		{
+			name:       "error, invalid request body",
+			body:       "{totally not a valid json]",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			body: `{"coord": "A1"}`,
+			setup: func() {
+				testifyServiceMock.On("bomb", "A1", caller{admin: true}).Return([]cellShot(nil), errorShipsNotPlaced).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"SHIPS_NOT_PLACED","err":"ships not placed yet"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/weapon/bomb", handlers.Bomb)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/weapon/bomb", strings.NewReader(tt.body))
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_State(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+
+	type args struct {
+		method      string
+		url         string
+		ifNoneMatch string
+	}
+
+	tests := []struct {
+		name       string
+		args       args
+		setup      func()
+		wantStatus int
+		wantETag   string
+		wantBody   string
+	}{
+		{
+			name: "success",
+			args: args{
+				url:    "/state",
+				method: http.MethodGet,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"state",
+				).Return(state{
+					game:      "42",
+					version:   7,
+					seed:      5,
+					knocked:   1,
+					destroyed: 1,
+					shipCount: 3,
+					shotCount: 5,
+				}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"42-7"`,
+			wantBody:   `{"game":"42","version":7,"seed":5,"ship_count":3,"destroyed":1,"knocked":1,"shot_count":5}`,
+		},
+		{
+			name: "success, not modified",
+			args: args{
+				url:         "/state",
+				method:      http.MethodGet,
+				ifNoneMatch: `"42-6", "42-7"`,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"state",
+				).Return(state{game: "42", version: 7}).Once()
+			},
+			wantStatus: http.StatusNotModified,
+			wantETag:   `"42-7"`,
+		},
+		{
+			name: "success, modified",
+			args: args{
+				url:         "/state",
+				method:      http.MethodGet,
+				ifNoneMatch: `"42-6"`,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"state",
+				).Return(state{game: "42", version: 7}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"42-7"`,
+			wantBody:   `{"game":"42","version":7,"seed":0,"ship_count":0,"destroyed":0,"knocked":0,"shot_count":0}`,
+		},
+		{
+			name: "success, game is not started",
+			args: args{
+				url:    "/state",
+				method: http.MethodGet,
+			},
+			setup: func() {
+				testifyServiceMock.On(
+					"state",
+				).Return(state{}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"-0"`,
+			wantBody:   `{"version":0,"seed":0,"ship_count":0,"destroyed":0,"knocked":0,"shot_count":0}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/state", handlers.State)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(
+				tt.args.method,
+				tt.args.url,
+				nil,
+			)
+			if tt.args.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.args.ifNoneMatch)
+			}
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, res.Code, tt.wantStatus)
+			assert.Equal(t, tt.wantETag, res.Header().Get("ETag"))
+			if tt.wantBody == "" {
+				assert.Empty(t, res.Body.String())
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Stats(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	testifyServiceMock.On("stats").Return(statsReport{
+		games:   1,
+		players: map[string]playerStats{"bob": {Games: 1, Shots: 2, Hits: 1, Sunk: 1, LongestStreak: 1, FirstHits: 2}},
+	}).Once()
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+	NewHandlers(logger, NewEndpoints(logger, testifyServiceMock)).Register(r)
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/stats", nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"games":1,"players":{"bob":{"games":1,"shots":2,"hits":1,"misses":1,"hit_rate":0.5,
+		"longest_streak":1,"shots_to_first_hit":2,"sunk":1,"shots_per_sunk":2}}}`, res.Body.String())
+	testifyServiceMock.AssertExpectations(t)
+}
+
+func TestHandlers_Heatmap(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	h := heatmap{Games: 1, Shots: [][]int{{1, 0}, {0, 1}}, Hits: [][]int{{1, 0}, {0, 0}}}
+
+	tests := []struct {
+		name            string
+		url             string
+		setup           func()
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success, json",
+			url:  "/stats/heatmap?size=2",
+			setup: func() {
+				testifyServiceMock.On("heatmap", uint(2)).Return(h, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"size":2,"games":1,"shots":[[1,0],[0,1]],"hits":[[1,0],[0,0]]}`,
+		},
+		{
+			name: "success, png",
+			url:  "/stats/heatmap?format=png&kind=hits",
+			setup: func() {
+				testifyServiceMock.On("heatmap", uint(0)).Return(h, nil).Once()
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "image/png",
+		},
+		{
+			name:       "error, invalid size",
+			url:        "/stats/heatmap?size=-1",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name:       "error, unknown format",
+			url:        "/stats/heatmap?format=gif",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name:       "error, unknown kind",
+			url:        "/stats/heatmap?format=png&kind=misses",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name: "error, service error",
+			url:  "/stats/heatmap",
+			setup: func() {
+				testifyServiceMock.On("heatmap", uint(0)).Return(heatmap{}, errorInvalidFieldSize).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_FIELD_SIZE","err":"field size is invalid"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/stats/heatmap", handlers.Heatmap)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, res.Header().Get("Content-Type"))
+				return
+			}
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
+
+func TestHandlers_Field(t *testing.T) {
+	testifyServiceMock := NewTestifyServiceMock(t)
+	v := fieldView{game: "42", version: 7, grid: GridSquare, rows: []string{"#.", ".^"}}
+	fogged := fieldView{game: "42", version: 7, grid: GridHex, fog: true, rows: []string{"..", ".^"}}
+
+	tests := []struct {
+		name        string
+		url         string
+		ifNoneMatch string
+		setup       func()
+		wantStatus  int
+		wantETag    string
+		wantBody    string
+	}{
+		{
+			name: "success, json",
+			url:  "/field",
+			setup: func() {
+				testifyServiceMock.On("view", caller{admin: true}, false).Return(v).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"42-7"`,
+			wantBody:   `{"game":"42","version":7,"size":2,"grid":"square","fog":false,"rows":["#.",".^"]}`,
+		},
+		{
+			name: "success, hex text in fog",
+			url:  "/field?fog=true&format=text",
+			setup: func() {
+				testifyServiceMock.On("view", caller{admin: true}, true).Return(fogged).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"42-7"`,
+			wantBody:   "  A B\n1 . .\n2  . ^",
+		},
+		{
+			name:        "success, not modified",
+			url:         "/field?fog=1",
+			ifNoneMatch: `"42-6", W/"42-7"`,
+			setup: func() {
+				testifyServiceMock.On("view", caller{admin: true}, true).Return(fogged).Once()
+			},
+			wantStatus: http.StatusNotModified,
+			wantETag:   `"42-7"`,
+		},
+		{
+			name:        "success, version changed",
+			url:         "/field",
+			ifNoneMatch: `"42-6"`,
+			setup: func() {
+				testifyServiceMock.On("view", caller{admin: true}, false).Return(v).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"42-7"`,
+			wantBody:   `{"game":"42","version":7,"size":2,"grid":"square","fog":false,"rows":["#.",".^"]}`,
+		},
+		{
+			name:       "error, invalid fog",
+			url:        "/field?fog=maybe",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+		{
+			name:       "error, unknown format",
+			url:        "/field?format=png",
+			setup:      func() {},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":"INVALID_INPUT_PARAMS","err":"invalid input params"}`,
+		},
+	}
+
+	logger := logrus.New()
+	r := mux.NewRouter()
+
+	endpoints := NewEndpoints(logger, testifyServiceMock)
+	handlers := NewHandlers(logger, endpoints)
+
+	r.HandleFunc("/field", handlers.Field)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer testifyServiceMock.AssertExpectations(t)
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			r.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.Equal(t, tt.wantETag, res.Header().Get("ETag"))
+			assert.Equal(t, tt.wantBody, strings.TrimRight(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func TestHandlers_Field_IfMatch(t *testing.T) {
+	logger := logrus.New()
+	s := NewService(logger)
+	alice := caller{player: "alice"}
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+
+	handlers := NewHandlers(logger, NewEndpoints(logger, s))
+	r := mux.NewRouter()
+	r.HandleFunc("/field", handlers.Field).Methods(http.MethodGet)
+	r.HandleFunc("/shot", handlers.Shot).Methods(http.MethodPost)
+
+	do := func(method, url, body, ifMatch string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), principalKey{}, Principal{Player: "bob"}))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	// the attacker sees the field in fog and moves with its ETag
+	res := do(http.MethodGet, "/field", "", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `"fog":true`)
+	tag := res.Header().Get("ETag")
+
+	res = do(http.MethodPost, "/shot", `{"coord":"C3"}`, tag)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	// the view is outdated after the shot
+	res = do(http.MethodPost, "/shot", `{"coord":"B3"}`, tag)
+	assert.Equal(t, http.StatusPreconditionFailed, res.Code)
+}
@@ -0,0 +1,66 @@
+package battlefield
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Health serves liveness and readiness probes.
+type Health struct {
+	draining int32
+	ping     func() error
+	logger   *logrus.Logger
+}
+
+// HealthResponse defines probe response.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// NewHealth creates new Health, ping checks availability of dependencies.
+func NewHealth(l *logrus.Logger, ping func() error) *Health {
+	return &Health{ping: ping, logger: l}
+}
+
+// Drain makes readiness probe fail, so no new requests are routed
+// to the server while it shuts down.
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Live handles liveness probe
+// @Title Live
+// @Tags Health
+// @Description the server is alive
+// @Summary liveness probe
+// @Success 200 {object} battlefield.HealthResponse
+// @Router /healthz [get]
+func (h *Health) Live(w http.ResponseWriter, _ *http.Request) {
+	handleOKResponse(w, HealthResponse{Status: "ok"})
+}
+
+// Ready handles readiness probe
+// @Title Ready
+// @Tags Health
+// @Description the server is ready to accept requests:
+// @Description it is not shutting down and the store is available
+// @Summary readiness probe
+// @Success 200 {object} battlefield.HealthResponse
+// @Failure 503 {object} battlefield.HTTPError
+// @Router /readyz [get]
+func (h *Health) Ready(w http.ResponseWriter, _ *http.Request) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		handleErrorResponse(w, errorDraining)
+		return
+	}
+	if h.ping != nil {
+		if err := h.ping(); err != nil {
+			h.logger.Errorf("Health: Ready: store is unavailable: %v", err)
+			handleErrorResponse(w, errorStoreUnavailable)
+			return
+		}
+	}
+	handleOKResponse(w, HealthResponse{Status: "ok"})
+}
@@ -0,0 +1,70 @@
+package battlefield
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Live(t *testing.T) {
+	h := NewHealth(logrus.New(), nil)
+	h.Drain()
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+	h.Live(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, res.Body.String())
+}
+
+func TestHealth_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		ping       func() error
+		drain      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success",
+			ping:       func() error { return nil },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+		},
+		{
+			name:       "error, draining",
+			ping:       func() error { return nil },
+			drain:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"code":"SERVER_DRAINING","err":"server is shutting down"}`,
+		},
+		{
+			name:       "error, store is unavailable",
+			ping:       func() error { return errors.New("disk is gone") },
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"code":"STORE_UNAVAILABLE","err":"store is unavailable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealth(logrus.New(), tt.ping)
+			if tt.drain {
+				h.Drain()
+			}
+
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			h.Ready(res, req)
+
+			assert.Equal(t, tt.wantStatus, res.Code)
+			assert.JSONEq(t, tt.wantBody, strings.TrimSpace(res.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"my/battleship/storage"
+)
+
+// Outcomes of archived games.
+const (
+	// OutcomeWon is the game where the attacker destroyed all ships.
+	OutcomeWon = "won"
+	// OutcomeAbandoned is the game paused for too long, see ArchivePaused.
+	OutcomeAbandoned = "abandoned"
+)
+
+// Page size of the history.
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// archivedGame is the archive record of the game. The game is replayed
+// from the snapshot, final boards are kept to browse the history
+// without replaying.
+type archivedGame struct {
+	snapshot
+	Attacker string    `json:"attacker,omitempty"`
+	Outcome  string    `json:"outcome"`
+	Finished time.Time `json:"finished"`
+	// Clock is the play time of the game, Moves is the number of its moves.
+	Clock time.Duration `json:"clock"`
+	Moves int           `json:"moves"`
+	// Board is the final field as seen by the owner,
+	// Fog is the final field as seen by the attacker.
+	Board []string `json:"board"`
+	Fog   []string `json:"fog"`
+}
+
+// archiveRecord returns the archive record of the game, the game is finished
+// by its last move.
+func (f Field) archiveRecord(outcome string) archivedGame {
+	g := archivedGame{
+		snapshot: f.snapshot(),
+		Attacker: f.attacker,
+		Outcome:  outcome,
+		Moves:    len(f.log),
+		Board:    f.rows(false),
+		Fog:      f.rows(true),
+	}
+	if len(f.log) > 0 {
+		g.Finished = f.log[len(f.log)-1].At
+	}
+	g.Clock = f.clock(g.Finished)
+	return g
+}
+
+// summary returns the archive record without moves, terrain and boards,
+// summaries are kept in the index of the archive, see archiveIndex.
+func (g archivedGame) summary() archivedGame {
+	g.Log, g.Terrain, g.Board, g.Fog = nil, nil, nil, nil
+	return g
+}
+
+// isPlayer checks if caller played the game, see Field.isPlayer.
+func (g archivedGame) isPlayer(cl caller) bool {
+	return Field{owner: g.Owner, attacker: g.Attacker}.isPlayer(cl)
+}
+
+// historyQuery filters archived games. Empty fields match all games,
+// the game matches the player if they own or attack it, the game matches
+// the date range if it's finished since from and before to.
+// Games are sorted from the last finished one, offset games are skipped
+// and at most limit games are returned.
+type historyQuery struct {
+	player  string
+	from    time.Time
+	to      time.Time
+	rules   string
+	outcome string
+	offset  int
+	limit   int
+}
+
+func (q historyQuery) matches(g archivedGame) bool {
+	switch {
+	case q.player != "" && q.player != g.Owner && q.player != g.Attacker:
+		return false
+	case !q.from.IsZero() && g.Finished.Before(q.from):
+		return false
+	case !q.to.IsZero() && !g.Finished.Before(q.to):
+		return false
+	case q.rules != "" && q.rules != g.Rules:
+		return false
+	case q.outcome != "" && q.outcome != g.Outcome:
+		return false
+	}
+	return true
+}
+
+// putArchived saves the game to the archive and adds it to the index.
+func (s *Service) putArchived(f Field, outcome string) error {
+	g := f.archiveRecord(outcome)
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Put(archiveKeyPrefix+f.id, b); err != nil {
+		return err
+	}
+
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+	if s.archive != nil {
+		s.archive[f.id] = g.summary()
+	}
+	return nil
+}
+
+// archiveFinished archives the current game if it's finished. The finished
+// game isn't changed anymore, so the record is written without the lock
+// and the game is marked as archived if it's still current then.
+// The game which isn't saved is archived again on Flush.
+func (s *Service) archiveFinished() {
+	s.rLock()
+	if s.store == nil || !s.f.gameIsOver || s.f.archived {
+		s.RUnlock()
+		return
+	}
+	f := s.f
+	s.RUnlock()
+
+	if err := s.putArchived(f, OutcomeWon); err != nil {
+		s.logger.Errorf("can't archive game %s: %v", f.id, err)
+		return
+	}
+
+	s.lock()
+	defer s.Unlock()
+	if s.f.id == f.id && s.f.version == f.version {
+		s.f.archived = true
+	}
+	s.logger.Infof("GAME %s ARCHIVED", f.id)
+}
+
+// loadArchived loads the archived game.
+func (s *Service) loadArchived(key string) (archivedGame, error) {
+	b, err := s.store.Get(key)
+	if err != nil {
+		return archivedGame{}, err
+	}
+	g := archivedGame{}
+	err = json.Unmarshal(b, &g)
+	return g, err
+}
+
+// archiveIndex returns summaries of archived games by identifier. The index
+// is loaded from the store once and is updated when games are archived,
+// so the history isn't loaded on every request. archiveMu should be held.
+func (s *Service) archiveIndex() (map[string]archivedGame, error) {
+	if s.archive != nil {
+		return s.archive, nil
+	}
+	keys, err := s.store.List(archiveKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]archivedGame, len(keys))
+	for _, k := range keys {
+		g, err := s.loadArchived(k)
+		if err != nil {
+			s.logger.Errorf("can't load archived game %s: %v", k, err)
+			continue
+		}
+		index[strings.TrimPrefix(k, archiveKeyPrefix)] = g.summary()
+	}
+	s.archive = index
+	return index, nil
+}
+
+// history lists summaries of archived games of the caller matching
+// the query and returns the number of all matching games. All games
+// are listed for the admin and if authentication is disabled.
+func (s *Service) history(q historyQuery, cl caller) ([]archivedGame, int, error) {
+	s.logger.Debug("Service: history started")
+
+	if q.outcome != "" && q.outcome != OutcomeWon && q.outcome != OutcomeAbandoned {
+		return nil, 0, errorInvalidInputParams
+	}
+	if q.rules != "" {
+		if _, _, ok := rulesByName(q.rules); !ok {
+			return nil, 0, errorUnknownRules
+		}
+	}
+	if q.offset < 0 || q.limit < 0 || q.limit > maxHistoryLimit {
+		return nil, 0, errorInvalidInputParams
+	}
+	if q.limit == 0 {
+		q.limit = defaultHistoryLimit
+	}
+	if s.store == nil {
+		return []archivedGame{}, 0, nil
+	}
+
+	s.archiveMu.Lock()
+	index, err := s.archiveIndex()
+	if err != nil {
+		s.archiveMu.Unlock()
+		s.logger.Errorf("can't list archived games: %v", err)
+		return nil, 0, errorStoreUnavailable
+	}
+	games := make([]archivedGame, 0, len(index))
+	for _, g := range index {
+		if g.isPlayer(cl) && q.matches(g) {
+			games = append(games, g)
+		}
+	}
+	s.archiveMu.Unlock()
+	sort.Slice(games, func(i, j int) bool {
+		if !games[i].Finished.Equal(games[j].Finished) {
+			return games[i].Finished.After(games[j].Finished)
+		}
+		return games[i].ID < games[j].ID
+	})
+
+	total := len(games)
+	if q.offset > total {
+		q.offset = total
+	}
+	games = games[q.offset:]
+	if len(games) > q.limit {
+		games = games[:q.limit]
+	}
+	return games, total, nil
+}
+
+// historyRecord returns the archived game, only players of the game
+// and the admin can read it.
+func (s *Service) historyRecord(id string, cl caller) (archivedGame, error) {
+	s.logger.WithField("game", id).Debug("Service: historyRecord started")
+
+	if s.store == nil {
+		return archivedGame{}, errorGameNotFound
+	}
+	g, err := s.loadArchived(archiveKeyPrefix + id)
+	if err == storage.ErrNotFound {
+		return archivedGame{}, errorGameNotFound
+	}
+	if err != nil {
+		s.logger.Errorf("can't load archived game %s: %v", id, err)
+		return archivedGame{}, errorStoreUnavailable
+	}
+	if !g.isPlayer(cl) {
+		return archivedGame{}, errorNotGamePlayer
+	}
+	return g, nil
+}
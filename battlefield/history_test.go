@@ -0,0 +1,215 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/storage"
+)
+
+func TestService_Archive(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+
+	t.Run("success, the last shot", func(t *testing.T) {
+		s := NewService(logrus.New())
+		s.SetStore(storage.NewMemory())
+		assert.NoError(t, s.createField(fieldOptions{size: 3, rules: RulesFree}, alice))
+		assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+		_, err := s.shot("C3", bob)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Flush())
+		_, err = s.historyRecord(s.f.id, alice)
+		assert.Equal(t, errorGameNotFound, err)
+
+		// the finished game is archived when it's saved
+		_, err = s.shot("A1", bob)
+		assert.NoError(t, err)
+		_, err = s.historyRecord(s.f.id, alice)
+		assert.Equal(t, errorGameNotFound, err)
+		assert.NoError(t, s.Flush())
+		assert.True(t, s.f.archived)
+		g, err := s.historyRecord(s.f.id, alice)
+		assert.NoError(t, err)
+		assert.Equal(t, s.f.snapshot(), g.snapshot)
+		assert.Equal(t, "bob", g.Attacker)
+		assert.Equal(t, OutcomeWon, g.Outcome)
+		assert.Equal(t, s.f.log[2].At, g.Finished)
+		assert.Equal(t, s.f.clock(time.Now()), g.Clock)
+		assert.Equal(t, 3, g.Moves)
+		assert.Equal(t, []string{"X..", "...", "..o"}, g.Board)
+		assert.Equal(t, g.Board, g.Fog)
+
+		// the archived game is replayed
+		f, err := replay(logrus.New(), g.snapshot)
+		assert.NoError(t, err)
+		assert.True(t, f.gameIsOver)
+		assert.Equal(t, g.Board, f.rows(false))
+	})
+
+	t.Run("success, the last bomb", func(t *testing.T) {
+		s := NewService(logrus.New())
+		s.SetStore(storage.NewMemory())
+		assert.NoError(t, s.createField(fieldOptions{size: 3, advanced: true}, alice))
+		assert.NoError(t, s.addShipsByCoordinates("A1 B1", alice))
+		_, err := s.bomb("A1", bob)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Flush())
+		g, err := s.historyRecord(s.f.id, bob)
+		assert.NoError(t, err)
+		assert.Equal(t, OutcomeWon, g.Outcome)
+		assert.Equal(t, "XX.", g.Board[0])
+	})
+
+	t.Run("success, archived again on flush", func(t *testing.T) {
+		store := storage.NewMemory()
+		s := NewService(logrus.New())
+		s.SetStore(fullStore{store})
+		assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+		assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+		_, err := s.shot("A1", bob)
+		assert.NoError(t, err)
+		assert.Error(t, s.Flush())
+		assert.False(t, s.f.archived)
+
+		s.SetStore(store)
+		assert.NoError(t, s.Flush())
+		assert.True(t, s.f.archived)
+		_, err = s.historyRecord(s.f.id, alice)
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_History(t *testing.T) {
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGame := func(id, owner, attacker, rules, outcome string, finished time.Time) archivedGame {
+		return archivedGame{
+			snapshot: snapshot{ID: id, Size: 10, Rules: rules, Owner: owner, Log: []event{}},
+			Attacker: attacker,
+			Outcome:  outcome,
+			Finished: finished,
+		}
+	}
+	games := []archivedGame{
+		newGame("a", "alice", "bob", RulesClassic, OutcomeWon, day),
+		newGame("b", "bob", "carol", RulesFree, OutcomeWon, day.Add(24*time.Hour)),
+		newGame("c", "carol", "alice", RulesClassic, OutcomeAbandoned, day.Add(48*time.Hour)),
+	}
+	store := storage.NewMemory()
+	for _, g := range games {
+		b, err := json.Marshal(g)
+		assert.NoError(t, err)
+		assert.NoError(t, store.Put(archiveKeyPrefix+g.ID, b))
+	}
+	// other keys are not listed
+	assert.NoError(t, store.Put(pausedKeyPrefix+"d", []byte("{}")))
+
+	tests := []struct {
+		name      string
+		q         historyQuery
+		cl        caller
+		want      []string
+		wantTotal int
+		wantErr   error
+	}{
+		{name: "success, all games", want: []string{"c", "b", "a"}, wantTotal: 3},
+		{name: "success, player", q: historyQuery{player: "alice"}, want: []string{"c", "a"}, wantTotal: 2},
+		{name: "success, games of the caller", cl: caller{player: "alice"}, want: []string{"c", "a"}, wantTotal: 2},
+		{name: "success, games of the caller with other player", q: historyQuery{player: "bob"}, cl: caller{player: "alice"}, want: []string{"a"}, wantTotal: 1},
+		{name: "success, admin", cl: caller{player: "dave", admin: true}, want: []string{"c", "b", "a"}, wantTotal: 3},
+		{name: "success, no games of the caller", cl: caller{player: "dave"}, want: []string{}, wantTotal: 0},
+		{name: "success, date range", q: historyQuery{from: day.Add(time.Hour), to: day.Add(48 * time.Hour)}, want: []string{"b"}, wantTotal: 1},
+		{name: "success, rules", q: historyQuery{rules: RulesClassic}, want: []string{"c", "a"}, wantTotal: 2},
+		{name: "success, outcome", q: historyQuery{outcome: OutcomeWon}, want: []string{"b", "a"}, wantTotal: 2},
+		{name: "success, page", q: historyQuery{offset: 1, limit: 1}, want: []string{"b"}, wantTotal: 3},
+		{name: "success, page after the last game", q: historyQuery{offset: 5}, want: []string{}, wantTotal: 3},
+		{name: "error, unknown outcome", q: historyQuery{outcome: "draw"}, wantErr: errorInvalidInputParams},
+		{name: "error, unknown rules", q: historyQuery{rules: "chess"}, wantErr: errorUnknownRules},
+		{name: "error, negative offset", q: historyQuery{offset: -1}, wantErr: errorInvalidInputParams},
+		{name: "error, page is too large", q: historyQuery{limit: maxHistoryLimit + 1}, wantErr: errorInvalidInputParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			s.SetStore(store)
+
+			got, total, err := s.history(tt.q, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			ids := make([]string, len(got))
+			for i, g := range got {
+				ids[i] = g.ID
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+
+	got, total, err := NewService(logrus.New()).history(historyQuery{}, caller{})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	assert.Equal(t, 0, total)
+}
+
+func TestService_History_Index(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	store := storage.NewMemory()
+	s := NewService(logrus.New())
+	s.SetStore(store)
+	got, _, err := s.history(historyQuery{}, alice)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	// archived games are added to the loaded index
+	assert.NoError(t, s.createField(fieldOptions{size: 2}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+	_, err = s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+	got, total, err := s.history(historyQuery{}, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, s.f.id, got[0].ID)
+		assert.Equal(t, 2, got[0].Moves)
+		assert.Nil(t, got[0].Log)
+		assert.Nil(t, got[0].Board)
+	}
+
+	// the index isn't reloaded from the store on every request
+	assert.NoError(t, store.Delete(archiveKeyPrefix+s.f.id))
+	_, total, err = s.history(historyQuery{}, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestService_HistoryRecord(t *testing.T) {
+	store := storage.NewMemory()
+	assert.NoError(t, store.Put(archiveKeyPrefix+"broken", []byte("{")))
+	s := NewService(logrus.New())
+	s.SetStore(store)
+
+	b, err := json.Marshal(archivedGame{snapshot: snapshot{ID: "a", Owner: "alice"}, Attacker: "bob"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(archiveKeyPrefix+"a", b))
+
+	for _, cl := range []caller{{player: "alice"}, {player: "bob"}, {player: "dave", admin: true}, {}} {
+		g, err := s.historyRecord("a", cl)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", g.ID)
+	}
+	_, err = s.historyRecord("a", caller{player: "dave"})
+	assert.Equal(t, errorNotGamePlayer, err)
+	_, err = s.historyRecord("0123456789abcdef", caller{})
+	assert.Equal(t, errorGameNotFound, err)
+	_, err = s.historyRecord("broken", caller{})
+	assert.Equal(t, errorStoreUnavailable, err)
+	_, err = NewService(logrus.New()).historyRecord("0123456789abcdef", caller{})
+	assert.Equal(t, errorGameNotFound, err)
+}
@@ -0,0 +1,247 @@
+package battlefield
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxIdempotencyKeyLength limits length of Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are response headers remembered with the body.
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+// idempotentResponse is the response remembered for the key.
+type idempotentResponse struct {
+	key         string
+	game        string
+	fingerprint [sha256.Size]byte
+	expires     time.Time
+
+	// done is closed when the original request is finished.
+	done   chan struct{}
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Idempotency replays responses of retried mutating requests
+// with the same Idempotency-Key header instead of executing them again.
+// Keys are remembered per client and per game, at most size keys
+// are kept for ttl, the oldest keys are forgotten first.
+type Idempotency struct {
+	game func() string
+	size int
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	responses map[string]*list.Element
+	order     *list.List // oldest at the back
+	now       func() time.Time
+
+	logger *logrus.Logger
+}
+
+// NewIdempotency creates new Idempotency. game returns identifier
+// of the current game, responses of other games are not replayed.
+func NewIdempotency(l *logrus.Logger, game func() string, size int, ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		game:      game,
+		size:      size,
+		ttl:       ttl,
+		responses: make(map[string]*list.Element),
+		order:     list.New(),
+		now:       time.Now,
+		logger:    l,
+	}
+}
+
+// Middleware replays the response of the request with the same client
+// and Idempotency-Key. Reusing the key for other request is rejected.
+// Server errors and rejected by rate limit requests are not remembered,
+// so they are executed again. Should be used after Authenticator middleware.
+func (id *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || isReadMethod(r.Method) || id.size <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			handleErrorResponse(w, errorInvalidIdempotencyKey)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(r.Body)
+			if err != nil {
+				id.logger.Errorf("Idempotency: can't read request body: %v", err)
+				handleErrorResponse(w, errorRequestTooLarge)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		fingerprint := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+
+		resp, ok := id.acquire(clientID(r)+" "+key, fingerprint)
+		if !ok {
+			handleErrorResponse(w, errorIdempotencyKeyReused)
+			return
+		}
+		if resp.done == nil {
+			id.logger.Debugf("Idempotency: replaying response of %s", r.URL.Path)
+			w.Header().Set("Idempotent-Replayed", "true")
+			for k, v := range resp.header {
+				w.Header()[k] = append([]string(nil), v...)
+			}
+			w.WriteHeader(resp.status)
+			_, _ = w.Write(resp.body)
+			return
+		}
+
+		// the panicking request is forgotten, so requests waiting for it go on
+		rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		released := false
+		defer func() {
+			if !released {
+				id.forget(resp)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		id.release(resp, rec)
+		released = true
+	})
+}
+
+// acquire returns finished response of the key, or registers new
+// pending response with not nil done channel, which should be released
+// by the caller. Concurrent requests with the same key wait for
+// the first one. ok is false if the key is used for other request.
+func (id *Idempotency) acquire(key string, fingerprint [sha256.Size]byte) (resp idempotentResponse, ok bool) {
+	game := id.game()
+
+	id.mu.Lock()
+	for {
+		id.expire()
+		el, found := id.responses[key]
+		if !found {
+			break
+		}
+		e := el.Value.(*idempotentResponse)
+		if e.done != nil {
+			done := e.done
+			id.mu.Unlock()
+			<-done
+			id.mu.Lock()
+			continue
+		}
+		if e.game != game {
+			id.remove(el)
+			break
+		}
+		id.mu.Unlock()
+		if e.fingerprint != fingerprint {
+			return idempotentResponse{}, false
+		}
+		return *e, true
+	}
+
+	e := &idempotentResponse{
+		key:         key,
+		fingerprint: fingerprint,
+		expires:     id.now().Add(id.ttl),
+		done:        make(chan struct{}),
+	}
+	id.responses[key] = id.order.PushFront(e)
+	for id.order.Len() > id.size {
+		id.remove(id.order.Back())
+	}
+	id.mu.Unlock()
+	return *e, true
+}
+
+// release remembers the response of pending request and wakes up
+// requests waiting for it.
+func (id *Idempotency) release(pending idempotentResponse, rec *responseCapture) {
+	game := id.game()
+
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	defer close(pending.done)
+
+	el, ok := id.responses[pending.key]
+	if !ok || el.Value.(*idempotentResponse).done != pending.done {
+		// forgotten while executed
+		return
+	}
+	if rec.status >= http.StatusInternalServerError || rec.status == http.StatusTooManyRequests {
+		id.remove(el)
+		return
+	}
+	e := el.Value.(*idempotentResponse)
+	e.game = game
+	e.status = rec.status
+	e.header = http.Header{}
+	for _, k := range replayedHeaders {
+		if v := rec.Header().Get(k); v != "" {
+			e.header.Set(k, v)
+		}
+	}
+	e.body = rec.body.Bytes()
+	e.done = nil
+}
+
+// forget removes pending request which didn't finish and wakes up
+// requests waiting for it, they are executed again.
+func (id *Idempotency) forget(pending idempotentResponse) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	defer close(pending.done)
+
+	if el, ok := id.responses[pending.key]; ok && el.Value.(*idempotentResponse).done == pending.done {
+		id.remove(el)
+	}
+}
+
+// expire removes expired responses, the oldest are at the back.
+func (id *Idempotency) expire() {
+	now := id.now()
+	for el := id.order.Back(); el != nil; el = id.order.Back() {
+		e := el.Value.(*idempotentResponse)
+		if e.done != nil || now.Before(e.expires) {
+			return
+		}
+		id.remove(el)
+	}
+}
+
+func (id *Idempotency) remove(el *list.Element) {
+	id.order.Remove(el)
+	delete(id.responses, el.Value.(*idempotentResponse).key)
+}
+
+// responseCapture writes the response through and keeps its copy.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
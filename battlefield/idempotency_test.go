@@ -0,0 +1,205 @@
+package battlefield
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency_Middleware(t *testing.T) {
+	now := time.Unix(0, 0)
+	game := "1"
+	calls := 0
+	status := http.StatusOK
+
+	id := NewIdempotency(logrus.New(), func() string { return game }, 2, time.Minute)
+	id.now = func() time.Time { return now }
+
+	h := id.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if status != http.StatusOK {
+			handleErrorResponse(w, errorCellAlreadyShot)
+			return
+		}
+		w.Header().Set("ETag", etag(game, uint64(calls)))
+		handleOKResponse(w, ShotResponse{Knock: true})
+	}))
+
+	do := func(method, key, body string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/shot", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1000"
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	// the first request is executed, retry is replayed
+	res := do(http.MethodPost, "k1", `{"coord":"A1"}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "", res.Header().Get("Idempotent-Replayed"))
+
+	res = do(http.MethodPost, "k1", `{"coord":"A1"}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "true", res.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, `{"destroy":false,"knock":true,"end":false}`, res.Body.String())
+	assert.Equal(t, etag("1", 1), res.Header().Get("ETag"))
+	assert.Equal(t, 1, calls)
+
+	// error responses are replayed too
+	status = http.StatusBadRequest
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "k2", `{"coord":"A1"}`).Code)
+	res = do(http.MethodPost, "k2", `{"coord":"A1"}`)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Equal(t, "application/json; charset=utf-8", res.Header().Get("Content-Type"))
+	assert.Equal(t, 2, calls)
+	status = http.StatusOK
+
+	// key can't be used for other request
+	res = do(http.MethodPost, "k1", `{"coord":"B2"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Equal(t, 2, calls)
+
+	// requests without key and read requests are always executed
+	do(http.MethodPost, "", `{"coord":"A1"}`)
+	do(http.MethodGet, "k1", "")
+	assert.Equal(t, 4, calls)
+
+	// keys are forgotten in the new game
+	game = "2"
+	do(http.MethodPost, "k1", `{"coord":"A1"}`)
+	assert.Equal(t, 5, calls)
+
+	// the oldest keys are forgotten
+	do(http.MethodPost, "k3", `{"coord":"A1"}`)
+	do(http.MethodPost, "k4", `{"coord":"A1"}`)
+	assert.Len(t, id.responses, 2)
+	do(http.MethodPost, "k1", `{"coord":"A1"}`)
+	assert.Equal(t, 8, calls)
+
+	// and expire
+	now = now.Add(time.Minute)
+	do(http.MethodPost, "k4", `{"coord":"A1"}`)
+	assert.Equal(t, 9, calls)
+	assert.Len(t, id.responses, 1)
+
+	res = do(http.MethodPost, strings.Repeat("k", maxIdempotencyKeyLength+1), "")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestIdempotency_Middleware_NotRemembered(t *testing.T) {
+	calls := 0
+	id := NewIdempotency(logrus.New(), func() string { return "" }, 10, time.Minute)
+	h := id.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	for i := 0; i < 2; i++ {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/shot", nil)
+		req.Header.Set("Idempotency-Key", "k1")
+		h.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Len(t, id.responses, 0)
+}
+
+func TestIdempotency_Middleware_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	id := NewIdempotency(logrus.New(), func() string { return "" }, 10, time.Minute)
+	h := id.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		close(started)
+		<-finish
+		handleOKResponse(w, ShotResponse{Knock: true})
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/shot", strings.NewReader(`{"coord":"A1"}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- do() }()
+	<-started
+
+	second := make(chan *httptest.ResponseRecorder)
+	go func() { second <- do() }()
+
+	close(finish)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+	res := <-second
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "true", res.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_Middleware_Panic(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	id := NewIdempotency(logrus.New(), func() string { return "" }, 10, time.Minute)
+	h := id.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-finish
+			panic("something went wrong")
+		}
+		handleOKResponse(w, ShotResponse{Knock: true})
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/shot", strings.NewReader(`{"coord":"A1"}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		do()
+	}()
+	<-started
+
+	// the request waiting for the panicking one is executed
+	second := make(chan *httptest.ResponseRecorder)
+	go func() { second <- do() }()
+
+	close(finish)
+	assert.Equal(t, "something went wrong", <-panicked)
+	select {
+	case res := <-second:
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "", res.Header().Get("Idempotent-Replayed"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("request waiting for the panicking one is blocked")
+	}
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,154 @@
+package battlefield
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Layout is portable description of placed ships.
+//
+// JSON format:
+//
+//	{"size": 10, "rules": "classic", "ships": ["A1 A4", "C1 C3"]}
+//
+// Text format, one directive per line, empty lines and lines
+// starting with # are ignored:
+//
+//	size 10
+//	rules classic
+//	grid hex
+//	wrap
+//	ship A1 A4
+//	ship C1+C2+D2
+//
+// Ships are described by two corners or by cells like in AddShipsRequest.
+// Grid is omitted for the square grid, wrap is set for the torus field.
+type Layout struct {
+	Size  uint     `json:"size"`
+	Rules string   `json:"rules"`
+	Grid  string   `json:"grid,omitempty"`
+	Wrap  bool     `json:"wrap,omitempty"`
+	Ships []string `json:"ships"`
+}
+
+// Text returns the layout in text format.
+func (l Layout) Text() []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "size %d\n", l.Size)
+	fmt.Fprintf(b, "rules %s\n", l.Rules)
+	if l.Grid != "" {
+		fmt.Fprintf(b, "grid %s\n", l.Grid)
+	}
+	if l.Wrap {
+		fmt.Fprintln(b, "wrap")
+	}
+	for _, sh := range l.Ships {
+		fmt.Fprintf(b, "ship %s\n", sh)
+	}
+	return b.Bytes()
+}
+
+// ParseLayoutText parses the layout in text format.
+func ParseLayoutText(text []byte) (Layout, error) {
+	l := Layout{}
+	sc := bufio.NewScanner(bytes.NewReader(text))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case fields[0] == "size" && len(fields) == 2:
+			size, err := strconv.ParseUint(fields[1], 10, 0)
+			if err != nil {
+				return Layout{}, fmt.Errorf("line %d: invalid size: %v", n, err)
+			}
+			l.Size = uint(size)
+		case fields[0] == "rules" && len(fields) == 2:
+			l.Rules = fields[1]
+		case fields[0] == "grid" && len(fields) == 2:
+			l.Grid = fields[1]
+		case fields[0] == "wrap" && len(fields) == 1:
+			l.Wrap = true
+		case fields[0] == "ship" && len(fields) == 3:
+			l.Ships = append(l.Ships, fields[1]+" "+fields[2])
+		case fields[0] == "ship" && len(fields) == 2 && strings.Contains(fields[1], cellSeparator):
+			l.Ships = append(l.Ships, fields[1])
+		default:
+			return Layout{}, fmt.Errorf("line %d: unknown directive %q", n, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Layout{}, err
+	}
+	return l, nil
+}
+
+func (s *Service) exportShips(cl caller) (Layout, error) {
+	s.rLock()
+	defer s.RUnlock()
+
+	s.logger.Debug("Service: exportShips started")
+
+	if !s.f.isOwnedBy(cl) {
+		return Layout{}, errorNotBoardOwner
+	}
+
+	if !s.f.shipsAdded {
+		return Layout{}, errorShipsNotPlaced
+	}
+
+	l := Layout{
+		Size:  s.f.size,
+		Rules: s.f.rules,
+		Wrap:  s.f.wrap,
+		Ships: make([]string, 0, len(s.f.ships)),
+	}
+	if grid, _, _ := gridByName(s.f.grid); grid != GridSquare {
+		l.Grid = grid
+	}
+	for _, sh := range s.f.ships {
+		l.Ships = append(l.Ships, sh.String())
+	}
+	return l, nil
+}
+
+func (s *Service) importShips(l Layout, cl caller) error {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("layout", l).Debug("Service: importShips started")
+
+	if len(l.Ships) == 0 {
+		return errorInvalidLayout
+	}
+	for _, sh := range l.Ships {
+		if strings.Contains(sh, ",") {
+			return errorInvalidLayout
+		}
+	}
+
+	// ownership is checked before revealing the field
+	if !s.f.isOwnedBy(cl) {
+		return errorNotBoardOwner
+	}
+
+	name, _, _ := rulesByName(l.Rules)
+	grid, _, _ := gridByName(l.Grid)
+	fieldGrid, _, _ := gridByName(s.f.grid)
+	if l.Size != s.f.size || name != s.f.rules || grid != fieldGrid || l.Wrap != s.f.wrap {
+		return errorLayoutMismatch
+	}
+
+	coords := strings.Join(l.Ships, ",")
+	if err := s.placeFleet(coords, cl); err != nil {
+		return err
+	}
+	s.f.record(eventShips, cl, coords)
+	s.changed()
+	return nil
+}
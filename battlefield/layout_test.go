@@ -0,0 +1,179 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayout_Text(t *testing.T) {
+	l := Layout{Size: 10, Rules: RulesClassic, Ships: []string{"A1 A4", "C1 C1"}}
+	text := "size 10\nrules classic\nship A1 A4\nship C1 C1\n"
+	assert.Equal(t, text, string(l.Text()))
+
+	got, err := ParseLayoutText([]byte(text))
+	assert.NoError(t, err)
+	assert.Equal(t, l, got)
+
+	l.Grid = GridHex
+	assert.Equal(t, "size 10\nrules classic\ngrid hex\nship A1 A4\nship C1 C1\n", string(l.Text()))
+
+	l.Wrap = true
+	assert.Equal(t, "size 10\nrules classic\ngrid hex\nwrap\nship A1 A4\nship C1 C1\n", string(l.Text()))
+}
+
+func TestParseLayoutText(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    Layout
+		wantErr bool
+	}{
+		{
+			name: "success, comments and empty lines",
+			args: "# my fleet\n\nsize 3\n  ship A1 A2  \nrules free\n",
+			want: Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2"}},
+		},
+		{
+			name: "success, ship cells",
+			args: "ship A1+A2+B2\nship D1 D1\n",
+			want: Layout{Ships: []string{"A1+A2+B2", "D1 D1"}},
+		},
+		{
+			name: "success, grid",
+			args: "size 3\nrules free\ngrid hex\nship C1+B2\n",
+			want: Layout{Size: 3, Rules: RulesFree, Grid: GridHex, Ships: []string{"C1+B2"}},
+		},
+		{
+			name: "success, wrap",
+			args: "size 3\nrules free\nwrap\nship C1+A1\n",
+			want: Layout{Size: 3, Rules: RulesFree, Wrap: true, Ships: []string{"C1+A1"}},
+		},
+		{
+			name:    "error, wrap with argument",
+			args:    "wrap yes\n",
+			wantErr: true,
+		},
+		{
+			name:    "error, invalid size",
+			args:    "size three\n",
+			wantErr: true,
+		},
+		{
+			name:    "error, ship with one corner",
+			args:    "ship A1\n",
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown directive",
+			args:    "mine A1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLayoutText([]byte(tt.args))
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_ExportImportShips(t *testing.T) {
+	alice := caller{player: "alice"}
+	bob := caller{player: "bob"}
+	classic := fieldOptions{size: 10, rules: RulesClassic}
+
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(classic, alice))
+
+	_, err := s.exportShips(alice)
+	assert.Equal(t, errorShipsNotPlaced, err)
+
+	coords := "A1 A4,C3 C1,E1 E3,G1 G2,I1 I2,B6 A6,J4 J4,J6 J6,J8 J8,J10 J10"
+	assert.NoError(t, s.addShipsByCoordinates(coords, alice))
+
+	_, err = s.exportShips(bob)
+	assert.Equal(t, errorNotBoardOwner, err)
+
+	l, err := s.exportShips(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(10), l.Size)
+	assert.Equal(t, RulesClassic, l.Rules)
+	assert.Len(t, l.Ships, 10)
+
+	// round trip through text format reproduces the placement
+	l, err = ParseLayoutText(l.Text())
+	assert.NoError(t, err)
+
+	imported := NewService(logrus.New())
+	assert.NoError(t, imported.createField(classic, alice))
+	assert.NoError(t, imported.importShips(l, alice))
+	assert.Equal(t, s.f.field, imported.f.field)
+	assert.Equal(t, s.f.state, imported.f.state)
+
+	got, err := imported.exportShips(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, l, got)
+}
+
+func TestService_ImportShips(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		cl      caller
+		wantErr error
+	}{
+		{
+			name:   "success",
+			layout: Layout{Size: 3, Ships: []string{"A1 A2", "C3 C3"}},
+			cl:     caller{player: "alice"},
+		},
+		{
+			name:    "error, not owner",
+			layout:  Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2"}},
+			cl:      caller{player: "bob"},
+			wantErr: errorNotBoardOwner,
+		},
+		{
+			name:    "error, no ships",
+			layout:  Layout{Size: 3, Rules: RulesFree},
+			cl:      caller{player: "alice"},
+			wantErr: errorInvalidLayout,
+		},
+		{
+			name:    "error, several ships in one",
+			layout:  Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2,C3 C3"}},
+			cl:      caller{player: "alice"},
+			wantErr: errorInvalidLayout,
+		},
+		{
+			name:    "error, other size",
+			layout:  Layout{Size: 4, Rules: RulesFree, Ships: []string{"A1 A2"}},
+			cl:      caller{player: "alice"},
+			wantErr: errorLayoutMismatch,
+		},
+		{
+			name:    "error, other rules",
+			layout:  Layout{Size: 3, Rules: RulesClassic, Ships: []string{"A1 A2"}},
+			cl:      caller{player: "alice"},
+			wantErr: errorLayoutMismatch,
+		},
+		{
+			name:    "error, invalid ship",
+			layout:  Layout{Size: 3, Rules: RulesFree, Ships: []string{"A1 A2", "B1 B1"}},
+			cl:      caller{player: "alice"},
+			wantErr: errorCellIsOccupiedNearby,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{player: "alice"}))
+			assertError(t, tt.wantErr, s.importShips(tt.layout, tt.cl))
+		})
+	}
+}
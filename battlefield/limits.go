@@ -0,0 +1,166 @@
+package battlefield
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sweepInterval defines how often idle buckets are removed.
+const sweepInterval = time.Minute
+
+// Limit describes token bucket parameters.
+// Zero Rate disables limiting.
+type Limit struct {
+	Rate  float64 // tokens per second
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits request rate of every client with token buckets.
+// Client is identified by authenticated player or by remote IP,
+// mutating and read requests are limited separately.
+type RateLimiter struct {
+	write, read Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+
+	logger *logrus.Logger
+}
+
+// NewRateLimiter creates new RateLimiter with limits for mutating
+// and read requests.
+func NewRateLimiter(l *logrus.Logger, write, read Limit) *RateLimiter {
+	return &RateLimiter{
+		write:   write,
+		read:    read,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+		logger:  l,
+	}
+}
+
+// Middleware rejects requests exceeding the limit with 429 status code
+// and Retry-After header. Should be used after Authenticator middleware.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, kind := rl.write, "write"
+		if isReadMethod(r.Method) {
+			limit, kind = rl.read, "read"
+		}
+		if limit.Rate <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := clientID(r)
+		wait := rl.take(kind+":"+client, limit)
+		if wait > 0 {
+			rl.logger.Errorf("RateLimiter: %s limit exceeded by %s", kind, client)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			handleErrorResponse(w, errorTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take takes a token from the bucket of the key and returns zero,
+// or returns time to wait until the token is available.
+func (rl *RateLimiter) take(key string, limit Limit) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	if now.Sub(rl.lastSweep) > sweepInterval {
+		rl.sweep(now)
+	}
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep removes buckets that are refilled by now, since they are
+// indistinguishable from new ones.
+func (rl *RateLimiter) sweep(now time.Time) {
+	for key, b := range rl.buckets {
+		limit := rl.write
+		if strings.HasPrefix(key, "read:") {
+			limit = rl.read
+		}
+		if b.tokens+now.Sub(b.last).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// clientID identifies client by authenticated player or by remote IP.
+func clientID(r *http.Request) string {
+	if p, ok := r.Context().Value(principalKey{}).(Principal); ok {
+		return "player:" + p.Player
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// LimitBody returns middleware that rejects request bodies bigger
+// than max bytes. Bodies without Content-Length are cut at max bytes,
+// so decoding of them fails, see bodyError.
+func LimitBody(max int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > max {
+				handleErrorResponse(w, errorRequestTooLarge)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, max)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bodyError returns errorRequestTooLarge if decoding of the request body
+// failed because the body was cut by LimitBody, invalid otherwise.
+func bodyError(err, invalid error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errorRequestTooLarge
+	}
+	return invalid
+}
@@ -0,0 +1,132 @@
+package battlefield
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	now := time.Unix(0, 0)
+	rl := NewRateLimiter(logrus.New(), Limit{Rate: 1, Burst: 2}, Limit{})
+	rl.now = func() time.Time { return now }
+
+	h := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+
+	do := func(method, remote string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/shot", nil)
+		req.RemoteAddr = remote
+		h.ServeHTTP(res, req)
+		return res
+	}
+
+	// burst is available at once
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "10.0.0.1:1001").Code)
+
+	res := do(http.MethodPost, "10.0.0.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "1", res.Header().Get("Retry-After"))
+	assert.Equal(t, `{"code":"TOO_MANY_REQUESTS","err":"too many requests"}`, strings.TrimSpace(res.Body.String()))
+
+	// other clients and read requests are not affected
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "10.0.0.2:1000").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "10.0.0.1:1000").Code)
+
+	// bucket is refilled over time
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "10.0.0.1:1000").Code)
+
+	// idle buckets are swept
+	now = now.Add(2 * sweepInterval)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "10.0.0.3:1000").Code)
+	assert.Len(t, rl.buckets, 1)
+}
+
+func TestClientID(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/state", nil)
+	req.RemoteAddr = "10.0.0.1:1000"
+	assert.Equal(t, "ip:10.0.0.1", clientID(req))
+
+	req = req.WithContext(context.WithValue(req.Context(), principalKey{}, Principal{Player: "alice"}))
+	assert.Equal(t, "player:alice", clientID(req))
+}
+
+func TestLimitBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			body:       `{"coord":"A1"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "error, content length exceeded",
+			body:       `{"coord":"` + strings.Repeat("A", 32) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "error, chunked body exceeded",
+			body:       `{"coord":"` + strings.Repeat("A", 32) + `"}`,
+			chunked:    true,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "error, invalid chunked body",
+			body:       `{"coord":`,
+			chunked:    true,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	h := LimitBody(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := ShotRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleErrorResponse(w, bodyError(err, errorInvalidInputParams))
+			return
+		}
+		handleOKResponse(w, ShotResponse{})
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/shot", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			h.ServeHTTP(res, req)
+			assert.Equal(t, tt.wantStatus, res.Code)
+		})
+	}
+}
+
+func TestLimitBody_Handlers(t *testing.T) {
+	logger := logrus.New()
+	handlers := NewHandlers(logger, NewEndpoints(logger, NewTestifyServiceMock(t)))
+	h := LimitBody(16)(http.HandlerFunc(handlers.Shot))
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/shot", strings.NewReader(`{"coord":"`+strings.Repeat("A", 32)+`"}`))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+	assert.Equal(t, `{"code":"REQUEST_TOO_LARGE","err":"request body is too large"}`, strings.TrimSpace(res.Body.String()))
+}
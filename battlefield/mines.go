@@ -0,0 +1,129 @@
+package battlefield
+
+import (
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// Penalties of the attacker who hits a mine.
+const (
+	// MinePenaltyRetaliate gives the owner a free turn to move or to repair
+	// a ship. The field has no fleet of the attacker to shoot back at,
+	// so the retaliatory shot is replaced by the turn of the owner,
+	// and the game should allow moves or repairs.
+	MinePenaltyRetaliate = "retaliate"
+	// MinePenaltySkip takes the next turn from the attacker,
+	// it's the default penalty.
+	MinePenaltySkip = "skip"
+)
+
+// minePenaltyByName returns the penalty, MinePenaltySkip if name is empty,
+// false if it's unknown.
+func minePenaltyByName(name string) (string, bool) {
+	switch name {
+	case "":
+		return MinePenaltySkip, true
+	case MinePenaltyRetaliate, MinePenaltySkip:
+		return name, true
+	}
+	return "", false
+}
+
+// parseMines parses coordinates of mines separated by commas, e.g. "C3,E5".
+func parseMines(s string) ([]coordinates.Coordinate, bool) {
+	parts := strings.Split(s, ",")
+	mines := make([]coordinates.Coordinate, 0, len(parts))
+	for _, p := range parts {
+		c, ok := coordinates.ConvertCoordinate(strings.TrimSpace(p))
+		if !ok {
+			return nil, false
+		}
+		mines = append(mines, c)
+	}
+	return mines, true
+}
+
+// hitMine applies the penalty of the mine to the attacker,
+// the lock should be held.
+func (s *Service) hitMine() {
+	s.f.state.minesHit++
+	switch s.f.minePenalty {
+	case MinePenaltyRetaliate:
+		s.f.state.retaliations++
+	case MinePenaltySkip:
+		s.f.turnLost = true
+	}
+}
+
+// loseTurn spends the turn lost on the mine by the shot at the coordinate,
+// the shot isn't fired. The lock should be held.
+func (s *Service) loseTurn(coordinate string, cl caller) bool {
+	if !s.f.turnLost {
+		return false
+	}
+	s.f.turnLost = false
+	s.f.record(eventSkip, cl, coordinate)
+	s.changed()
+	return true
+}
+
+// placeMines hides mines in cells which don't hold ships. Mines are placed
+// after ships and before the first shot, the number of mines is set
+// by the game.
+func (s *Service) placeMines(coords string, cl caller) error {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("coords", coords).Debug("Service: placeMines started")
+
+	if !s.f.isOwnedBy(cl) {
+		return errorNotBoardOwner
+	}
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+	if s.f.paused {
+		return errorGamePaused
+	}
+	if s.f.mines == 0 {
+		return errorMinesDisabled
+	}
+	if !s.f.shipsAdded {
+		return errorShipsNotPlaced
+	}
+	if s.f.minesAdded {
+		return errorMinesAlreadyPlaced
+	}
+
+	mines, ok := parseMines(coords)
+	if !ok {
+		return errorInvalidCoordinate
+	}
+	if len(mines) != s.f.mines {
+		return errorMinesMismatch
+	}
+	field := s.f.copyField()
+	for _, c := range mines {
+		if c.X >= s.f.size || c.Y >= s.f.size {
+			return errorOutOfBonds.withCoord(c)
+		}
+		cell := &field[c.X][c.Y]
+		switch {
+		case cell.terrain == terrainIsland:
+			return errorCellNotShootable.withCoord(c)
+		case cell.ship != nil:
+			return errorCellIsOccupiedByShip.withCoord(c)
+		case cell.mine:
+			return errorCellHasMine.withCoord(c)
+		}
+		cell.mine = true
+	}
+
+	s.f.field = field
+	s.f.minesAdded = true
+	s.f.state.mineCount = len(mines)
+	s.f.record(eventMines, cl, coords)
+	s.changed()
+	return nil
+}
@@ -0,0 +1,241 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestMinePenaltyByName(t *testing.T) {
+	tests := []struct {
+		name    string
+		penalty string
+		want    string
+		wantOk  bool
+	}{
+		{name: "success, skip by default", want: MinePenaltySkip, wantOk: true},
+		{name: "success, retaliate", penalty: MinePenaltyRetaliate, want: MinePenaltyRetaliate, wantOk: true},
+		{name: "success, skip", penalty: MinePenaltySkip, want: MinePenaltySkip, wantOk: true},
+		{name: "error, unknown penalty", penalty: "explode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := minePenaltyByName(tt.penalty)
+			assert.Equal(t, tt.wantOk, ok)
+			if ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseMines(t *testing.T) {
+	got, ok := parseMines("C3, e5")
+	assert.True(t, ok)
+	assert.Equal(t, []coordinates.Coordinate{{X: 2, Y: 2}, {X: 4, Y: 4}}, got)
+
+	for _, s := range []string{"", "C3,", "C3 E5", "3C"} {
+		_, ok := parseMines(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestService_CreateField_Mines(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        fieldOptions
+		wantPenalty string
+		wantErr     error
+	}{
+		{name: "success, retaliate with moves", opts: fieldOptions{size: 3, mines: 2, moving: true, minePenalty: MinePenaltyRetaliate}, wantPenalty: MinePenaltyRetaliate},
+		{name: "success, retaliate with repairs", opts: fieldOptions{size: 3, mines: 2, repairs: 1, minePenalty: MinePenaltyRetaliate}, wantPenalty: MinePenaltyRetaliate},
+		{name: "success, skip by default", opts: fieldOptions{size: 3, mines: 2}, wantPenalty: MinePenaltySkip},
+		{name: "success, skip by default with moves", opts: fieldOptions{size: 3, mines: 2, moving: true}, wantPenalty: MinePenaltySkip},
+		{name: "success, skip", opts: fieldOptions{size: 3, mines: 2, moving: true, minePenalty: MinePenaltySkip}, wantPenalty: MinePenaltySkip},
+		{name: "success, mines are off", opts: fieldOptions{size: 3, minePenalty: MinePenaltySkip}},
+		{name: "error, owner can't retaliate", opts: fieldOptions{size: 3, mines: 2, minePenalty: MinePenaltyRetaliate}, wantErr: errorRetaliateNotSupported},
+		{name: "error, unknown penalty", opts: fieldOptions{size: 3, mines: 2, minePenalty: "explode"}, wantErr: errorInvalidInputParams},
+		{name: "error, negative number of mines", opts: fieldOptions{size: 3, mines: -1}, wantErr: errorInvalidInputParams},
+		{name: "error, no cells left for ships", opts: fieldOptions{size: 3, mines: 9}, wantErr: errorInvalidInputParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			err := s.createField(tt.opts, caller{})
+			assert.Equal(t, tt.wantErr, err)
+			if err == nil {
+				assert.Equal(t, tt.wantPenalty, s.f.minePenalty)
+			}
+		})
+	}
+}
+
+func TestService_PlaceMines(t *testing.T) {
+	alice := caller{player: "alice"}
+
+	tests := []struct {
+		name    string
+		mines   int
+		noShips bool
+		coords  string
+		cl      caller
+		wantErr error
+	}{
+		{name: "success", mines: 2, coords: "C3,E1", cl: alice},
+		{name: "success, near the ship", mines: 2, coords: "C1,A2", cl: alice},
+		{name: "error, not the owner", mines: 2, coords: "C3,E1", cl: caller{player: "bob"}, wantErr: errorNotBoardOwner},
+		{name: "error, mines are off", coords: "C3,E1", cl: alice, wantErr: errorMinesDisabled},
+		{name: "error, ships not placed", mines: 2, noShips: true, coords: "C3,E1", cl: alice, wantErr: errorShipsNotPlaced},
+		{name: "error, invalid coordinate", mines: 2, coords: "C3,3C", cl: alice, wantErr: errorInvalidCoordinate},
+		{name: "error, too few mines", mines: 2, coords: "C3", cl: alice, wantErr: errorMinesMismatch},
+		{name: "error, out of the field", mines: 2, coords: "C3,F1", cl: alice, wantErr: errorOutOfBonds.withCoord(coord("F1"))},
+		{name: "error, mine on the ship", mines: 2, coords: "C3,B1", cl: alice, wantErr: errorCellIsOccupiedByShip.withCoord(coord("B1"))},
+		{name: "error, mine on the island", mines: 2, coords: "C3,E5", cl: alice, wantErr: errorCellNotShootable.withCoord(coord("E5"))},
+		{name: "error, two mines in one cell", mines: 2, coords: "C3,C3", cl: alice, wantErr: errorCellHasMine.withCoord(coord("C3"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			opts := fieldOptions{size: 5, mines: tt.mines, terrain: Terrain{Islands: []string{"E5"}}}
+			assert.NoError(t, s.createField(opts, alice))
+			if !tt.noShips {
+				assert.NoError(t, s.addShipsByCoordinates("A1 B1", alice))
+			}
+			field := s.f.copyField()
+
+			err := s.placeMines(tt.coords, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				assert.Equal(t, field, s.f.field)
+				assert.False(t, s.f.minesAdded)
+				return
+			}
+			assert.Equal(t, tt.mines, s.state().mineCount)
+			mines, _ := parseMines(tt.coords)
+			for _, c := range mines {
+				assert.True(t, s.f.field[c.X][c.Y].mine)
+			}
+
+			assert.Equal(t, errorMinesAlreadyPlaced, s.placeMines(tt.coords, tt.cl))
+		})
+	}
+}
+
+// newMinesGame creates the advanced game on the field 5x5 with the ship
+// at A1-B1 and mines at C3 and E1.
+func newMinesGame(t *testing.T, opts fieldOptions) *Service {
+	alice := caller{player: "alice"}
+	s := NewService(logrus.New())
+	opts.size, opts.mines, opts.advanced = 5, 2, true
+	assert.NoError(t, s.createField(opts, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1", alice))
+	_, err := s.shot("A1", caller{player: "bob"})
+	assert.Equal(t, errorMinesNotPlaced, err)
+	assert.NoError(t, s.placeMines("C3,E1", alice))
+	return s
+}
+
+func TestService_Mines_Retaliate(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := newMinesGame(t, fieldOptions{moving: true, minePenalty: MinePenaltyRetaliate})
+	_, err := s.moveShip(0, DirectionDown, alice)
+	assert.Equal(t, errorNotYourTurn, err)
+
+	res, err := s.shot("C3", bob)
+	assert.NoError(t, err)
+	assert.Equal(t, shotResult{Mine: true, Penalty: MinePenaltyRetaliate}, res)
+	st := s.state()
+	assert.Equal(t, 2, st.mineCount)
+	assert.Equal(t, 1, st.minesHit)
+	assert.Equal(t, 1, st.retaliations)
+
+	// the owner spends the regular turn first and the free turn then
+	_, err = s.moveShip(0, DirectionDown, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.state().retaliations)
+	_, err = s.moveShip(0, DirectionDown, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.state().retaliations)
+	_, err = s.moveShip(0, DirectionDown, alice)
+	assert.Equal(t, errorNotYourTurn, err)
+
+	// the bomb hits the mine like the shot
+	shots, err := s.bomb("E2", bob)
+	assert.NoError(t, err)
+	assert.Contains(t, shots, cellShot{
+		coord:      coordinates.Coordinate{X: 4, Y: 0},
+		shotResult: shotResult{Mine: true, Penalty: MinePenaltyRetaliate},
+	})
+	assert.Equal(t, 1, s.state().retaliations)
+
+	// free turns are replayed
+	f, err := replay(logrus.New(), s.f.snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.state, f.state)
+	assert.True(t, f.ownerTurn())
+}
+
+func TestService_Mines_Skip(t *testing.T) {
+	bob := caller{player: "bob"}
+	s := newMinesGame(t, fieldOptions{})
+
+	res, err := s.shot("C3", bob)
+	assert.NoError(t, err)
+	assert.Equal(t, shotResult{Mine: true, Penalty: MinePenaltySkip}, res)
+
+	// weapons wait for the shot which spends the lost turn
+	version := s.f.version
+	_, err = s.sonar("A1", bob)
+	assert.Equal(t, errorTurnLost, err)
+	assert.Equal(t, version, s.f.version)
+	assert.Equal(t, weaponStock[WeaponSonar], s.f.weapons[WeaponSonar])
+
+	// invalid shots don't spend it
+	_, err = s.shot("C3", bob)
+	assert.Equal(t, errorCellAlreadyShot.withCoord(coordinates.Coordinate{X: 2, Y: 2}), err)
+	assert.Equal(t, version, s.f.version)
+
+	res, err = s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.Equal(t, shotResult{Skipped: true}, res)
+	assert.Equal(t, version+1, s.f.version)
+	assert.False(t, s.f.field[0][0].shot)
+	assert.Equal(t, 1, s.state().shotCount)
+
+	res, err = s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+	assert.Equal(t, 0, s.state().retaliations)
+
+	// lost turns are replayed
+	_, err = s.shot("E1", bob)
+	assert.NoError(t, err)
+	snap := s.f.snapshot()
+	assert.Equal(t, 2, snap.Mines)
+	assert.Equal(t, MinePenaltySkip, snap.MinePenalty)
+	f, err := replay(logrus.New(), snap)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.state, f.state)
+	assert.Equal(t, s.f.version, f.version)
+	assert.True(t, f.turnLost)
+	assert.True(t, f.field[2][2].mine)
+}
+
+func TestService_Mines_MovingShip(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3, mines: 1, moving: true}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+	assert.NoError(t, s.placeMines("B1", alice))
+	_, err := s.shot("C3", bob)
+	assert.NoError(t, err)
+
+	_, err = s.moveShip(0, DirectionRight, alice)
+	assert.Equal(t, errorCellHasMine.withShip(0).withCoord(coordinates.Coordinate{X: 1, Y: 0}), err)
+}
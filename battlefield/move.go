@@ -0,0 +1,171 @@
+package battlefield
+
+import (
+	"strconv"
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// Directions ships move in. Ships on the square grid move up, down,
+// left and right, ships on the hex grid move left, right and diagonally.
+const (
+	DirectionUp        = "up"
+	DirectionDown      = "down"
+	DirectionLeft      = "left"
+	DirectionRight     = "right"
+	DirectionUpLeft    = "up-left"
+	DirectionUpRight   = "up-right"
+	DirectionDownLeft  = "down-left"
+	DirectionDownRight = "down-right"
+)
+
+// directions are offsets of cells by direction on every grid,
+// each offset is the step to the neighbour of the cell.
+var directions = map[string]map[string][2]int{
+	GridSquare: {
+		DirectionUp:    {0, -1},
+		DirectionDown:  {0, 1},
+		DirectionLeft:  {-1, 0},
+		DirectionRight: {1, 0},
+	},
+	// every row of hexes is shifted by half a hex to the right of the row above
+	GridHex: {
+		DirectionLeft:      {-1, 0},
+		DirectionRight:     {1, 0},
+		DirectionUpLeft:    {0, -1},
+		DirectionUpRight:   {1, -1},
+		DirectionDownLeft:  {-1, 1},
+		DirectionDownRight: {0, 1},
+	},
+}
+
+// direction returns the offset of the direction on the grid of the field.
+func (f Field) direction(name string) ([2]int, bool) {
+	grid, _, _ := gridByName(f.grid)
+	d, ok := directions[grid][name]
+	return d, ok
+}
+
+// formatShipArg returns the argument of the recorded event of the ship,
+// the index of the ship and the direction or the cell, e.g. "2 up" or "2 A1".
+func formatShipArg(id int, arg string) string {
+	return strconv.Itoa(id) + " " + arg
+}
+
+// parseShipArg parses the argument formatted by formatShipArg.
+func parseShipArg(s string) (int, string, bool) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", false
+	}
+	return id, parts[1], true
+}
+
+// moved returns the ship moved by dx and dy on the field. If the ship
+// crosses the edge, it returns the first cell which can't be moved and false.
+func (f Field) moved(sh *ship, dx, dy int) (*ship, coordinates.Coordinate, bool) {
+	cells := make([]coordinates.Coordinate, 0, sh.inner.Len())
+	for _, c := range sh.inner.Sorted() {
+		n, ok := f.cellAt(c, dx, dy)
+		if !ok {
+			return nil, c, false
+		}
+		cells = append(cells, n)
+	}
+	inner := coordinates.NewCoordinates(cells...)
+
+	// corners of the bounding box are inside the field like cells are
+	var c [2]coordinates.Coordinate
+	for i, corner := range sh.c {
+		c[i], _ = f.cellAt(corner, dx, dy)
+	}
+	return &ship{
+		c:          c,
+		inner:      inner,
+		outer:      f.geometry().Ring(inner),
+		aliveCells: inner.Len(),
+		repairs:    sh.repairs,
+	}, coordinates.Coordinate{}, true
+}
+
+// moveShip moves the undamaged ship by one cell in the direction.
+// The owner may move one ship after every move of the attacker.
+// The ship is checked like the placed one, and it can't move onto
+// cells which are already shot. It returns the ship at the new place.
+func (s *Service) moveShip(id int, direction string, cl caller) (string, error) {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("ship", id).WithField("direction", direction).
+		Debug("Service: moveShip started")
+
+	if !s.f.isOwnedBy(cl) {
+		return "", errorNotBoardOwner
+	}
+	if !s.f.moving {
+		return "", errorMovesDisabled
+	}
+	if !s.f.shipsAdded {
+		return "", errorShipsNotPlaced
+	}
+	if !s.f.matches(cl) {
+		return "", errorVersionMismatch
+	}
+	if s.f.paused {
+		return "", errorGamePaused
+	}
+	if !s.f.ownerTurn() || s.f.gameIsOver {
+		return "", errorNotYourTurn
+	}
+	if id < 0 || id >= len(s.f.ships) {
+		return "", errorShipNotFound
+	}
+	sh := s.f.ships[id]
+	if sh.isKnocked {
+		return "", errorShipDamaged.withShip(id)
+	}
+	d, ok := s.f.direction(direction)
+	if !ok {
+		return "", errorInvalidInputParams
+	}
+
+	moved, c, ok := s.f.moved(sh, d[0], d[1])
+	if !ok {
+		return "", errorOutOfBonds.withShip(id).withCoord(c)
+	}
+	for _, c := range moved.inner.Sorted() {
+		if s.f.field[c.X][c.Y].shot {
+			return "", errorCellAlreadyShot.withShip(id).withCoord(c)
+		}
+	}
+
+	// other ships occupy the copy of the field again,
+	// so the field is untouched if the ship can't move
+	field := s.f.copyField()
+	for x := range field {
+		for y := range field[x] {
+			field[x][y].occupied = false
+			field[x][y].ship = nil
+		}
+	}
+	for i, other := range s.f.ships {
+		if i != id {
+			occupy(field, s.f.size, other)
+		}
+	}
+	if problems := placeShip(field, s.f.size, moved); len(problems) > 0 {
+		return "", problems[0].withShip(id)
+	}
+
+	s.f.field = field
+	s.f.ships[id] = moved
+	s.f.spendOwnerTurn()
+	s.f.record(eventMove, cl, formatShipArg(id, direction))
+	s.changed()
+	return moved.String(), nil
+}
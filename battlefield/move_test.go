@@ -0,0 +1,276 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestParseShipArg(t *testing.T) {
+	id, direction, ok := parseShipArg(formatShipArg(2, DirectionUp))
+	assert.True(t, ok)
+	assert.Equal(t, 2, id)
+	assert.Equal(t, DirectionUp, direction)
+
+	for _, s := range []string{"", "2", "two up", "2 up 3"} {
+		_, _, ok := parseShipArg(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestDirections(t *testing.T) {
+	c := coordinates.Coordinate{X: 2, Y: 2}
+	for name, d := range directions {
+		_, g, ok := gridByName(name)
+		assert.True(t, ok, name)
+		neighbours := g.Neighbours(coordinates.NewCoordinates(c))
+		assert.Len(t, d, neighbours.Len(), name)
+		for direction, o := range d {
+			n := coordinates.Coordinate{X: uint(int(c.X) + o[0]), Y: uint(int(c.Y) + o[1])}
+			assert.True(t, neighbours.Contains(n), "%s %s", name, direction)
+		}
+	}
+}
+
+func TestService_MoveShip(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+
+	tests := []struct {
+		name      string
+		opts      fieldOptions
+		shots     []string
+		cl        caller
+		id        int
+		direction string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			want:      "A2 B2",
+		},
+		{
+			name:      "success, the ship crosses the edge of the torus",
+			opts:      fieldOptions{wrap: true},
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionUp,
+			want:      "A5 B5",
+		},
+		{
+			name:      "success, hex grid",
+			opts:      fieldOptions{grid: GridHex},
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDownRight,
+			want:      "A2 B2",
+		},
+		{
+			name:      "error, attacker didn't move",
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorNotYourTurn,
+		},
+		{
+			name:      "error, not the owner",
+			shots:     []string{"E5"},
+			cl:        bob,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorNotBoardOwner,
+		},
+		{
+			name:      "error, out of the field",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionUp,
+			wantErr:   errorOutOfBonds.withShip(0).withCoord(coord("A1")),
+		},
+		{
+			name:      "error, the ship touches other ship",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionRight,
+			wantErr:   errorCellIsOccupiedNearby.withShip(0).withCoord(coord("C1")),
+		},
+		{
+			name:      "error, the ship moves onto terrain",
+			opts:      fieldOptions{terrain: Terrain{Islands: []string{"B2"}}},
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorShipOnTerrain.withShip(0).withCoord(coord("B2")),
+		},
+		{
+			name:      "error, the ship moves onto the shot cell",
+			shots:     []string{"A2"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorCellAlreadyShot.withShip(0).withCoord(coord("A2")),
+		},
+		{
+			name:      "error, damaged ship",
+			shots:     []string{"A1"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorShipDamaged.withShip(0),
+		},
+		{
+			name:      "error, unknown ship",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        2,
+			direction: DirectionDown,
+			wantErr:   errorShipNotFound,
+		},
+		{
+			name:      "error, negative ship index",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        -1,
+			direction: DirectionDown,
+			wantErr:   errorShipNotFound,
+		},
+		{
+			name:      "error, unknown direction",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: "north",
+			wantErr:   errorInvalidInputParams,
+		},
+		{
+			name:      "error, diagonal on square grid",
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDownRight,
+			wantErr:   errorInvalidInputParams,
+		},
+		{
+			name:      "error, hexes have no down",
+			opts:      fieldOptions{grid: GridHex},
+			shots:     []string{"E5"},
+			cl:        alice,
+			id:        0,
+			direction: DirectionDown,
+			wantErr:   errorInvalidInputParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.size = 5
+			tt.opts.moving = true
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(tt.opts, alice))
+			assert.NoError(t, s.addShipsByCoordinates("A1 B1,D2 D3", alice))
+			for _, c := range tt.shots {
+				_, err := s.shot(c, bob)
+				assert.NoError(t, err)
+			}
+			field := s.f.copyField()
+			version := s.f.version
+
+			got, err := s.moveShip(tt.id, tt.direction, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			if err != nil {
+				// the field is untouched
+				assert.Equal(t, field, s.f.field)
+				assert.Equal(t, version, s.f.version)
+				return
+			}
+			assert.Equal(t, version+1, s.f.version)
+
+			// the owner moves once after every move of the attacker
+			_, err = s.moveShip(tt.id, tt.direction, tt.cl)
+			assert.Equal(t, errorNotYourTurn, err)
+		})
+	}
+}
+
+func TestService_MoveShip_Occupancy(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 5, moving: true}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1,E4 E5", alice))
+
+	_, err := s.shot("E1", bob)
+	assert.NoError(t, err)
+	_, err = s.moveShip(0, DirectionDown, alice)
+	assert.NoError(t, err)
+	_, err = s.shot("E2", bob)
+	assert.NoError(t, err)
+	_, err = s.moveShip(0, DirectionRight, alice)
+	assert.NoError(t, err)
+
+	// shots at the old place miss, shots at the new one hit
+	res, err := s.shot("A2", bob)
+	assert.NoError(t, err)
+	assert.False(t, res.Knock)
+	res, err = s.shot("C2", bob)
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+
+	// old cells are free, shots are kept
+	assert.Nil(t, s.f.field[0][0].ship)
+	assert.Nil(t, s.f.field[0][1].ship)
+	assert.True(t, s.f.field[0][1].shot)
+	assert.True(t, s.f.field[2][1].shot)
+	assert.Equal(t, s.f.ships[0], s.f.field[2][1].ship)
+
+	l, err := s.exportShips(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"B2 C2", "E4 E5"}, l.Ships)
+
+	// moves are replayed
+	f, err := replay(logrus.New(), s.f.snapshot())
+	assert.NoError(t, err)
+	assert.True(t, f.moving)
+	assert.Equal(t, s.f.state, f.state)
+	assert.Equal(t, "B2 C2", f.ships[0].String())
+}
+
+func TestService_MoveShip_Disabled(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{}))
+	_, err := s.shot("C3", caller{})
+	assert.NoError(t, err)
+
+	_, err = s.moveShip(0, DirectionDown, caller{})
+	assert.Equal(t, errorMovesDisabled, err)
+}
+
+func TestService_MoveShip_ReplyIsLost(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 5, moving: true}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1,E4 E5", alice))
+
+	// the attacker doesn't wait for the reply of the owner
+	for _, c := range []string{"E1", "E2"} {
+		_, err := s.shot(c, bob)
+		assert.NoError(t, err)
+	}
+	_, err := s.moveShip(0, DirectionDown, alice)
+	assert.NoError(t, err)
+	_, err = s.moveShip(0, DirectionDown, alice)
+	assert.Equal(t, errorNotYourTurn, err)
+}
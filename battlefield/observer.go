@@ -0,0 +1,29 @@
+package battlefield
+
+import (
+	"time"
+)
+
+// Observer describes receiver of Service events, e.g. metrics collector.
+// All methods are called synchronously, so implementation should be fast.
+type Observer interface {
+	// GameCreated is called when new field is created or the paused game is resumed.
+	GameCreated()
+	// GameFinished is called when the last ship of the game is destroyed.
+	GameFinished()
+	// GameDiscarded is called when unfinished game is cleared or paused.
+	GameDiscarded()
+	// ShotFired is called on every successful shot.
+	ShotFired(knock, destroy bool)
+	// LockWaited is called with time spent waiting for the Service lock.
+	LockWaited(write bool, d time.Duration)
+}
+
+// nopObserver is used when no Observer is set.
+type nopObserver struct{}
+
+func (nopObserver) GameCreated()                   {}
+func (nopObserver) GameFinished()                  {}
+func (nopObserver) GameDiscarded()                 {}
+func (nopObserver) ShotFired(_, _ bool)            {}
+func (nopObserver) LockWaited(bool, time.Duration) {}
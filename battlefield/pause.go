@@ -0,0 +1,306 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"time"
+
+	"my/battleship/storage"
+)
+
+// Store key prefixes of paused and archived games, the game identifier
+// follows the prefix, e.g. "games/paused/0123456789abcdef".
+const (
+	pausedKeyPrefix  = "games/paused/"
+	archiveKeyPrefix = "games/archive/"
+)
+
+// pausedGame describes the paused game in the listing.
+type pausedGame struct {
+	id       string
+	owner    string
+	attacker string
+	size     uint
+	rules    string
+	pausedAt time.Time
+	clock    time.Duration
+}
+
+// pausedRecord is the store record of the paused game. Fields of the listing
+// are kept next to the snapshot, so paused games are listed without replaying.
+type pausedRecord struct {
+	snapshot
+	Attacker string        `json:"attacker,omitempty"`
+	PausedAt time.Time     `json:"paused_at"`
+	Clock    time.Duration `json:"clock"`
+}
+
+// pausedRecord returns the store record of the paused game.
+func (f Field) pausedRecord() pausedRecord {
+	return pausedRecord{
+		snapshot: f.snapshot(),
+		Attacker: f.attacker,
+		PausedAt: f.pausedAt,
+		Clock:    f.clock(f.pausedAt),
+	}
+}
+
+// isPlayer checks if caller plays the game: the owner, the attacker or the admin.
+func (f Field) isPlayer(cl caller) bool {
+	return cl.admin || cl.player == "" || cl.player == f.owner || cl.player == f.attacker
+}
+
+// pause freezes the clock of the game at the time.
+func (f *Field) pause(at time.Time) {
+	f.paused = true
+	f.pausedAt = at
+}
+
+// resume starts the clock of the paused game at the time.
+func (f *Field) resume(at time.Time) {
+	f.pausedFor += at.Sub(f.pausedAt)
+	f.paused = false
+	f.pausedAt = time.Time{}
+}
+
+// clock returns the play time of the game at the time. The clock is
+// stopped while the game is paused and when the last ship is destroyed.
+func (f Field) clock(now time.Time) time.Duration {
+	if f.created.IsZero() {
+		return 0
+	}
+	end := now
+	switch {
+	case f.paused:
+		end = f.pausedAt
+	case f.gameIsOver && len(f.log) > 0:
+		end = f.log[len(f.log)-1].At
+	}
+	return end.Sub(f.created) - f.pausedFor
+}
+
+// loadPaused replays the paused game saved in the store.
+func (s *Service) loadPaused(id string) (Field, error) {
+	if s.store == nil {
+		return Field{}, errorGameNotFound
+	}
+	b, err := s.store.Get(pausedKeyPrefix + id)
+	if err == storage.ErrNotFound {
+		return Field{}, errorGameNotFound
+	}
+	if err != nil {
+		s.logger.Errorf("can't load paused game %s: %v", id, err)
+		return Field{}, errorStoreUnavailable
+	}
+	snap := snapshot{}
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Field{}, err
+	}
+	return replay(s.logger, snap)
+}
+
+// pauseGame pauses the current game: the clock is stopped, moves are
+// rejected until the game is resumed, and the game is saved to the store
+// at once. Another game can be created while the game is paused.
+func (s *Service) pauseGame(id string, cl caller) error {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("game", id).Debug("Service: pauseGame started")
+
+	if !s.f.isSet || s.f.id != id {
+		return errorGameNotFound
+	}
+	if !s.f.isPlayer(cl) {
+		return errorNotGamePlayer
+	}
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+	if s.f.gameIsOver {
+		return errorGameIsOver
+	}
+	if s.f.paused {
+		return errorGamePaused
+	}
+
+	// the game is changed only if it's saved
+	f := s.f
+	f.record(eventPause, cl, "")
+	f.pause(f.log[len(f.log)-1].At)
+	if s.store != nil {
+		b, err := json.Marshal(f.pausedRecord())
+		if err == nil {
+			err = s.store.Put(pausedKeyPrefix+id, b)
+		}
+		if err != nil {
+			s.logger.Errorf("can't save paused game %s: %v", id, err)
+			return errorStoreUnavailable
+		}
+	}
+	s.f = f
+	s.changed()
+	s.notify().GameDiscarded()
+	return nil
+}
+
+// resumeGame resumes the paused game. The current game is resumed in place,
+// other games are loaded from the store if the current game is finished
+// or paused too. The finished game is archived before it's replaced.
+func (s *Service) resumeGame(id string, cl caller) error {
+	s.archiveFinished()
+
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("game", id).Debug("Service: resumeGame started")
+
+	f := s.f
+	if !f.isSet || f.id != id {
+		if f.isSet && !f.gameIsOver && !f.paused {
+			return errorFieldAlreadySet
+		}
+		var err error
+		if f, err = s.loadPaused(id); err != nil {
+			return err
+		}
+	}
+	if !f.isPlayer(cl) {
+		return errorNotGamePlayer
+	}
+	if !f.matches(cl) {
+		return errorVersionMismatch
+	}
+	if !f.paused {
+		return errorGameNotPaused
+	}
+	if s.f.id != f.id && s.f.gameIsOver && s.store != nil && !s.f.archived {
+		return errorStoreUnavailable
+	}
+
+	if s.store != nil {
+		if err := s.store.Delete(pausedKeyPrefix + id); err != nil {
+			s.logger.Errorf("can't delete paused game %s: %v", id, err)
+			return errorStoreUnavailable
+		}
+	}
+	f.record(eventResume, cl, "")
+	f.resume(f.log[len(f.log)-1].At)
+	s.f = f
+	s.changed()
+	s.notify().GameCreated()
+	return nil
+}
+
+// loadPausedRecord loads the store record of the paused game by the key.
+func (s *Service) loadPausedRecord(key string) (pausedRecord, error) {
+	b, err := s.store.Get(key)
+	if err != nil {
+		return pausedRecord{}, err
+	}
+	r := pausedRecord{}
+	err = json.Unmarshal(b, &r)
+	return r, err
+}
+
+// pausedGames lists paused games of the caller, all games are listed
+// for the admin and if authentication is disabled. The current game
+// isn't read, so the lock isn't held while records are loaded.
+func (s *Service) pausedGames(cl caller) ([]pausedGame, error) {
+	s.logger.Debug("Service: pausedGames started")
+
+	if s.store == nil {
+		return []pausedGame{}, nil
+	}
+	keys, err := s.store.List(pausedKeyPrefix)
+	if err != nil {
+		s.logger.Errorf("can't list paused games: %v", err)
+		return nil, errorStoreUnavailable
+	}
+	games := make([]pausedGame, 0, len(keys))
+	for _, k := range keys {
+		r, err := s.loadPausedRecord(k)
+		if err != nil {
+			s.logger.Errorf("can't load paused game %s: %v", k, err)
+			continue
+		}
+		if !(Field{owner: r.Owner, attacker: r.Attacker}).isPlayer(cl) {
+			continue
+		}
+		games = append(games, pausedGame{
+			id:       r.ID,
+			owner:    r.Owner,
+			attacker: r.Attacker,
+			size:     r.Size,
+			rules:    r.Rules,
+			pausedAt: r.PausedAt,
+			clock:    r.Clock,
+		})
+	}
+	return games, nil
+}
+
+// ArchivePaused moves games paused for longer than ttl from paused games
+// to the archive, they can't be resumed anymore. It returns the number
+// of archived games. The lock is held while one game is archived,
+// so moves of the current game wait for one game at most.
+func (s *Service) ArchivePaused(ttl time.Duration) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+	keys, err := s.store.List(pausedKeyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	n := 0
+	for _, k := range keys {
+		r, err := s.loadPausedRecord(k)
+		if err == storage.ErrNotFound {
+			// resumed since listed
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if now.Sub(r.PausedAt) <= ttl {
+			continue
+		}
+		f, err := replay(s.logger, r.snapshot)
+		if err != nil {
+			return n, err
+		}
+		archived, err := s.archivePaused(k, f)
+		if err != nil {
+			return n, err
+		}
+		if archived {
+			n++
+			s.logger.Infof("PAUSED GAME %s ARCHIVED", f.id)
+		}
+	}
+	return n, nil
+}
+
+// archivePaused archives the paused game stored by the key as abandoned,
+// false if the game is resumed since it was loaded.
+func (s *Service) archivePaused(key string, f Field) (bool, error) {
+	s.lock()
+	defer s.Unlock()
+
+	if _, err := s.store.Get(key); err == storage.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err := s.putArchived(f, OutcomeAbandoned); err != nil {
+		return false, err
+	}
+	if err := s.store.Delete(key); err != nil {
+		return false, err
+	}
+	if s.f.isSet && s.f.id == f.id {
+		s.f = Field{}
+		s.dirty = true
+	}
+	return true, nil
+}
@@ -0,0 +1,339 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/storage"
+)
+
+// newPausedGame creates the game of alice and bob on the field 3x3
+// with the ship at A1-B1 and pauses it.
+func newPausedGame(t *testing.T) (*Service, *storage.Memory) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	store := storage.NewMemory()
+	s := NewService(logrus.New())
+	s.SetStore(store)
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1", alice))
+	_, err := s.shot("C3", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.pauseGame(s.f.id, bob))
+	return s, store
+}
+
+func TestService_PauseGame(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+
+	tests := []struct {
+		name    string
+		over    bool
+		id      string
+		cl      caller
+		wantErr error
+	}{
+		{name: "success, owner", cl: alice},
+		{name: "success, attacker", cl: bob},
+		{name: "success, admin", cl: caller{admin: true}},
+		{name: "error, unknown game", id: "0123456789abcdef", cl: alice, wantErr: errorGameNotFound},
+		{name: "error, not a player", cl: caller{player: "carol"}, wantErr: errorNotGamePlayer},
+		{name: "error, version mismatch", cl: caller{player: "alice", ifMatch: "W/\"0\""}, wantErr: errorVersionMismatch},
+		{name: "error, game is over", over: true, cl: alice, wantErr: errorGameIsOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemory()
+			s := NewService(logrus.New())
+			s.SetStore(store)
+			assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+			assert.NoError(t, s.addShipsByCoordinates("A1 A1", alice))
+			_, err := s.shot("C3", bob)
+			assert.NoError(t, err)
+			if tt.over {
+				_, err := s.shot("A1", bob)
+				assert.NoError(t, err)
+			}
+			id := s.f.id
+			if tt.id != "" {
+				id = tt.id
+			}
+			version := s.f.version
+
+			err = s.pauseGame(id, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				assert.False(t, s.f.paused)
+				assert.Equal(t, version, s.f.version)
+				_, err := store.Get(pausedKeyPrefix + s.f.id)
+				assert.Equal(t, storage.ErrNotFound, err)
+				return
+			}
+			assert.True(t, s.f.paused)
+			assert.Equal(t, version+1, s.f.version)
+			assert.True(t, s.state().paused)
+
+			b, err := store.Get(pausedKeyPrefix + s.f.id)
+			assert.NoError(t, err)
+			snap := snapshot{}
+			assert.NoError(t, json.Unmarshal(b, &snap))
+			assert.Equal(t, s.f.snapshot(), snap)
+
+			assert.Equal(t, errorGamePaused, s.pauseGame(s.f.id, tt.cl))
+		})
+	}
+}
+
+func TestService_PauseGame_RejectsMoves(t *testing.T) {
+	bob := caller{player: "bob"}
+	s, _ := newPausedGame(t)
+
+	_, err := s.shot("A1", bob)
+	assert.Equal(t, errorGamePaused, err)
+	_, err = s.sonar("A1", bob)
+	assert.Equal(t, errorGamePaused, err)
+	assert.Equal(t, 1, s.state().shotCount)
+}
+
+func TestService_PauseGame_StoreUnavailable(t *testing.T) {
+	alice := caller{player: "alice"}
+	s := NewService(logrus.New())
+	s.SetStore(fullStore{storage.NewMemory()})
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+	version := s.f.version
+
+	assert.Equal(t, errorStoreUnavailable, s.pauseGame(s.f.id, alice))
+	assert.False(t, s.f.paused)
+	assert.Equal(t, version, s.f.version)
+}
+
+func TestService_ResumeGame(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+
+	t.Run("success, the current game", func(t *testing.T) {
+		s, store := newPausedGame(t)
+		id := s.f.id
+		assert.NoError(t, s.resumeGame(id, alice))
+		assert.False(t, s.f.paused)
+		assert.False(t, s.state().paused)
+		_, err := store.Get(pausedKeyPrefix + id)
+		assert.Equal(t, storage.ErrNotFound, err)
+
+		_, err = s.shot("A1", bob)
+		assert.NoError(t, err)
+		assert.Equal(t, errorGameNotPaused, s.resumeGame(id, alice))
+	})
+
+	t.Run("success, the game replaced by another game", func(t *testing.T) {
+		s, _ := newPausedGame(t)
+		id := s.f.id
+		assert.NoError(t, s.createField(fieldOptions{size: 4}, caller{player: "carol"}))
+		assert.NotEqual(t, id, s.f.id)
+
+		// the new game is in progress
+		assert.Equal(t, errorFieldAlreadySet, s.resumeGame(id, alice))
+
+		assert.NoError(t, s.pauseGame(s.f.id, caller{player: "carol"}))
+		assert.NoError(t, s.resumeGame(id, bob))
+		assert.Equal(t, id, s.f.id)
+		assert.Equal(t, uint(3), s.f.size)
+		assert.Equal(t, 1, s.state().shotCount)
+		res, err := s.shot("A1", bob)
+		assert.NoError(t, err)
+		assert.True(t, res.Knock)
+	})
+
+	t.Run("success, the version of the paused game is matched", func(t *testing.T) {
+		s, _ := newPausedGame(t)
+		id, version := s.f.id, s.f.version
+		assert.NoError(t, s.createField(fieldOptions{size: 4}, caller{player: "carol"}))
+		assert.NoError(t, s.pauseGame(s.f.id, caller{player: "carol"}))
+
+		current := caller{player: "bob", ifMatch: etag(s.f.id, s.f.version)}
+		assert.Equal(t, errorVersionMismatch, s.resumeGame(id, current))
+		assert.NoError(t, s.resumeGame(id, caller{player: "bob", ifMatch: etag(id, version)}))
+		assert.Equal(t, id, s.f.id)
+	})
+
+	t.Run("success, the finished game is archived before it's replaced", func(t *testing.T) {
+		s, store := newPausedGame(t)
+		id := s.f.id
+		s.SetStore(fullStore{store})
+		carol := caller{player: "carol"}
+		assert.NoError(t, s.createField(fieldOptions{size: 3}, carol))
+		assert.NoError(t, s.addShipsByCoordinates("A1 A1", carol))
+		_, err := s.shot("A1", bob)
+		assert.NoError(t, err)
+		finished := s.f.id
+		assert.False(t, s.f.archived)
+
+		assert.Equal(t, errorStoreUnavailable, s.resumeGame(id, bob))
+		assert.Equal(t, finished, s.f.id)
+
+		s.SetStore(store)
+		assert.NoError(t, s.resumeGame(id, bob))
+		assert.Equal(t, id, s.f.id)
+		_, err = s.historyRecord(finished, bob)
+		assert.NoError(t, err)
+	})
+
+	t.Run("error, not a player", func(t *testing.T) {
+		s, _ := newPausedGame(t)
+		assert.Equal(t, errorNotGamePlayer, s.resumeGame(s.f.id, caller{player: "carol"}))
+		assert.True(t, s.f.paused)
+	})
+
+	t.Run("error, unknown game", func(t *testing.T) {
+		s, _ := newPausedGame(t)
+		assert.Equal(t, errorGameNotFound, s.resumeGame("0123456789abcdef", alice))
+		assert.Equal(t, errorGameNotFound, NewService(logrus.New()).resumeGame("0123456789abcdef", alice))
+	})
+}
+
+func TestService_PausedGames(t *testing.T) {
+	alice := caller{player: "alice"}
+	s, _ := newPausedGame(t)
+	first := s.f.id
+	assert.NoError(t, s.createField(fieldOptions{size: 4}, caller{player: "carol"}))
+	assert.NoError(t, s.pauseGame(s.f.id, caller{player: "carol"}))
+
+	games, err := s.pausedGames(alice)
+	assert.NoError(t, err)
+	if assert.Len(t, games, 1) {
+		g := games[0]
+		assert.Equal(t, first, g.id)
+		assert.Equal(t, "alice", g.owner)
+		assert.Equal(t, "bob", g.attacker)
+		assert.Equal(t, uint(3), g.size)
+		assert.False(t, g.pausedAt.IsZero())
+	}
+
+	games, err = s.pausedGames(caller{admin: true})
+	assert.NoError(t, err)
+	assert.Len(t, games, 2)
+
+	games, err = s.pausedGames(caller{player: "dave"})
+	assert.NoError(t, err)
+	assert.Empty(t, games)
+
+	games, err = NewService(logrus.New()).pausedGames(alice)
+	assert.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestService_PausedGames_NoReplay(t *testing.T) {
+	s, store := newPausedGame(t)
+	r := s.f.pausedRecord()
+
+	// games are listed from the record, its moves aren't replayed
+	r.Log = []event{{Kind: "unknown"}}
+	b, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(pausedKeyPrefix+r.ID, b))
+
+	games, err := s.pausedGames(caller{player: "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, []pausedGame{{
+		id:       r.ID,
+		owner:    "alice",
+		attacker: "bob",
+		size:     3,
+		rules:    r.Rules,
+		pausedAt: r.PausedAt,
+		clock:    r.Clock,
+	}}, games)
+}
+
+func TestField_Clock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := Field{created: start}
+	assert.Equal(t, time.Minute, f.clock(start.Add(time.Minute)))
+
+	// the clock is stopped while the game is paused
+	f.pause(start.Add(time.Minute))
+	assert.Equal(t, time.Minute, f.clock(start.Add(time.Hour)))
+	f.resume(start.Add(time.Hour))
+	assert.Equal(t, time.Minute+time.Second, f.clock(start.Add(time.Hour+time.Second)))
+
+	// the clock is stopped when the game is over
+	f.gameIsOver = true
+	f.log = []event{{At: start.Add(2 * time.Hour)}}
+	assert.Equal(t, time.Hour+time.Minute, f.clock(start.Add(3*time.Hour)))
+
+	assert.Equal(t, time.Duration(0), Field{}.clock(start))
+}
+
+func TestService_PauseGame_Replay(t *testing.T) {
+	s, _ := newPausedGame(t)
+	f, err := replay(logrus.New(), s.f.snapshot())
+	assert.NoError(t, err)
+	assert.True(t, f.paused)
+	assert.Equal(t, s.f.pausedAt, f.pausedAt)
+	assert.Equal(t, s.f.created, f.created)
+	assert.Equal(t, s.f.clock(time.Now()), f.clock(time.Now()))
+
+	assert.NoError(t, s.resumeGame(s.f.id, caller{player: "alice"}))
+	f, err = replay(logrus.New(), s.f.snapshot())
+	assert.NoError(t, err)
+	assert.False(t, f.paused)
+	assert.Equal(t, s.f.pausedFor, f.pausedFor)
+}
+
+func TestService_ArchivePaused(t *testing.T) {
+	s, store := newPausedGame(t)
+	id := s.f.id
+
+	// the game paused recently is kept
+	n, err := s.ArchivePaused(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// the game paused long ago is archived
+	r := s.f.pausedRecord()
+	snap := r.snapshot
+	snap.Created = snap.Created.Add(-3 * time.Hour)
+	for i := range snap.Log {
+		snap.Log[i].At = snap.Log[i].At.Add(-2 * time.Hour)
+	}
+	r.snapshot, r.PausedAt = snap, r.PausedAt.Add(-2*time.Hour)
+	b, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(pausedKeyPrefix+id, b))
+
+	n, err = s.ArchivePaused(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = store.Get(pausedKeyPrefix + id)
+	assert.Equal(t, storage.ErrNotFound, err)
+	g, err := s.historyRecord(id, caller{player: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeAbandoned, g.Outcome)
+	assert.Equal(t, snap, g.snapshot)
+	assert.Equal(t, snap.Log[len(snap.Log)-1].At, g.Finished)
+	assert.Equal(t, time.Hour, g.Clock.Round(time.Minute))
+	assert.False(t, s.f.isSet)
+	assert.Equal(t, errorGameNotFound, s.resumeGame(id, caller{player: "alice"}))
+
+	// the game resumed after it was loaded isn't archived
+	archived, err := s.archivePaused(pausedKeyPrefix+id, Field{id: id})
+	assert.NoError(t, err)
+	assert.False(t, archived)
+
+	n, err = NewService(logrus.New()).ArchivePaused(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+// fullStore fails to save values.
+type fullStore struct {
+	storage.Store
+}
+
+func (fullStore) Put(string, []byte) error {
+	return errors.New("disk is full")
+}
@@ -0,0 +1,225 @@
+package battlefield
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// maxPlaceAttempts limits attempts to place the fleet randomly.
+const maxPlaceAttempts = 100
+
+// newSeed generates random seed of the game.
+func newSeed() int64 {
+	b := make([]byte, 8)
+	_, _ = crand.Read(b)
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+// random returns source of randomness of the next move.
+// It depends only on the seed and the number of recorded moves,
+// so replaying the same moves on the game with the same seed
+// gives the same result, and rejected moves don't affect next ones.
+func (f Field) random() *rand.Rand {
+	return rand.New(rand.NewSource(f.seed + int64(len(f.log))))
+}
+
+// fleetLengths returns lengths of ships of the fleet, the longest first.
+func fleetLengths(fleet map[int]int) []int {
+	var lengths []int
+	for length, n := range fleet {
+		for i := 0; i < n; i++ {
+			lengths = append(lengths, length)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+	return lengths
+}
+
+// randomFleet places straight ships of the lengths on the empty field
+// of the size, so that ships don't touch each other and terrain cells.
+// Ships are placed along rows and columns, which are straight lines of
+// both grids, on the torus field they may cross its edges. It returns
+// ships in the format of AddShipsRequest, false if ships don't fit.
+func randomFleet(rng *rand.Rand, g coordinates.Grid, size uint, wrap bool, lengths []int, terrain coordinates.Coordinates) (string, bool) {
+	type position struct {
+		x, y       uint
+		horizontal bool
+	}
+	// cellsAt returns cells of the ship at the position, false if
+	// the ship crosses the edge of the field which doesn't wrap
+	cellsAt := func(p position, length uint) ([]coordinates.Coordinate, bool) {
+		cells := make([]coordinates.Coordinate, length)
+		for i := uint(0); i < length; i++ {
+			x, y := p.x, p.y+i
+			if p.horizontal {
+				x, y = p.x+i, p.y
+			}
+			if wrap {
+				x, y = x%size, y%size
+			}
+			if x >= size || y >= size {
+				return nil, false
+			}
+			cells[i] = coordinates.Coordinate{X: x, Y: y}
+		}
+		return cells, true
+	}
+
+	for attempt := 0; attempt < maxPlaceAttempts; attempt++ {
+		// blocked cells are occupied by ships or near them
+		blocked := make([][]bool, size)
+		for i := range blocked {
+			blocked[i] = make([]bool, size)
+		}
+		for _, c := range terrain.Sorted() {
+			blocked[c.X][c.Y] = true
+		}
+		free := func(p position, length uint) bool {
+			cells, ok := cellsAt(p, length)
+			if !ok {
+				return false
+			}
+			for _, c := range cells {
+				if blocked[c.X][c.Y] {
+					return false
+				}
+			}
+			return true
+		}
+
+		ships := make([]string, 0, len(lengths))
+		for _, l := range lengths {
+			length := uint(l)
+			var candidates []position
+			for x := uint(0); x < size; x++ {
+				for y := uint(0); y < size; y++ {
+					for _, horizontal := range []bool{true, false} {
+						p := position{x: x, y: y, horizontal: horizontal}
+						if (length > 1 || horizontal) && free(p, length) {
+							candidates = append(candidates, p)
+						}
+					}
+				}
+			}
+			if len(candidates) == 0 {
+				break
+			}
+
+			p := candidates[rng.Intn(len(candidates))]
+			line, _ := cellsAt(p, length)
+			cells := coordinates.NewCoordinates(line...)
+			for _, c := range cells.Union(g.Ring(cells)).Clip(size).Sorted() {
+				blocked[c.X][c.Y] = true
+			}
+			ships = append(ships, formatCells(g, line, cells))
+		}
+		if len(ships) == len(lengths) {
+			return strings.Join(ships, ","), true
+		}
+	}
+	return "", false
+}
+
+// formatCells returns the ship of cells from the first one to the last one
+// by two corners, or cell by cell if corners set another ship on the grid,
+// e.g. a long ship crossing the edge of the torus.
+func formatCells(g coordinates.Grid, line []coordinates.Coordinate, cells coordinates.Coordinates) string {
+	start, end := line[0], line[len(line)-1]
+	if area := g.Area(start, end); area.Len() == cells.Len() && area.Difference(cells).Len() == 0 {
+		return start.String() + " " + end.String()
+	}
+	l := make([]string, 0, cells.Len())
+	for _, c := range cells.Sorted() {
+		l = append(l, c.String())
+	}
+	return strings.Join(l, cellSeparator)
+}
+
+// autoPlaceShips places ships of the lengths randomly, lengths of
+// the rules fleet are used if empty. The placement is recorded as
+// the lengths, so it's repeated on replay.
+func (s *Service) autoPlaceShips(lengths []int, cl caller) error {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("lengths", lengths).Debug("Service: autoPlaceShips started")
+
+	if err := s.canPlace(cl); err != nil {
+		return err
+	}
+	coords, err := s.randomFleet(lengths)
+	if err != nil {
+		return err
+	}
+	if err := s.placeFleet(coords, cl); err != nil {
+		return err
+	}
+	s.f.record(eventAuto, cl, formatLengths(lengths))
+	s.changed()
+	return nil
+}
+
+// randomFleet returns random placement of ships of the lengths
+// on the field, the lock should be held.
+func (s *Service) randomFleet(lengths []int) (string, error) {
+	if len(lengths) == 0 {
+		_, r, _ := rulesByName(s.f.rules)
+		if r.fleet == nil {
+			return "", errorInvalidInputParams
+		}
+		lengths = fleetLengths(r.fleet)
+	}
+
+	cells := 0
+	for _, l := range lengths {
+		if l < 1 {
+			return "", errorInvalidInputParams
+		}
+		if uint(l) > s.f.size {
+			return "", errorFleetDoesNotFit
+		}
+		cells += l
+	}
+	terrain := s.f.terrainCells()
+	if uint(cells+terrain.Len()) > s.f.size*s.f.size {
+		return "", errorFleetDoesNotFit
+	}
+
+	coords, ok := randomFleet(s.f.random(), s.f.geometry(), s.f.size, s.f.wrap, lengths, terrain)
+	if !ok {
+		return "", errorFleetDoesNotFit
+	}
+	return coords, nil
+}
+
+// formatLengths returns lengths separated by commas.
+func formatLengths(lengths []int) string {
+	s := make([]string, len(lengths))
+	for i, l := range lengths {
+		s[i] = strconv.Itoa(l)
+	}
+	return strings.Join(s, ",")
+}
+
+// parseLengths parses lengths separated by commas, empty string is no lengths.
+func parseLengths(s string) ([]int, bool) {
+	if s == "" {
+		return nil, true
+	}
+	parts := strings.Split(s, ",")
+	lengths := make([]int, len(parts))
+	for i, p := range parts {
+		l, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		lengths[i] = l
+	}
+	return lengths, true
+}
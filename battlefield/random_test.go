@@ -0,0 +1,270 @@
+package battlefield
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFleetLengths(t *testing.T) {
+	assert.Equal(t, []int{4, 3, 3, 2, 2, 2, 1, 1, 1, 1}, fleetLengths(presets[RulesClassic].fleet))
+	assert.Empty(t, fleetLengths(nil))
+}
+
+func TestRandomFleet(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    uint
+		lengths []int
+		terrain Terrain
+		wrap    bool
+		wantOK  bool
+	}{
+		{
+			name:    "classic fleet",
+			size:    10,
+			lengths: fleetLengths(presets[RulesClassic].fleet),
+			wantOK:  true,
+		},
+		{
+			name:    "single cell",
+			size:    1,
+			lengths: []int{1},
+			wantOK:  true,
+		},
+		{
+			name:    "ships touch each other",
+			size:    2,
+			lengths: []int{1, 1},
+			wantOK:  false,
+		},
+		{
+			name:    "terrain leaves one place",
+			size:    3,
+			lengths: []int{3},
+			terrain: Terrain{Islands: []string{"A1 C1"}, Reefs: []string{"B2"}},
+			wantOK:  true,
+		},
+		{
+			name:    "terrain leaves no place",
+			size:    3,
+			lengths: []int{3},
+			terrain: Terrain{Islands: []string{"A2 C2", "B1", "B3"}},
+			wantOK:  false,
+		},
+		{
+			name:    "terrain leaves places across edges only",
+			size:    4,
+			lengths: []int{3},
+			terrain: Terrain{Islands: []string{"B1 B4", "A2 D2"}},
+			wantOK:  false,
+		},
+		{
+			name:    "wrap, terrain leaves places across edges only",
+			size:    4,
+			lengths: []int{3},
+			terrain: Terrain{Islands: []string{"B1 B4", "A2 D2"}},
+			wrap:    true,
+			wantOK:  true,
+		},
+		{
+			name:    "wrap, ships touch across edges",
+			size:    3,
+			lengths: []int{1, 1},
+			wrap:    true,
+			wantOK:  false,
+		},
+		{
+			name:    "wrap, classic fleet",
+			size:    10,
+			lengths: fleetLengths(presets[RulesClassic].fleet),
+			wrap:    true,
+			wantOK:  true,
+		},
+		{
+			name:    "ship is too long",
+			size:    3,
+			lengths: []int{4},
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewField(tt.size)
+			f.wrap = tt.wrap
+			assert.NoError(t, f.setTerrain(tt.terrain))
+			terrain := f.terrainCells()
+			g := f.geometry()
+
+			coords, ok := randomFleet(rand.New(rand.NewSource(1)), g, tt.size, tt.wrap, tt.lengths, terrain)
+			assert.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+
+			// the same seed gives the same fleet
+			again, _ := randomFleet(rand.New(rand.NewSource(1)), g, tt.size, tt.wrap, tt.lengths, terrain)
+			assert.Equal(t, coords, again)
+
+			// and the fleet is valid
+			ships, err := makeShipsFromCoords(coords, g)
+			assert.NoError(t, err)
+			for i, sh := range ships {
+				assert.Equal(t, tt.lengths[i], sh.inner.Len())
+			}
+			assert.NoError(t, presets[RulesFree].checkFleet(ships, g))
+			s := &Service{f: f, logger: logrus.New()}
+			assert.NoError(t, s.addShips(ships))
+		})
+	}
+}
+
+func TestService_AutoPlaceShips(t *testing.T) {
+	alice := caller{player: "alice"}
+
+	tests := []struct {
+		name      string
+		opts      fieldOptions
+		lengths   []int
+		cl        caller
+		wantErr   error
+		wantShips int
+	}{
+		{
+			name:      "success, rules fleet",
+			opts:      fieldOptions{size: 10, rules: RulesClassic, seed: 1},
+			cl:        alice,
+			wantShips: 10,
+		},
+		{
+			name:      "success, lengths",
+			opts:      fieldOptions{size: 5, seed: 1},
+			lengths:   []int{3, 2, 1},
+			cl:        alice,
+			wantShips: 3,
+		},
+		{
+			name:    "error, no fleet",
+			opts:    fieldOptions{size: 5},
+			cl:      alice,
+			wantErr: errorInvalidInputParams,
+		},
+		{
+			name:    "error, invalid length",
+			opts:    fieldOptions{size: 5},
+			lengths: []int{0},
+			cl:      alice,
+			wantErr: errorInvalidInputParams,
+		},
+		{
+			name:    "error, ship is too long",
+			opts:    fieldOptions{size: 5},
+			lengths: []int{6},
+			cl:      alice,
+			wantErr: errorFleetDoesNotFit,
+		},
+		{
+			name:    "error, too many ships",
+			opts:    fieldOptions{size: 2},
+			lengths: []int{1, 1, 1, 1, 1},
+			cl:      alice,
+			wantErr: errorFleetDoesNotFit,
+		},
+		{
+			name:    "error, ships touch each other",
+			opts:    fieldOptions{size: 2},
+			lengths: []int{1, 1},
+			cl:      alice,
+			wantErr: errorFleetDoesNotFit,
+		},
+		{
+			name:    "error, fleet doesn't match the rules",
+			opts:    fieldOptions{size: 10, rules: RulesClassic},
+			lengths: []int{1},
+			cl:      alice,
+			wantErr: errorFleetMismatch,
+		},
+		{
+			name:    "error, not board owner",
+			opts:    fieldOptions{size: 5},
+			lengths: []int{1},
+			cl:      caller{player: "bob"},
+			wantErr: errorNotBoardOwner,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(tt.opts, alice))
+
+			err := s.autoPlaceShips(tt.lengths, tt.cl)
+			assertError(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantShips, s.state().shipCount)
+			if err != nil {
+				assert.Empty(t, s.f.log)
+			}
+		})
+	}
+}
+
+func TestService_AutoPlaceShips_Seed(t *testing.T) {
+	alice := caller{player: "alice"}
+	place := func(seed int64, rejected bool) []string {
+		s := NewService(logrus.New())
+		assert.NoError(t, s.createField(fieldOptions{size: 10, rules: RulesClassic, seed: seed}, alice))
+		if rejected {
+			assertError(t, errorFleetMismatch, s.autoPlaceShips([]int{1}, alice))
+		}
+		assert.NoError(t, s.autoPlaceShips(nil, alice))
+		l, err := s.exportShips(alice)
+		assert.NoError(t, err)
+		return l.Ships
+	}
+
+	// the same seed gives the same game, rejected moves don't matter
+	assert.Equal(t, place(42, false), place(42, false))
+	assert.Equal(t, place(42, false), place(42, true))
+	assert.NotEqual(t, place(42, false), place(43, false))
+}
+
+func TestParseLengths(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   []int
+		wantOK bool
+	}{
+		{s: "", want: nil, wantOK: true},
+		{s: "4,1", want: []int{4, 1}, wantOK: true},
+		{s: "4,a", want: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseLengths(tt.s)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+			if ok {
+				assert.Equal(t, tt.s, formatLengths(got))
+			}
+		})
+	}
+}
+
+func TestReplay_AutoPlaceShips(t *testing.T) {
+	alice := caller{player: "alice"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 10, rules: RulesClassic, seed: 7}, alice))
+	assert.NoError(t, s.autoPlaceShips(nil, alice))
+	_, err := s.shot("A1", caller{player: "bob"})
+	assert.NoError(t, err)
+
+	f, err := replay(logrus.New(), s.f.snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.seed, f.seed)
+	assert.Equal(t, s.f.field, f.field)
+	assert.Equal(t, s.f.state, f.state)
+}
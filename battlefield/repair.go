@@ -0,0 +1,65 @@
+package battlefield
+
+// repairShip repairs the hit cell of the damaged ship which is not
+// destroyed yet. The repair takes the turn of the owner like the move
+// of the ship does. The cell is not shot anymore, but the attacker
+// still sees the hit until they shoot the cell again.
+// It returns the number of repairs left for the ship.
+func (s *Service) repairShip(id int, coordinate string, cl caller) (int, error) {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("ship", id).WithField("coordinate", coordinate).
+		Debug("Service: repairShip started")
+
+	if !s.f.isOwnedBy(cl) {
+		return 0, errorNotBoardOwner
+	}
+	if s.f.repairs == 0 {
+		return 0, errorRepairsDisabled
+	}
+	if !s.f.shipsAdded {
+		return 0, errorShipsNotPlaced
+	}
+	if !s.f.matches(cl) {
+		return 0, errorVersionMismatch
+	}
+	if s.f.paused {
+		return 0, errorGamePaused
+	}
+	if !s.f.ownerTurn() || s.f.gameIsOver {
+		return 0, errorNotYourTurn
+	}
+	if id < 0 || id >= len(s.f.ships) {
+		return 0, errorShipNotFound
+	}
+	c, err := s.f.parseTarget(coordinate)
+	if err != nil {
+		return 0, err
+	}
+	sh := s.f.ships[id]
+	if sh.aliveCells == 0 {
+		return 0, errorShipDestroyed.withShip(id)
+	}
+	if sh.repairs >= s.f.repairs {
+		return 0, errorRepairsExhausted.withShip(id)
+	}
+	cell := &s.f.field[c.X][c.Y]
+	if !sh.inner.Contains(c) || !cell.shot {
+		return 0, errorCellNotDamaged.withShip(id).withCoord(c)
+	}
+
+	cell.shot = false
+	cell.repaired = true
+	sh.aliveCells++
+	sh.repairs++
+	if sh.aliveCells == sh.inner.Len() {
+		// the ship is healthy again
+		sh.isKnocked = false
+		s.f.state.knocked--
+	}
+	s.f.spendOwnerTurn()
+	s.f.record(eventRepair, cl, formatShipArg(id, coordinate))
+	s.changed()
+	return s.f.repairs - sh.repairs, nil
+}
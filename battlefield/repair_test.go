@@ -0,0 +1,193 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestService_RepairShip(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+
+	tests := []struct {
+		name        string
+		shots       []string
+		cl          caller
+		id          int
+		coordinate  string
+		want        int
+		wantErr     error
+		wantKnocked int
+	}{
+		{
+			name:        "success, the ship is healthy again",
+			shots:       []string{"A1"},
+			cl:          alice,
+			id:          0,
+			coordinate:  "A1",
+			want:        1,
+			wantKnocked: 0,
+		},
+		{
+			name:        "success, the ship is still damaged",
+			shots:       []string{"A1", "B1"},
+			cl:          alice,
+			id:          0,
+			coordinate:  "B1",
+			want:        1,
+			wantKnocked: 1,
+		},
+		{
+			name:       "error, attacker didn't move",
+			cl:         alice,
+			id:         0,
+			coordinate: "A1",
+			wantErr:    errorNotYourTurn,
+		},
+		{
+			name:        "error, not the owner",
+			shots:       []string{"A1"},
+			cl:          bob,
+			id:          0,
+			coordinate:  "A1",
+			wantErr:     errorNotBoardOwner,
+			wantKnocked: 1,
+		},
+		{
+			name:        "error, unknown ship",
+			shots:       []string{"A1"},
+			cl:          alice,
+			id:          2,
+			coordinate:  "A1",
+			wantErr:     errorShipNotFound,
+			wantKnocked: 1,
+		},
+		{
+			name:        "error, invalid coordinate",
+			shots:       []string{"A1"},
+			cl:          alice,
+			id:          0,
+			coordinate:  "1A",
+			wantErr:     errorInvalidCoordinate,
+			wantKnocked: 1,
+		},
+		{
+			name:        "error, cell is not hit",
+			shots:       []string{"A1"},
+			cl:          alice,
+			id:          0,
+			coordinate:  "B1",
+			wantErr:     errorCellNotDamaged.withShip(0).withCoord(coord("B1")),
+			wantKnocked: 1,
+		},
+		{
+			name:        "error, cell of other ship",
+			shots:       []string{"A1", "E4"},
+			cl:          alice,
+			id:          0,
+			coordinate:  "E4",
+			wantErr:     errorCellNotDamaged.withShip(0).withCoord(coord("E4")),
+			wantKnocked: 2,
+		},
+		{
+			name:        "error, destroyed ship",
+			shots:       []string{"E4", "E5"},
+			cl:          alice,
+			id:          1,
+			coordinate:  "E4",
+			wantErr:     errorShipDestroyed.withShip(1),
+			wantKnocked: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(fieldOptions{size: 5, repairs: 2}, alice))
+			assert.NoError(t, s.addShipsByCoordinates("A1 C1,E4 E5", alice))
+			for _, c := range tt.shots {
+				_, err := s.shot(c, bob)
+				assert.NoError(t, err)
+			}
+			field := s.f.copyField()
+			version := s.f.version
+
+			got, err := s.repairShip(tt.id, tt.coordinate, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantKnocked, s.state().knocked)
+			if err != nil {
+				assert.Equal(t, field, s.f.field)
+				assert.Equal(t, version, s.f.version)
+				return
+			}
+			assert.Equal(t, version+1, s.f.version)
+
+			// the cell is not shot, but the attacker still sees the hit
+			c := coord(tt.coordinate)
+			assert.False(t, s.f.field[c.X][c.Y].shot)
+			assert.Equal(t, string(symbolHit), string(s.view(bob, false).rows[c.Y][c.X]))
+			assert.Equal(t, string(symbolShip), string(s.view(alice, false).rows[c.Y][c.X]))
+			assert.Equal(t, tt.wantKnocked > 0, s.f.ships[tt.id].isKnocked)
+
+			// the owner repairs once after every move of the attacker
+			_, err = s.repairShip(tt.id, tt.coordinate, tt.cl)
+			assert.Equal(t, errorNotYourTurn, err)
+		})
+	}
+}
+
+func TestService_RepairShip_Limit(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 5, repairs: 1, moving: true}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1,E4 E5", alice))
+
+	_, err := s.shot("A1", bob)
+	assert.NoError(t, err)
+	left, err := s.repairShip(0, "A1", alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, left)
+
+	// the repaired cell is hit again
+	res, err := s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+	assert.Equal(t, 1, s.state().knocked)
+	_, err = s.repairShip(0, "A1", alice)
+	assert.Equal(t, errorRepairsExhausted.withShip(0), err)
+
+	// the moved ship keeps the number of repairs
+	_, err = s.repairShip(1, "E4", alice)
+	assert.Equal(t, errorCellNotDamaged.withShip(1).withCoord(coordinates.Coordinate{X: 4, Y: 3}), err)
+	_, err = s.moveShip(1, DirectionLeft, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.f.ships[1].repairs)
+	assert.Equal(t, 1, s.f.ships[0].repairs)
+
+	// repairs are replayed
+	snap := s.f.snapshot()
+	assert.Equal(t, 1, snap.Repairs)
+	f, err := replay(logrus.New(), snap)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.state, f.state)
+	assert.Equal(t, 1, f.ships[0].repairs)
+	assert.Equal(t, 1, f.ships[0].aliveCells)
+}
+
+func TestService_RepairShip_Disabled(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1", caller{}))
+	_, err := s.shot("A1", caller{})
+	assert.NoError(t, err)
+
+	_, err = s.repairShip(0, "A1", caller{})
+	assert.Equal(t, errorRepairsDisabled, err)
+	assert.False(t, s.f.defenderTurn)
+
+	assert.Equal(t, errorInvalidInputParams, NewService(logrus.New()).createField(fieldOptions{size: 3, repairs: -1}, caller{}))
+}
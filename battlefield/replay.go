@@ -0,0 +1,298 @@
+package battlefield
+
+import (
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+
+	"my/battleship/coordinates"
+)
+
+// replayFrame is the field seen by the owner after the move,
+// move is nil for the field before the first move.
+type replayFrame struct {
+	move *event
+	rows []string
+}
+
+// historyReplay replays the archived game move by move, see historyRecord.
+func (s *Service) historyReplay(id string, cl caller) (archivedGame, []replayFrame, error) {
+	s.logger.WithField("game", id).Debug("Service: historyReplay started")
+
+	g, err := s.historyRecord(id, cl)
+	if err != nil {
+		return archivedGame{}, nil, err
+	}
+
+	start := g.snapshot
+	start.Log = nil
+	f, err := replay(s.logger, start)
+	if err != nil {
+		return archivedGame{}, nil, err
+	}
+	frames := make([]replayFrame, 0, len(g.Log)+1)
+	frames = append(frames, replayFrame{rows: f.rows(false)})
+	_, err = replaySteps(s.logger, g.snapshot, func(e event, f Field) {
+		move := e
+		frames = append(frames, replayFrame{move: &move, rows: f.rows(false)})
+	})
+	if err != nil {
+		return archivedGame{}, nil, err
+	}
+	return g, frames, nil
+}
+
+// target returns the cell targeted by the move, if any.
+func (m HistoryMove) target() (coordinates.Coordinate, bool) {
+	switch m.Kind {
+	case eventShot, eventSonar, eventBomb:
+		return coordinates.ConvertCoordinate(m.Arg)
+	}
+	return coordinates.Coordinate{}, false
+}
+
+// Replay image settings: the size of the cell in pixels and frame
+// delays in hundredths of a second, the last frame is held longer.
+const (
+	replayCellSize  = 16
+	replayDelay     = 50
+	replayLastDelay = 300
+)
+
+// replayColors are colors of cell symbols in replay images and pages.
+var replayColors = map[byte]color.RGBA{
+	symbolWater:    {R: 0xbb, G: 0xdd, B: 0xff, A: 0xff},
+	symbolShip:     {R: 0x66, G: 0x66, B: 0x77, A: 0xff},
+	symbolHit:      {R: 0xff, G: 0x88, B: 0x00, A: 0xff},
+	symbolSunk:     {R: 0xaa, G: 0x11, B: 0x11, A: 0xff},
+	symbolMiss:     {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	symbolIsland:   {R: 0x55, G: 0x99, B: 0x33, A: 0xff},
+	symbolReef:     {R: 0x44, G: 0xbb, B: 0xaa, A: 0xff},
+	symbolShotReef: {R: 0x22, G: 0x77, B: 0x66, A: 0xff},
+	symbolMine:     {R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+	symbolBlast:    {R: 0xff, G: 0xee, B: 0x00, A: 0xff},
+}
+
+// replaySymbols returns symbols of replayColors in order.
+func replaySymbols() []byte {
+	symbols := make([]byte, 0, len(replayColors))
+	for symbol := range replayColors {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	return symbols
+}
+
+// Colors of grid lines and of the target of the move.
+var (
+	replayLineColor   = color.RGBA{R: 0x22, G: 0x44, B: 0x66, A: 0xff}
+	replayTargetColor = color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+)
+
+// renderReplayGIF writes animated GIF image with a frame per move,
+// the target of the move is outlined. Rows of hex grid are shifted
+// by half a cell per row like in renderField.
+func renderReplayGIF(w io.Writer, r HistoryReplayResponse) error {
+	palette := color.Palette{replayLineColor, replayTargetColor}
+	index := make(map[byte]uint8, len(replayColors))
+	for _, symbol := range replaySymbols() {
+		index[symbol] = uint8(len(palette))
+		palette = append(palette, replayColors[symbol])
+	}
+
+	hex := r.Grid == GridHex
+	size := int(r.Size)
+	width, height := size*replayCellSize+1, size*replayCellSize+1
+	if hex {
+		width += (size - 1) * replayCellSize / 2
+	}
+
+	anim := &gif.GIF{}
+	for i, frame := range r.Frames {
+		img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		target, hasTarget := coordinates.Coordinate{}, false
+		if frame.Move != nil {
+			target, hasTarget = frame.Move.target()
+		}
+		for y, row := range frame.Board {
+			shift := 0
+			if hex {
+				shift = y * replayCellSize / 2
+			}
+			for x := 0; x < len(row); x++ {
+				x0, y0 := shift+x*replayCellSize, y*replayCellSize
+				fill := index[row[x]]
+				for py := 1; py < replayCellSize; py++ {
+					for px := 1; px < replayCellSize; px++ {
+						c := fill
+						edge := px < 3 || py < 3 || px > replayCellSize-3 || py > replayCellSize-3
+						if hasTarget && edge && target.X == uint(x) && target.Y == uint(y) {
+							c = 1
+						}
+						img.SetColorIndex(x0+px, y0+py, c)
+					}
+				}
+			}
+		}
+		delay := replayDelay
+		if i == len(r.Frames)-1 {
+			delay = replayLastDelay
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// replayPage is the self-contained HTML page of the replay: styles,
+// the script and frames are embedded, so it needs no other requests.
+var replayPage = template.Must(template.New("replay").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Game {{.Game.ID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.row { display: flex; height: 24px; }
+.cell { width: 22px; height: 22px; margin: 1px; box-sizing: border-box; }
+.target { border: 3px solid #ff00ff; }
+{{range .Colors}}.c{{.Class}} { background: {{.Color}}; }
+{{end}}
+input[type=range] { width: 30em; }
+</style>
+</head>
+<body>
+<h1>Game {{.Game.ID}}</h1>
+<p>{{with .Game.Owner}}{{.}}{{else}}owner{{end}} vs {{with .Game.Attacker}}{{.}}{{else}}attacker{{end}},
+{{.Game.Rules}} rules, {{.Game.Size}}x{{.Game.Size}}, {{.Game.Outcome}}, {{.Game.Moves}} moves in {{.Game.Duration}}s</p>
+<div id="board"></div>
+<p>
+<button id="play">Play</button>
+<input id="slider" type="range" min="0" max="{{.Last}}" value="0">
+<span id="step"></span>
+</p>
+<p id="move"></p>
+<script>
+var frames = {{.Frames}};
+var hex = {{.Hex}};
+var board = document.getElementById("board");
+var slider = document.getElementById("slider");
+var timer = null;
+
+function show(i) {
+	var f = frames[i];
+	board.innerHTML = "";
+	f.board.forEach(function(row, y) {
+		var r = document.createElement("div");
+		r.className = "row";
+		if (hex) {
+			r.style.marginLeft = (y * 12) + "px";
+		}
+		for (var x = 0; x < row.length; x++) {
+			var c = document.createElement("div");
+			c.className = "cell c" + row.charCodeAt(x);
+			if (f.x === x && f.y === y) {
+				c.className += " target";
+			}
+			r.appendChild(c);
+		}
+		board.appendChild(r);
+	});
+	document.getElementById("step").textContent = i + " / " + (frames.length - 1);
+	document.getElementById("move").textContent = f.move;
+}
+
+function stop() {
+	clearInterval(timer);
+	timer = null;
+	document.getElementById("play").textContent = "Play";
+}
+
+slider.oninput = function() {
+	stop();
+	show(+slider.value);
+};
+document.getElementById("play").onclick = function() {
+	if (timer) {
+		stop();
+		return;
+	}
+	if (+slider.value === frames.length - 1) {
+		slider.value = 0;
+	}
+	this.textContent = "Pause";
+	timer = setInterval(function() {
+		if (+slider.value >= frames.length - 1) {
+			stop();
+			return;
+		}
+		slider.value = +slider.value + 1;
+		show(+slider.value);
+	}, {{.Delay}});
+};
+show(0);
+</script>
+</body>
+</html>
+`))
+
+// replayPageFrame is the frame of the replay page, x and y are
+// the target of the move, -1 if the move has no target.
+type replayPageFrame struct {
+	Board []string `json:"board"`
+	Move  string   `json:"move"`
+	X     int      `json:"x"`
+	Y     int      `json:"y"`
+}
+
+// replayPageColor is the style of cells with the symbol.
+type replayPageColor struct {
+	Class int
+	Color template.CSS
+}
+
+// renderReplayHTML writes HTML page with a slider over moves of the game.
+func renderReplayHTML(w io.Writer, r HistoryReplayResponse) error {
+	frames := make([]replayPageFrame, len(r.Frames))
+	for i, f := range r.Frames {
+		frames[i] = replayPageFrame{Board: f.Board, Move: "start", X: -1, Y: -1}
+		if f.Move == nil {
+			continue
+		}
+		frames[i].Move = f.Move.At.Format("15:04:05") + " " + f.Move.Kind + " " + f.Move.Arg
+		if f.Move.Player != "" {
+			frames[i].Move += " by " + f.Move.Player
+		}
+		if c, ok := f.Move.target(); ok {
+			frames[i].X, frames[i].Y = int(c.X), int(c.Y)
+		}
+	}
+	colors := make([]replayPageColor, 0, len(replayColors))
+	for _, symbol := range replaySymbols() {
+		c := replayColors[symbol]
+		colors = append(colors, replayPageColor{
+			Class: int(symbol),
+			Color: template.CSS(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)),
+		})
+	}
+
+	return replayPage.Execute(w, struct {
+		Game   HistoryGame
+		Frames []replayPageFrame
+		Colors []replayPageColor
+		Hex    bool
+		Last   int
+		Delay  int
+	}{
+		Game:   r.HistoryGame,
+		Frames: frames,
+		Colors: colors,
+		Hex:    r.Grid == GridHex,
+		Last:   len(frames) - 1,
+		Delay:  replayDelay * 10,
+	})
+}
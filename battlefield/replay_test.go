@@ -0,0 +1,122 @@
+package battlefield
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/storage"
+)
+
+func TestService_HistoryReplay(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	s.SetStore(storage.NewMemory())
+	opts := fieldOptions{size: 3, terrain: Terrain{Islands: []string{"C1"}}}
+	assert.NoError(t, s.createField(opts, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 B1", alice))
+	_, err := s.shot("C3", bob)
+	assert.NoError(t, err)
+	_, err = s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+	_, _, err = s.historyReplay(s.f.id, alice)
+	assert.Equal(t, errorGameNotFound, err)
+	_, err = s.shot("B1", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+	_, _, err = s.historyReplay(s.f.id, caller{player: "carol"})
+	assert.Equal(t, errorNotGamePlayer, err)
+
+	g, frames, err := s.historyReplay(s.f.id, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.id, g.ID)
+	want := [][]string{
+		{"..^", "...", "..."},
+		{"##^", "...", "..."},
+		{"##^", "...", "..o"},
+		{"x#^", "...", "..o"},
+		{"XX^", "...", "..o"},
+	}
+	if assert.Len(t, frames, len(want)) {
+		assert.Nil(t, frames[0].move)
+		for i, f := range frames {
+			assert.Equal(t, want[i], f.rows, i)
+			if i > 0 {
+				assert.Equal(t, g.Log[i-1], *f.move)
+			}
+		}
+	}
+	assert.Equal(t, g.Board, frames[len(frames)-1].rows)
+}
+
+// newReplayResponse returns the replay of the shot at B1 which sinks the ship.
+func newReplayResponse(grid string) HistoryReplayResponse {
+	return HistoryReplayResponse{
+		HistoryGame: HistoryGame{ID: "0123456789abcdef", Owner: "alice", Attacker: "bob", Size: 2, Rules: RulesFree, Outcome: OutcomeWon},
+		Grid:        grid,
+		Frames: []ReplayFrame{
+			{Board: []string{"#.", ".."}},
+			{Move: &HistoryMove{Kind: eventShot, Player: "bob", Arg: "B1", At: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}, Board: []string{"#X", ".."}},
+			{Move: &HistoryMove{Kind: eventRadar, Player: "bob", Arg: "1", At: time.Date(2020, 1, 2, 3, 4, 6, 0, time.UTC)}, Board: []string{"#X", ".."}},
+		},
+	}
+}
+
+func rgba(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+func TestRenderReplayGIF(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, renderReplayGIF(b, newReplayResponse(GridSquare)))
+
+	anim, err := gif.DecodeAll(b)
+	assert.NoError(t, err)
+	assert.Len(t, anim.Image, 3)
+	assert.Equal(t, []int{replayDelay, replayDelay, replayLastDelay}, anim.Delay)
+	size := 2*replayCellSize + 1
+	assert.Equal(t, size, anim.Image[0].Bounds().Dx())
+	assert.Equal(t, size, anim.Image[0].Bounds().Dy())
+
+	center := replayCellSize / 2
+	assert.Equal(t, replayColors[symbolShip], rgba(anim.Image[0].At(center, center)))
+	assert.Equal(t, replayColors[symbolWater], rgba(anim.Image[0].At(replayCellSize+center, center)))
+	assert.Equal(t, replayLineColor, rgba(anim.Image[0].At(replayCellSize, center)))
+	// the target of the shot is outlined
+	assert.Equal(t, replayColors[symbolSunk], rgba(anim.Image[1].At(replayCellSize+center, center)))
+	assert.Equal(t, replayTargetColor, rgba(anim.Image[1].At(replayCellSize+1, center)))
+	assert.Equal(t, replayColors[symbolSunk], rgba(anim.Image[2].At(replayCellSize+1, center)))
+
+	// rows of hex grid are shifted
+	b.Reset()
+	assert.NoError(t, renderReplayGIF(b, newReplayResponse(GridHex)))
+	anim, err = gif.DecodeAll(b)
+	assert.NoError(t, err)
+	assert.Equal(t, size+replayCellSize/2, anim.Image[0].Bounds().Dx())
+	assert.Equal(t, replayLineColor, rgba(anim.Image[0].At(center, replayCellSize+center)))
+}
+
+func TestRenderReplayHTML(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, renderReplayHTML(b, newReplayResponse(GridSquare)))
+	page := b.String()
+
+	assert.Contains(t, page, "<title>Game 0123456789abcdef</title>")
+	assert.Contains(t, page, "alice vs bob")
+	assert.Contains(t, page, `<input id="slider" type="range" min="0" max="2" value="0">`)
+	assert.Contains(t, page, `{"board":["#X",".."],"move":"03:04:05 shot B1 by bob","x":1,"y":0}`)
+	assert.Contains(t, page, `"move":"03:04:06 radar 1 by bob","x":-1,"y":-1`)
+	assert.Contains(t, page, ".c35 { background: #666677; }")
+	assert.Contains(t, page, "var hex =  false ;")
+	// the page is self-contained
+	for _, s := range []string{"src=", "href=", "@import", "url("} {
+		assert.False(t, strings.Contains(page, s), s)
+	}
+}
@@ -0,0 +1,131 @@
+package battlefield
+
+import (
+	"my/battleship/coordinates"
+)
+
+// Rule presets.
+const (
+	// RulesFree allows any number of rectangular ships on any field.
+	RulesFree = "free"
+	// RulesClassic is the classic game: 10x10 field and straight ships,
+	// one of 4 cells, two of 3, three of 2 and four of 1.
+	RulesClassic = "classic"
+	// RulesTetromino is 10x10 field and five ships of 4 cells
+	// shaped I4, O4, L4, T4 or Z4.
+	RulesTetromino = "tetromino"
+)
+
+// rules restricts the field and the fleet.
+type rules struct {
+	// size is required field size, any if zero.
+	size uint
+	// fleet is required number of ships by length, any if nil.
+	fleet map[int]int
+	// straight allows only ships one cell wide.
+	straight bool
+	// shapes are allowed ship shapes, any if nil.
+	// Shapes are named on the square grid only.
+	shapes map[string]bool
+}
+
+var presets = map[string]rules{
+	RulesFree: {},
+	RulesClassic: {
+		size:     10,
+		fleet:    map[int]int{4: 1, 3: 2, 2: 3, 1: 4},
+		straight: true,
+	},
+	RulesTetromino: {
+		size:  10,
+		fleet: map[int]int{4: 5},
+		shapes: map[string]bool{
+			ShapeI4: true,
+			ShapeO4: true,
+			ShapeL4: true,
+			ShapeT4: true,
+			ShapeZ4: true,
+		},
+	},
+}
+
+// fieldOptions describes the field to create.
+type fieldOptions struct {
+	size uint
+	// rules is the name of the preset, RulesFree if empty.
+	rules string
+	// grid is the name of the grid, GridSquare if empty.
+	grid string
+	// wrap makes the field a torus, see coordinates.Torus.
+	wrap bool
+	// advanced gives the game weapons, see weaponStock.
+	advanced bool
+	// moving allows the owner to move ships, see Service.moveShip.
+	moving bool
+	// repairs is the number of cells the owner can repair on every ship,
+	// see Service.repairShip.
+	repairs int
+	// mines is the number of mines the owner hides, minePenalty
+	// is the penalty of hitting the mine, see minePenaltyByName.
+	mines       int
+	minePenalty string
+	// seed is the seed of the game randomness.
+	seed int64
+	// terrain is terrain of the field, mapName is the name of
+	// the terrain preset, only one of them can be set.
+	terrain Terrain
+	mapName string
+}
+
+// rulesByName returns the rules preset, RulesFree is used if name is empty.
+func rulesByName(name string) (string, rules, bool) {
+	if name == "" {
+		name = RulesFree
+	}
+	r, ok := presets[name]
+	return name, r, ok
+}
+
+// checkFleet checks if ships on the grid match the rules.
+func (r rules) checkFleet(ships []*ship, g coordinates.Grid) error {
+	if problems := r.problems(ships, g); len(problems) > 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// problems returns all mismatches of ships and the rules.
+// Nil ships are invalid ones, number of ships is not checked then.
+func (r rules) problems(ships []*ship, g coordinates.Grid) []HTTPError {
+	var problems []HTTPError
+	complete := true
+	fleet := make(map[int]int)
+	for i, sh := range ships {
+		if sh == nil {
+			complete = false
+			continue
+		}
+		// ships should be connected under any rules
+		if !g.Connected(sh.inner) {
+			problems = append(problems, errorShipNotConnected.withShip(i))
+		} else if r.shapes != nil && !r.shapes[shapeOf(g.Unwrap(sh.inner))] {
+			problems = append(problems, errorShapeNotAllowed.withShip(i))
+		}
+		if r.straight && !g.Line(sh.inner) {
+			problems = append(problems, errorShipNotStraight.withShip(i))
+		}
+		fleet[sh.inner.Len()]++
+	}
+	if r.fleet == nil || !complete {
+		return problems
+	}
+	if len(fleet) != len(r.fleet) {
+		return append(problems, errorFleetMismatch)
+	}
+	for length, n := range r.fleet {
+		if fleet[length] != n {
+			return append(problems, errorFleetMismatch)
+		}
+	}
+	return problems
+}
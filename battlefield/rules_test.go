@@ -0,0 +1,147 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestRules_CheckFleet(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		grid    string
+		wrap    bool
+		coords  string
+		wantErr error
+	}{
+		{
+			name:   "success, free rules",
+			rules:  RulesFree,
+			coords: "A1 B2,D1 D1",
+		},
+		{
+			name:   "success, classic rules",
+			rules:  RulesClassic,
+			coords: "A1 A4,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10",
+		},
+		{
+			name:   "success, free rules, any shape",
+			rules:  RulesFree,
+			coords: "A1+A2+B2+B3+C3,E1 E1",
+		},
+		{
+			name:   "success, tetromino rules",
+			rules:  RulesTetromino,
+			coords: "A1 A4,C1 D2,F1+F2+F3+G3,I1+J1+I2+I3,A6+B6+B7+C7",
+		},
+		{
+			name:    "error, ship is not connected",
+			rules:   RulesFree,
+			coords:  "A1 A1,C1+C2+D3",
+			wantErr: errorShipNotConnected.withShip(1),
+		},
+		{
+			name:    "error, shape is not allowed",
+			rules:   RulesTetromino,
+			coords:  "A1 A4,C1 D2,F1+F2+F3+G3,I1+J1+I2+I3,A6 C6",
+			wantErr: errorShapeNotAllowed.withShip(4),
+		},
+		{
+			name:    "error, ship is not straight",
+			rules:   RulesClassic,
+			coords:  "A1 A4,C1 D2",
+			wantErr: errorShipNotStraight.withShip(1),
+		},
+		{
+			name:    "error, missing ship",
+			rules:   RulesClassic,
+			coords:  "A1 A4,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8",
+			wantErr: errorFleetMismatch,
+		},
+		{
+			name:    "error, extra ship",
+			rules:   RulesClassic,
+			coords:  "A1 A4,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10,H10 H10",
+			wantErr: errorFleetMismatch,
+		},
+		{
+			name:    "error, ship of other length",
+			rules:   RulesClassic,
+			coords:  "A1 A5,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10",
+			wantErr: errorFleetMismatch,
+		},
+		{
+			name:   "success, hex grid, diagonal line",
+			rules:  RulesClassic,
+			grid:   GridHex,
+			coords: "D1 A4,C6 C8,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10",
+		},
+		{
+			name:   "success, hex grid, connected by hex side",
+			rules:  RulesFree,
+			grid:   GridHex,
+			coords: "A2+B1",
+		},
+		{
+			name:    "error, hex grid, rhombus is not straight",
+			rules:   RulesClassic,
+			grid:    GridHex,
+			coords:  "A1 A4,C1 C3,E1 E3,G1 H2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10",
+			wantErr: errorShipNotStraight.withShip(3),
+		},
+		{
+			name:    "error, hex grid, cells touch by corner",
+			rules:   RulesFree,
+			grid:    GridHex,
+			coords:  "A1+B2",
+			wantErr: errorShipNotConnected.withShip(0),
+		},
+		{
+			name:   "success, wrap, straight ship crosses the edge",
+			rules:  RulesClassic,
+			wrap:   true,
+			coords: "J5 C5,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10",
+		},
+		{
+			name:   "success, wrap, shapes cross corners",
+			rules:  RulesTetromino,
+			wrap:   true,
+			coords: "J10+A10+A1+A2,J1+J2+I2+H2,C4 F4,C6 D7,F6+G6+H6+G7",
+		},
+		{
+			name:    "error, wrap, cells don't touch",
+			rules:   RulesFree,
+			wrap:    true,
+			coords:  "J1+B1",
+			wantErr: errorShipNotConnected.withShip(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, g, ok := gridByName(tt.grid)
+			assert.True(t, ok)
+			if tt.wrap {
+				g = coordinates.Torus(g, 10)
+			}
+			ships, err := makeShipsFromCoords(tt.coords, g)
+			assert.NoError(t, err)
+
+			_, r, ok := rulesByName(tt.rules)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantErr, r.checkFleet(ships, g))
+		})
+	}
+}
+
+func TestRulesByName(t *testing.T) {
+	name, _, ok := rulesByName("")
+	assert.Equal(t, RulesFree, name)
+	assert.True(t, ok)
+
+	_, _, ok = rulesByName("chess")
+	assert.False(t, ok)
+}
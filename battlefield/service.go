@@ -1,137 +1,390 @@
 package battlefield
 
 import (
+	"strings"
 	"sync"
+	"time"
 
 	"my/battleship/coordinates"
 
 	"github.com/sirupsen/logrus"
+
+	"my/battleship/storage"
 )
 
 // Service describes the battlefield service operations.
 type Service struct {
 	f Field
 
-	logger *logrus.Logger
+	// maxSize limits size of created fields, maxFieldSize is used if zero.
+	maxSize uint
+
+	// total is statistics of finished games.
+	total aggregate
+
+	// store persists the game, dirty reports unsaved changes.
+	store storage.Store
+	dirty bool
+	// archive is the index of archived games guarded by archiveMu,
+	// nil until it's loaded, see archiveIndex.
+	archive   map[string]archivedGame
+	archiveMu sync.Mutex
+
+	logger   *logrus.Logger
+	observer Observer
 	sync.RWMutex
 }
 
+// caller describes who performs the Service operation.
+// ifMatch is If-Match header of the request, the operation is
+// rejected if the game version doesn't match it.
+type caller struct {
+	player  string
+	admin   bool
+	ifMatch string
+}
+
 // NewService creates new Service.
 func NewService(l *logrus.Logger) *Service {
 	l.Infof("MAXIMUM FIELD SIZE POSSIBLE IS %d", maxFieldSize)
 	return &Service{logger: l}
 }
 
-func (s *Service) createField(size uint) error {
+// SetMaxFieldSize limits size of created fields.
+// Size should not exceed the size coordinates can address.
+func (s *Service) SetMaxFieldSize(size uint) error {
+	if size < 1 || size > maxFieldSize {
+		return errorInvalidFieldSize
+	}
+	s.logger.Infof("MAXIMUM FIELD SIZE IS SET TO %d", size)
+	s.maxSize = size
+	return nil
+}
+
+func (s *Service) fieldSizeLimit() uint {
+	if s.maxSize == 0 {
+		return maxFieldSize
+	}
+	return s.maxSize
+}
+
+// SetObserver sets receiver of the Service events.
+func (s *Service) SetObserver(o Observer) {
+	s.observer = o
+}
+
+func (s *Service) notify() Observer {
+	if s.observer == nil {
+		return nopObserver{}
+	}
+	return s.observer
+}
+
+// lock acquires write lock and reports time spent waiting for it.
+func (s *Service) lock() {
+	start := time.Now()
 	s.Lock()
+	s.notify().LockWaited(true, time.Since(start))
+}
+
+// rLock acquires read lock and reports time spent waiting for it.
+func (s *Service) rLock() {
+	start := time.Now()
+	s.RLock()
+	s.notify().LockWaited(false, time.Since(start))
+}
+
+// changed marks the game as changed to persist it and bumps its version.
+func (s *Service) changed() {
+	s.f.version++
+	s.dirty = true
+}
+
+func (s *Service) createField(opts fieldOptions, cl caller) error {
+	s.lock()
 	defer s.Unlock()
 
-	if s.f.isSet && !s.f.gameIsOver {
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+
+	if s.f.isSet && !s.f.gameIsOver && !s.f.paused {
 		return errorFieldAlreadySet
 	}
 
-	s.logger.WithField("size", size).Debug("Service: createField started")
+	s.logger.WithField("size", opts.size).Debug("Service: createField started")
 
-	if size < 1 || size > maxFieldSize {
-		s.logger.WithField("size", size).
+	if opts.size < 1 || opts.size > s.fieldSizeLimit() {
+		s.logger.WithField("size", opts.size).
 			Error("Field size provided is invalid")
 		return errorInvalidFieldSize
 	}
-	s.f = NewField(size)
+	name, r, ok := rulesByName(opts.rules)
+	if !ok {
+		return errorUnknownRules
+	}
+	if r.size != 0 && opts.size != r.size {
+		return errorInvalidFieldSize
+	}
+	gridName, _, ok := gridByName(opts.grid)
+	if !ok {
+		return errorUnknownGrid
+	}
+	if r.shapes != nil && gridName != GridSquare {
+		return errorGridNotSupported
+	}
+	penalty, ok := minePenaltyByName(opts.minePenalty)
+	if !ok || opts.mines < 0 || uint(opts.mines) >= opts.size*opts.size || opts.repairs < 0 {
+		return errorInvalidInputParams
+	}
+	if penalty == MinePenaltyRetaliate && opts.mines > 0 && !opts.moving && opts.repairs == 0 {
+		return errorRetaliateNotSupported
+	}
+	t := opts.terrain
+	if opts.mapName != "" {
+		if !t.isEmpty() {
+			return errorInvalidTerrain
+		}
+		var err error
+		if t, err = terrainByName(opts.mapName, opts.size); err != nil {
+			return err
+		}
+	}
+	f := NewField(opts.size)
+	f.grid = gridName
+	f.wrap = opts.wrap
+	f.weapons = newInventory(opts.advanced)
+	f.moving = opts.moving
+	f.repairs = opts.repairs
+	if opts.mines > 0 {
+		f.mines = opts.mines
+		f.minePenalty = penalty
+	}
+	if err := f.setTerrain(t); err != nil {
+		return err
+	}
+	s.f = f
+	s.f.id = newGameID()
+	s.f.rules = name
+	s.f.seed = opts.seed
+	s.f.owner = cl.player
+	s.f.created = time.Now().UTC()
+	s.changed()
+	s.notify().GameCreated()
 	return nil
 }
 
-func (s *Service) clearField() error {
-	s.Lock()
+func (s *Service) clearField(cl caller) error {
+	s.lock()
 	defer s.Unlock()
 
 	s.logger.Debug("Service: clearField started")
 
+	if !cl.admin {
+		return errorAdminRequired
+	}
+
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+
+	if s.f.isSet && !s.f.gameIsOver && !s.f.paused {
+		s.notify().GameDiscarded()
+	}
 	s.f = Field{}
+	s.dirty = true
 	return nil
 }
 
-func (s *Service) addShipsByCoordinates(coords string) error {
-	s.Lock()
+func (s *Service) addShipsByCoordinates(coords string, cl caller) error {
+	s.lock()
 	defer s.Unlock()
 
 	s.logger.WithField("coords", coords).
 		Debug("Service: addShipsByCoordinates started")
 
+	if err := s.placeFleet(coords, cl); err != nil {
+		return err
+	}
+	s.f.record(eventShips, cl, coords)
+	s.changed()
+	return nil
+}
+
+// canPlace checks if the caller can place ships, the lock should be held.
+func (s *Service) canPlace(cl caller) error {
+	if !s.f.isOwnedBy(cl) {
+		return errorNotBoardOwner
+	}
+
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+
+	if s.f.paused {
+		return errorGamePaused
+	}
+
 	if s.f.shipsAdded {
 		return errorShipsAlreadyAdded
 	}
+	return nil
+}
+
+// placeFleet places ships on the field, the lock should be held.
+// The caller records the move.
+func (s *Service) placeFleet(coords string, cl caller) error {
+	if err := s.canPlace(cl); err != nil {
+		return err
+	}
 
-	ships, err := makeShipsFromCoords(coords)
+	g := s.f.geometry()
+	ships, err := makeShipsFromCoords(coords, g)
 	if err != nil {
 		s.logger.WithField("coords", coords).
 			Error("addShipsByCoordinates: invalid coordinates provided")
 		return err
 	}
+	_, r, _ := rulesByName(s.f.rules)
+	if err := r.checkFleet(ships, g); err != nil {
+		s.logger.WithField("coords", coords).
+			Error("addShipsByCoordinates: ships don't match the rules")
+		return err
+	}
 	err = s.addShips(ships)
 	if err != nil {
 		s.logger.WithField("coords", coords).
 			Error("addShipsByCoordinates: can't add ships")
 		return err
 	}
+	s.f.ships = ships
 	s.f.shipsAdded = true
 	s.f.shipsAlive = len(ships)
 	s.f.state.shipCount = len(ships)
 	return nil
 }
 
+// addShips places ships on the copy of the field, so the field
+// is untouched if any ship can't be placed.
 func (s *Service) addShips(ships []*ship) error {
-	for _, ship := range ships {
-		err := s.placeShip(ship)
-		if err != nil {
-			return err
+	field := s.f.copyField()
+	for i, sh := range ships {
+		if problems := placeShip(field, s.f.size, sh); len(problems) > 0 {
+			return problems[0].withShip(i)
 		}
 	}
+	s.f.field = field
 	return nil
 }
 
-func (s *Service) placeShip(sh *ship) error {
-	// occupy ship cells
-	for c := range sh.inner {
-		if c.X >= s.f.size || c.Y >= s.f.size {
-			return errorOutOfBonds
+// placeShip places the ship on the field of provided size if all ship
+// cells are free, otherwise returns problems of all ship cells.
+func placeShip(field [][]cell, size uint, sh *ship) []HTTPError {
+	var problems []HTTPError
+	cells := sh.inner.Sorted()
+	for _, c := range cells {
+		if c.X >= size || c.Y >= size {
+			problems = append(problems, errorOutOfBonds.withCoord(c))
+			continue
+		}
+		cell := field[c.X][c.Y]
+		if cell.terrain != terrainWater {
+			problems = append(problems, errorShipOnTerrain.withCoord(c))
+			continue
+		}
+		if cell.mine {
+			problems = append(problems, errorCellHasMine.withCoord(c))
+			continue
 		}
-		cell := s.f.field[c.X][c.Y]
 		if cell.occupied {
 			if cell.ship != nil {
-				return errorCellIsOccupiedByShip
+				problems = append(problems, errorCellIsOccupiedByShip.withCoord(c))
+			} else {
+				problems = append(problems, errorCellIsOccupiedNearby.withCoord(c))
 			}
-			return errorCellIsOccupiedNearby
 		}
-		cell.occupied = true
-		cell.ship = sh
-		s.f.field[c.X][c.Y] = cell
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	occupy(field, size, sh)
+	return nil
+}
+
+// occupy marks cells of the ship and nearby cells as occupied,
+// shots and terrain of cells are kept.
+func occupy(field [][]cell, size uint, sh *ship) {
+	// occupy ship cells
+	for _, c := range sh.inner.Sorted() {
+		field[c.X][c.Y].occupied = true
+		field[c.X][c.Y].ship = sh
 	}
 	// occupy nearby cells, skip if out of bonds
-	for c := range sh.outer {
-		if c.X >= s.f.size || c.Y >= s.f.size {
+	for _, c := range sh.outer.Sorted() {
+		if c.X >= size || c.Y >= size {
 			continue
 		}
-		cell := s.f.field[c.X][c.Y]
-		if cell.occupied {
+		field[c.X][c.Y].occupied = true
+	}
+}
+
+func (s *Service) validateShips(coords string, cl caller) ([]HTTPError, error) {
+	s.rLock()
+	defer s.RUnlock()
+
+	s.logger.WithField("coords", coords).
+		Debug("Service: validateShips started")
+
+	if !s.f.isOwnedBy(cl) {
+		return nil, errorNotBoardOwner
+	}
+
+	if s.f.shipsAdded {
+		return []HTTPError{errorShipsAlreadyAdded}, nil
+	}
+
+	if len(coords) == 0 {
+		return []HTTPError{errorInvalidCoordinate}, nil
+	}
+
+	var problems []HTTPError
+	g := s.f.geometry()
+	parts := strings.Split(coords, ",")
+	ships := make([]*ship, len(parts))
+	for i, sc := range parts {
+		sh, ok := parseShip(sc, g)
+		if !ok {
+			problems = append(problems, errorInvalidCoordinate.withShip(i))
 			continue
 		}
-		cell.occupied = true
-		s.f.field[c.X][c.Y] = cell
+		ships[i] = sh
 	}
 
-	return nil
+	_, r, _ := rulesByName(s.f.rules)
+	problems = append(problems, r.problems(ships, g)...)
+
+	field := s.f.copyField()
+	for i, sh := range ships {
+		if sh == nil {
+			continue
+		}
+		for _, p := range placeShip(field, s.f.size, sh) {
+			problems = append(problems, p.withShip(i))
+		}
+	}
+	return problems, nil
 }
 
-func (s *Service) shot(coordinate string) (shotResult, error) {
-	s.Lock()
+func (s *Service) shot(coordinate string, cl caller) (shotResult, error) {
+	s.lock()
 	defer s.Unlock()
 
 	s.logger.WithField("coordinate", coordinate).
 		Debug("Service: shot started")
 
-	if !s.f.shipsAdded {
-		return shotResult{}, errorShipsNotPlaced
+	if err := s.canShoot(cl); err != nil {
+		return shotResult{}, err
 	}
 
 	c, ok := coordinates.ConvertCoordinate(coordinate)
@@ -142,16 +395,81 @@ func (s *Service) shot(coordinate string) (shotResult, error) {
 	}
 
 	if c.X >= s.f.size || c.Y >= s.f.size {
-		return shotResult{}, errorOutOfBonds
+		return shotResult{}, errorOutOfBonds.withCoord(c)
 	}
 
 	cell := s.f.field[c.X][c.Y]
+	if cell.terrain == terrainIsland {
+		return shotResult{}, errorCellNotShootable.withCoord(c)
+	}
 	if cell.shot {
-		return shotResult{}, errorCellAlreadyShot
+		return shotResult{}, errorCellAlreadyShot.withCoord(c)
+	}
+	if s.loseTurn(coordinate, cl) {
+		return shotResult{Skipped: true}, nil
 	}
+
+	res := s.fire(c, cl)
+	s.f.defenderTurn = s.f.defends()
+	if res.End {
+		s.total.add(s.f)
+	}
+	s.f.record(eventShot, cl, coordinate)
+	s.changed()
+
+	s.notify().ShotFired(res.Knock, res.Destroy)
+	if res.End {
+		s.notify().GameFinished()
+	}
+	return res, nil
+}
+
+// canShoot checks if the caller can shoot at the field, the lock should be held.
+func (s *Service) canShoot(cl caller) error {
+	if !s.f.shipsAdded {
+		return errorShipsNotPlaced
+	}
+
+	if s.f.gameIsOver {
+		return errorGameIsOver
+	}
+
+	if !s.f.hasTurn(cl) {
+		return errorNotYourTurn
+	}
+
+	if !s.f.matches(cl) {
+		return errorVersionMismatch
+	}
+
+	if s.f.paused {
+		return errorGamePaused
+	}
+
+	if s.f.mines > 0 && !s.f.minesAdded {
+		return errorMinesNotPlaced
+	}
+	return nil
+}
+
+// fire shoots at the cell which is not shot yet and isn't an island,
+// and updates the state of the game. The lock should be held.
+// The caller records the move.
+func (s *Service) fire(c coordinates.Coordinate, cl caller) shotResult {
+	cell := s.f.field[c.X][c.Y]
 	cell.shot = true
+	cell.repaired = false
 
 	res := shotResult{}
+	if cell.terrain == terrainReef {
+		res.Terrain = TerrainReef
+	}
+
+	if cell.mine {
+		res.Mine = true
+		res.Penalty = s.f.minePenalty
+		s.hitMine()
+	}
 
 	if cell.ship != nil {
 		res.Knock = true
@@ -179,16 +497,42 @@ func (s *Service) shot(coordinate string) (shotResult, error) {
 	}
 	s.f.field[c.X][c.Y] = cell
 
+	// the first shooter takes the attacker seat
+	if s.f.attacker == "" {
+		s.f.attacker = cl.player
+	}
+
 	// update global state
 	s.f.state.shotCount++
-	return res, nil
+	s.f.stats.shot(res.Knock, res.Destroy)
+	return res
 }
 
 func (s *Service) state() state {
-	s.RLock()
+	s.rLock()
 	defer s.RUnlock()
 
 	s.logger.Debug("Service: state started")
 
-	return s.f.state
+	st := s.f.state
+	st.game = s.f.id
+	st.version = s.f.version
+	st.seed = s.f.seed
+	st.paused = s.f.paused
+	st.clock = s.f.clock(time.Now())
+	if s.f.weapons != nil {
+		st.weapons = make(map[string]int, len(s.f.weapons))
+		for w, n := range s.f.weapons {
+			st.weapons[w] = n
+		}
+	}
+	return st
+}
+
+// GameID returns identifier of the current game, empty if the field is not set.
+func (s *Service) GameID() string {
+	s.rLock()
+	defer s.RUnlock()
+
+	return s.f.id
 }
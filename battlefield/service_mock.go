@@ -20,31 +20,149 @@ func NewTestifyServiceMock(t *testing.T) *TestifyServiceMock {
 }
 
 // createField is mock implementation.
-func (r *TestifyServiceMock) createField(size uint) error {
-	results := r.Called(size)
+func (r *TestifyServiceMock) createField(opts fieldOptions, cl caller) error {
+	results := r.Called(opts, cl)
 	return results.Error(0)
 }
 
 // clearField is mock implementation.
-func (r *TestifyServiceMock) clearField() error {
-	results := r.Called()
+func (r *TestifyServiceMock) clearField(cl caller) error {
+	results := r.Called(cl)
 	return results.Error(0)
 }
 
 // addShipsByCoordinates is mock implementation.
-func (r *TestifyServiceMock) addShipsByCoordinates(coords string) error {
-	results := r.Called(coords)
+func (r *TestifyServiceMock) addShipsByCoordinates(coords string, cl caller) error {
+	results := r.Called(coords, cl)
 	return results.Error(0)
 }
 
-// addShipsByCoordinates is mock implementation.
-func (r *TestifyServiceMock) shot(coords string) (shotResult, error) {
-	results := r.Called(coords)
+// autoPlaceShips is mock implementation.
+func (r *TestifyServiceMock) autoPlaceShips(lengths []int, cl caller) error {
+	results := r.Called(lengths, cl)
+	return results.Error(0)
+}
+
+// validateShips is mock implementation.
+func (r *TestifyServiceMock) validateShips(coords string, cl caller) ([]HTTPError, error) {
+	results := r.Called(coords, cl)
+	problems, _ := results.Get(0).([]HTTPError)
+	return problems, results.Error(1)
+}
+
+// exportShips is mock implementation.
+func (r *TestifyServiceMock) exportShips(cl caller) (Layout, error) {
+	results := r.Called(cl)
+	return results.Get(0).(Layout), results.Error(1)
+}
+
+// importShips is mock implementation.
+func (r *TestifyServiceMock) importShips(l Layout, cl caller) error {
+	results := r.Called(l, cl)
+	return results.Error(0)
+}
+
+// moveShip is mock implementation.
+func (r *TestifyServiceMock) moveShip(id int, direction string, cl caller) (string, error) {
+	results := r.Called(id, direction, cl)
+	return results.String(0), results.Error(1)
+}
+
+// repairShip is mock implementation.
+func (r *TestifyServiceMock) repairShip(id int, coordinate string, cl caller) (int, error) {
+	results := r.Called(id, coordinate, cl)
+	return results.Int(0), results.Error(1)
+}
+
+// pauseGame is mock implementation.
+func (r *TestifyServiceMock) pauseGame(id string, cl caller) error {
+	results := r.Called(id, cl)
+	return results.Error(0)
+}
+
+// resumeGame is mock implementation.
+func (r *TestifyServiceMock) resumeGame(id string, cl caller) error {
+	results := r.Called(id, cl)
+	return results.Error(0)
+}
+
+// pausedGames is mock implementation.
+func (r *TestifyServiceMock) pausedGames(cl caller) ([]pausedGame, error) {
+	results := r.Called(cl)
+	games, _ := results.Get(0).([]pausedGame)
+	return games, results.Error(1)
+}
+
+// history is mock implementation.
+func (r *TestifyServiceMock) history(q historyQuery, cl caller) ([]archivedGame, int, error) {
+	results := r.Called(q, cl)
+	games, _ := results.Get(0).([]archivedGame)
+	return games, results.Int(1), results.Error(2)
+}
+
+// historyRecord is mock implementation.
+func (r *TestifyServiceMock) historyRecord(id string, cl caller) (archivedGame, error) {
+	results := r.Called(id, cl)
+	return results.Get(0).(archivedGame), results.Error(1)
+}
+
+// historyReplay is mock implementation.
+func (r *TestifyServiceMock) historyReplay(id string, cl caller) (archivedGame, []replayFrame, error) {
+	results := r.Called(id, cl)
+	frames, _ := results.Get(1).([]replayFrame)
+	return results.Get(0).(archivedGame), frames, results.Error(2)
+}
+
+// placeMines is mock implementation.
+func (r *TestifyServiceMock) placeMines(coords string, cl caller) error {
+	results := r.Called(coords, cl)
+	return results.Error(0)
+}
+
+// shot is mock implementation.
+func (r *TestifyServiceMock) shot(coords string, cl caller) (shotResult, error) {
+	results := r.Called(coords, cl)
 	return results.Get(0).(shotResult), results.Error(1)
 }
 
+// sonar is mock implementation.
+func (r *TestifyServiceMock) sonar(coords string, cl caller) (bool, error) {
+	results := r.Called(coords, cl)
+	return results.Bool(0), results.Error(1)
+}
+
+// radar is mock implementation.
+func (r *TestifyServiceMock) radar(line string, cl caller) (int, error) {
+	results := r.Called(line, cl)
+	return results.Int(0), results.Error(1)
+}
+
+// bomb is mock implementation.
+func (r *TestifyServiceMock) bomb(coords string, cl caller) ([]cellShot, error) {
+	results := r.Called(coords, cl)
+	return results.Get(0).([]cellShot), results.Error(1)
+}
+
 // state is mock implementation.
 func (r *TestifyServiceMock) state() state {
 	results := r.Called()
 	return results.Get(0).(state)
 }
+
+// stats is mock implementation.
+func (r *TestifyServiceMock) stats() statsReport {
+	results := r.Called()
+	return results.Get(0).(statsReport)
+}
+
+// heatmap is mock implementation.
+func (r *TestifyServiceMock) heatmap(size uint) (heatmap, error) {
+	results := r.Called(size)
+	return results.Get(0).(heatmap), results.Error(1)
+}
+
+// view is mock implementation.
+func (r *TestifyServiceMock) view(cl caller, fog bool) fieldView {
+	results := r.Called(cl, fog)
+	return results.Get(0).(fieldView)
+}
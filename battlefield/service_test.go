@@ -1,7 +1,9 @@
 package battlefield
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,17 @@ import (
 	"my/battleship/coordinates"
 )
 
+// assertError checks that err has the same error code as want,
+// details of HTTPError are not compared.
+func assertError(t *testing.T, want, err error) {
+	t.Helper()
+	if want == nil {
+		assert.NoError(t, err)
+		return
+	}
+	assert.True(t, errors.Is(err, want), "want %v, got %v", want, err)
+}
+
 func TestNewService(t *testing.T) {
 	log := logrus.New()
 	want := &Service{logger: log}
@@ -56,7 +69,7 @@ func TestCreateField(t *testing.T) {
 		s := Service{logger: logrus.New(), f: tt.args.field}
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := s.createField(tt.args.size)
+			err := s.createField(fieldOptions{size: tt.args.size}, caller{})
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
@@ -95,7 +108,7 @@ func TestClearField(t *testing.T) {
 		s := Service{logger: logrus.New(), f: tt.args.field}
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := s.clearField()
+			err := s.clearField(caller{admin: true})
 			assert.Equal(t, tt.want, s.f)
 			assert.Equal(t, tt.wantErr, err)
 		})
@@ -120,7 +133,7 @@ func TestPlaceShip(t *testing.T) {
 					size:  2,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
 				},
 			},
 			wantErr: nil,
@@ -133,7 +146,7 @@ func TestPlaceShip(t *testing.T) {
 					size:  2,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
 				},
 			},
 			wantErr: nil,
@@ -146,7 +159,7 @@ func TestPlaceShip(t *testing.T) {
 					size:  2,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
 				},
 			},
 			wantErr: nil,
@@ -159,7 +172,7 @@ func TestPlaceShip(t *testing.T) {
 					size:  1,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}, {X: 1, Y: 0}: {}, {X: 0, Y: 1}: {}, {X: 1, Y: 1}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}...),
 				},
 			},
 			wantErr: errorOutOfBonds,
@@ -175,7 +188,7 @@ func TestPlaceShip(t *testing.T) {
 					size: 1,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
 				},
 			},
 			wantErr: errorCellIsOccupiedByShip,
@@ -190,7 +203,7 @@ func TestPlaceShip(t *testing.T) {
 					size: 1,
 				},
 				ship: &ship{
-					inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
 				},
 			},
 			wantErr: errorCellIsOccupiedNearby,
@@ -198,11 +211,14 @@ func TestPlaceShip(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		s := Service{logger: logrus.New(), f: tt.args.field}
-
 		t.Run(tt.name, func(t *testing.T) {
-			err := s.placeShip(tt.args.ship)
-			assert.Equal(t, tt.wantErr, err)
+			problems := placeShip(tt.args.field.field, tt.args.field.size, tt.args.ship)
+			if tt.wantErr == nil {
+				assert.Empty(t, problems)
+				return
+			}
+			assert.NotEmpty(t, problems)
+			assertError(t, tt.wantErr, problems[0])
 		})
 	}
 }
@@ -226,12 +242,12 @@ func TestAddShips(t *testing.T) {
 				},
 				ships: []*ship{
 					{
-						inner: coordinates.Coordinates{
-							{X: 0, Y: 0}: {},
-							{X: 1, Y: 1}: {},
-							{X: 1, Y: 0}: {},
-							{X: 0, Y: 1}: {},
-						},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{
+							{X: 0, Y: 0},
+							{X: 1, Y: 1},
+							{X: 1, Y: 0},
+							{X: 0, Y: 1},
+						}...),
 					},
 				},
 			},
@@ -246,10 +262,10 @@ func TestAddShips(t *testing.T) {
 				},
 				ships: []*ship{
 					{
-						inner: coordinates.Coordinates{{X: 0, Y: 0}: {}, {X: 1, Y: 0}: {}, {X: 2, Y: 0}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}...),
 					},
 					{
-						inner: coordinates.Coordinates{{X: 2, Y: 2}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 2, Y: 2}}...),
 					},
 				},
 			},
@@ -264,10 +280,10 @@ func TestAddShips(t *testing.T) {
 				},
 				ships: []*ship{
 					{
-						inner: coordinates.Coordinates{{X: 0, Y: 0}: {}, {X: 1, Y: 0}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}, {X: 1, Y: 0}}...),
 					},
 					{
-						inner: coordinates.Coordinates{{X: 0, Y: 0}: {}, {X: 0, Y: 1}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 1}}...),
 					},
 				},
 			},
@@ -282,15 +298,15 @@ func TestAddShips(t *testing.T) {
 				},
 				ships: []*ship{
 					{
-						inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
-						outer: coordinates.Coordinates{
-							{X: 1, Y: 0}: {},
-							{X: 0, Y: 1}: {},
-							{X: 1, Y: 1}: {},
-						},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
+						outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+							{X: 1, Y: 0},
+							{X: 0, Y: 1},
+							{X: 1, Y: 1},
+						}...),
 					},
 					{
-						inner: coordinates.Coordinates{{X: 1, Y: 1}: {}, {X: 1, Y: 1}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 1, Y: 1}, {X: 1, Y: 1}}...),
 					},
 				},
 			},
@@ -304,7 +320,7 @@ func TestAddShips(t *testing.T) {
 				},
 				ships: []*ship{
 					{
-						inner: coordinates.Coordinates{{X: 2, Y: 2}: {}, {X: 2, Y: 2}: {}},
+						inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 2, Y: 2}, {X: 2, Y: 2}}...),
 					},
 				},
 			},
@@ -317,7 +333,7 @@ func TestAddShips(t *testing.T) {
 
 		t.Run(tt.name, func(t *testing.T) {
 			err := s.addShips(tt.args.ships)
-			assert.Equal(t, tt.wantErr, err)
+			assertError(t, tt.wantErr, err)
 		})
 	}
 }
@@ -410,8 +426,8 @@ func TestAddShipsByCoordinates(t *testing.T) {
 		s := Service{logger: logrus.New(), f: tt.args.field}
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := s.addShipsByCoordinates(tt.args.coords)
-			assert.Equal(t, tt.wantErr, err)
+			err := s.addShipsByCoordinates(tt.args.coords, caller{})
+			assertError(t, tt.wantErr, err)
 		})
 	}
 }
@@ -510,7 +526,7 @@ func TestShot(t *testing.T) {
 				coordinate: "A1",
 			},
 			want:    shotResult{},
-			wantErr: errorCellAlreadyShot,
+			wantErr: errorCellAlreadyShot.withCoord(coordinates.Coordinate{X: 0, Y: 0}),
 		},
 		{
 			name: "error, shot out of bonds",
@@ -523,7 +539,7 @@ func TestShot(t *testing.T) {
 				coordinate: "C5",
 			},
 			want:    shotResult{},
-			wantErr: errorOutOfBonds,
+			wantErr: errorOutOfBonds.withCoord(coordinates.Coordinate{X: 2, Y: 4}),
 		},
 		{
 			name: "error, invalid coordinate",
@@ -557,7 +573,7 @@ func TestShot(t *testing.T) {
 		s := Service{logger: logrus.New(), f: tt.args.field}
 
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := s.shot(tt.args.coordinate)
+			got, err := s.shot(tt.args.coordinate, caller{})
 			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.wantErr, err)
 		})
@@ -586,3 +602,249 @@ func TestState(t *testing.T) {
 	got := s.state()
 	assert.Equal(t, want, got)
 }
+
+type recordingObserver struct {
+	created, finished, discarded int
+	shots, knocks, destroys      int
+	locks                        int
+}
+
+func (o *recordingObserver) GameCreated()   { o.created++ }
+func (o *recordingObserver) GameFinished()  { o.finished++ }
+func (o *recordingObserver) GameDiscarded() { o.discarded++ }
+func (o *recordingObserver) ShotFired(knock, destroy bool) {
+	o.shots++
+	if knock {
+		o.knocks++
+	}
+	if destroy {
+		o.destroys++
+	}
+}
+func (o *recordingObserver) LockWaited(bool, time.Duration) { o.locks++ }
+
+func TestService_Observer(t *testing.T) {
+	o := &recordingObserver{}
+	s := NewService(logrus.New())
+	s.SetObserver(o)
+
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A2", caller{}))
+	_, err := s.shot("C3", caller{})
+	assert.NoError(t, err)
+	_, err = s.shot("A1", caller{})
+	assert.NoError(t, err)
+	_, err = s.shot("A2", caller{})
+	assert.NoError(t, err)
+	_, err = s.shot("B2", caller{})
+	assert.Equal(t, errorGameIsOver, err)
+	_ = s.state()
+	assert.NoError(t, s.createField(fieldOptions{size: 2}, caller{}))
+	assert.NoError(t, s.clearField(caller{admin: true}))
+
+	want := &recordingObserver{
+		created:   2,
+		finished:  1,
+		discarded: 1,
+		shots:     3,
+		knocks:    2,
+		destroys:  1,
+		locks:     9,
+	}
+	assert.Equal(t, want, o)
+}
+
+func TestService_Ownership(t *testing.T) {
+	alice := caller{player: "alice"}
+	bob := caller{player: "bob"}
+	eve := caller{player: "eve"}
+	root := caller{player: "root", admin: true}
+
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+
+	assert.Equal(t, errorNotBoardOwner, s.addShipsByCoordinates("A1 A1", bob))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1,C3 C3", alice))
+
+	_, err := s.shot("B2", alice)
+	assert.Equal(t, errorNotYourTurn, err)
+	_, err = s.shot("B2", bob)
+	assert.NoError(t, err)
+	_, err = s.shot("A2", eve)
+	assert.Equal(t, errorNotYourTurn, err)
+
+	assert.Equal(t, errorAdminRequired, s.clearField(alice))
+	assert.NoError(t, s.clearField(root))
+}
+
+func TestService_SetMaxFieldSize(t *testing.T) {
+	s := NewService(logrus.New())
+
+	assert.Equal(t, errorInvalidFieldSize, s.SetMaxFieldSize(0))
+	assert.Equal(t, errorInvalidFieldSize, s.SetMaxFieldSize(maxFieldSize+1))
+	assert.NoError(t, s.SetMaxFieldSize(5))
+
+	assert.Equal(t, errorInvalidFieldSize, s.createField(fieldOptions{size: 6}, caller{}))
+	assert.NoError(t, s.createField(fieldOptions{size: 5}, caller{}))
+}
+
+func TestService_ErrorDetails(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+
+	ship := 1
+	assert.Equal(t, HTTPError{
+		ErrCode: CodeCellOccupiedByShip,
+		Err:     errorCellIsOccupiedByShip.Err,
+		Details: &ErrorDetails{Ship: &ship, Coord: "A1"},
+		Code:    400,
+	}, s.addShipsByCoordinates("A1 A1,A1 A1", caller{}))
+
+	assert.Equal(t, HTTPError{
+		ErrCode: CodeInvalidCoordinate,
+		Err:     errorInvalidCoordinate.Err,
+		Details: &ErrorDetails{Ship: &ship},
+		Code:    400,
+	}, s.addShipsByCoordinates("A1 A1,C3", caller{}))
+}
+
+func TestService_GameID(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.Equal(t, "", s.GameID())
+
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	first := s.GameID()
+	assert.Len(t, first, 16)
+	assert.Equal(t, first, s.state().game)
+
+	assert.NoError(t, s.clearField(caller{admin: true}))
+	assert.Equal(t, "", s.GameID())
+
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.NotEqual(t, first, s.GameID())
+}
+
+func TestService_Version(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.Equal(t, uint64(1), s.state().version)
+
+	stale := caller{ifMatch: etag(s.GameID(), 1)}
+	assert.NoError(t, s.addShipsByCoordinates("A1 A2", stale))
+	assert.Equal(t, uint64(2), s.state().version)
+
+	// failed operations don't change the version
+	_, err := s.shot("D4", caller{})
+	assertError(t, errorOutOfBonds, err)
+	assert.Equal(t, uint64(2), s.state().version)
+
+	_, err = s.shot("A1", stale)
+	assert.Equal(t, errorVersionMismatch, err)
+	assert.Equal(t, errorVersionMismatch, s.clearField(caller{admin: true, ifMatch: `"other-2"`}))
+
+	_, err = s.shot("A1", caller{ifMatch: etag(s.GameID(), 2)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), s.state().version)
+}
+
+func TestService_AddShipsTransactional(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	empty := s.f.copyField()
+
+	ship := 2
+	assert.Equal(t, HTTPError{
+		ErrCode: CodeCellOccupiedNearby,
+		Err:     errorCellIsOccupiedNearby.Err,
+		Details: &ErrorDetails{Ship: &ship, Coord: "A2"},
+		Code:    400,
+	}, s.addShipsByCoordinates("A1 A1,C3 C3,A2 B2", caller{}))
+	assert.Equal(t, empty, s.f.field)
+	assert.Equal(t, uint64(1), s.state().version)
+
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1,C3 C3", caller{}))
+}
+
+func TestService_ValidateShips(t *testing.T) {
+	ship := func(i int, e HTTPError) HTTPError {
+		return e.withShip(i)
+	}
+	coord := func(i int, e HTTPError, c coordinates.Coordinate) HTTPError {
+		return e.withShip(i).withCoord(c)
+	}
+
+	tests := []struct {
+		name    string
+		opts    fieldOptions
+		coords  string
+		cl      caller
+		want    []HTTPError
+		wantErr error
+	}{
+		{
+			name:   "success, valid placement",
+			opts:   fieldOptions{size: 3},
+			coords: "A1 A2,C1 C3",
+		},
+		{
+			name:   "success, all problems",
+			opts:   fieldOptions{size: 3},
+			coords: "A1 A2,A2 B2,X,C3 D3",
+			want: []HTTPError{
+				ship(2, errorInvalidCoordinate),
+				coord(1, errorCellIsOccupiedByShip, coordinates.Coordinate{X: 0, Y: 1}),
+				coord(1, errorCellIsOccupiedNearby, coordinates.Coordinate{X: 1, Y: 1}),
+				coord(3, errorOutOfBonds, coordinates.Coordinate{X: 3, Y: 2}),
+			},
+		},
+		{
+			name:   "success, rules problems",
+			opts:   fieldOptions{size: 10, rules: RulesClassic},
+			coords: "A1 B2",
+			want: []HTTPError{
+				ship(0, errorShipNotStraight),
+				errorFleetMismatch,
+			},
+		},
+		{
+			name:   "success, ship cells",
+			opts:   fieldOptions{size: 3},
+			coords: "A1+A2+B2,C1 C1,B1 B1",
+			want: []HTTPError{
+				coord(1, errorCellIsOccupiedNearby, coordinates.Coordinate{X: 2, Y: 0}),
+				coord(2, errorCellIsOccupiedNearby, coordinates.Coordinate{X: 1, Y: 0}),
+			},
+		},
+		{
+			name:   "success, empty coordinates",
+			opts:   fieldOptions{size: 3},
+			coords: "",
+			want:   []HTTPError{errorInvalidCoordinate},
+		},
+		{
+			name:    "error, not owner",
+			opts:    fieldOptions{size: 3},
+			coords:  "A1 A1",
+			cl:      caller{player: "bob"},
+			wantErr: errorNotBoardOwner,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(tt.opts, caller{player: "alice"}))
+			empty := s.f.copyField()
+
+			cl := tt.cl
+			if cl.player == "" {
+				cl.player = "alice"
+			}
+			got, err := s.validateShips(tt.coords, cl)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, empty, s.f.field)
+			assert.False(t, s.f.shipsAdded)
+		})
+	}
+}
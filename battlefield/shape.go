@@ -0,0 +1,104 @@
+package battlefield
+
+import (
+	"strconv"
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// Ship shapes: the letter the shape looks like and the number of cells.
+// Shapes match after rotation and reflection, so L4 includes J
+// and Z4 includes S.
+const (
+	ShapeI1 = "I1"
+	ShapeI2 = "I2"
+	ShapeI3 = "I3"
+	ShapeI4 = "I4"
+	ShapeI5 = "I5"
+	ShapeO4 = "O4"
+	ShapeL4 = "L4"
+	ShapeT4 = "T4"
+	ShapeZ4 = "Z4"
+)
+
+// shapeCells are cells of the shapes in any orientation.
+var shapeCells = map[string][]coordinates.Coordinate{
+	ShapeI1: {{X: 0, Y: 0}},
+	ShapeI2: {{X: 0, Y: 0}, {X: 0, Y: 1}},
+	ShapeI3: {{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}},
+	ShapeI4: {{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3}},
+	ShapeI5: {{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3}, {X: 0, Y: 4}},
+	ShapeO4: {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+	ShapeL4: {{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}},
+	ShapeT4: {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 1}},
+	ShapeZ4: {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}},
+}
+
+// shapeNames are names of the shapes by canonical form.
+var shapeNames = func() map[string]string {
+	names := make(map[string]string, len(shapeCells))
+	for name, cells := range shapeCells {
+		names[canonicalShape(cells)] = name
+	}
+	return names
+}()
+
+// shapeOf returns name of the shape of the cells, empty if the shape has no name.
+func shapeOf(cells coordinates.Coordinates) string {
+	return shapeNames[canonicalShape(cells.Sorted())]
+}
+
+// canonicalShape returns the same string for cells of the same shape
+// in any position and orientation: the least of descriptions of all
+// rotations and reflections of the cells.
+func canonicalShape(cells []coordinates.Coordinate) string {
+	best := ""
+	for t := 0; t < 8; t++ {
+		xs := make([]int, len(cells))
+		ys := make([]int, len(cells))
+		for i, c := range cells {
+			x, y := int(c.X), int(c.Y)
+			if t&1 != 0 {
+				x = -x
+			}
+			if t&2 != 0 {
+				y = -y
+			}
+			if t&4 != 0 {
+				x, y = y, x
+			}
+			xs[i], ys[i] = x, y
+		}
+		if d := describeShape(xs, ys); best == "" || d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// describeShape moves cells to the origin and lists them in reading order.
+func describeShape(xs, ys []int) string {
+	minX, minY := xs[0], ys[0]
+	for i := range xs {
+		if xs[i] < minX {
+			minX = xs[i]
+		}
+		if ys[i] < minY {
+			minY = ys[i]
+		}
+	}
+	moved := make([]coordinates.Coordinate, len(xs))
+	for i := range xs {
+		moved[i] = coordinates.Coordinate{X: uint(xs[i] - minX), Y: uint(ys[i] - minY)}
+	}
+
+	var b strings.Builder
+	for _, c := range coordinates.NewCoordinates(moved...).Sorted() {
+		b.WriteString(strconv.FormatUint(uint64(c.X), 10))
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatUint(uint64(c.Y), 10))
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestShapeOf(t *testing.T) {
+	tests := []struct {
+		cells string
+		want  string
+	}{
+		{cells: "C3 C3", want: ShapeI1},
+		{cells: "A1 C1", want: ShapeI3},
+		{cells: "B2 B6", want: ShapeI5},
+		{cells: "A1 A6", want: ""},
+		{cells: "D4 E5", want: ShapeO4},
+		{cells: "A1+A2+A3+B3", want: ShapeL4},
+		{cells: "B1+B2+B3+A3", want: ShapeL4},
+		{cells: "A1+B1+C1+C2", want: ShapeL4},
+		{cells: "A2+B2+C2+B1", want: ShapeT4},
+		{cells: "B1+B2+B3+A2", want: ShapeT4},
+		{cells: "A1+B1+B2+C2", want: ShapeZ4},
+		{cells: "A2+B2+B1+C1", want: ShapeZ4},
+		{cells: "A1+A2+B2+B3", want: ShapeZ4},
+		{cells: "A1+B1+C1+B2+B3", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cells, func(t *testing.T) {
+			sh, ok := parseShip(tt.cells, coordinates.Square)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, shapeOf(sh.inner))
+		})
+	}
+}
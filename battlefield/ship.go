@@ -12,6 +12,8 @@ type ship struct {
 	outer      coordinates.Coordinates
 	aliveCells int
 	isKnocked  bool
+	// repairs is the number of cells repaired, see Service.repairShip.
+	repairs int
 }
 
 func newShip(p1, p2 coordinates.Coordinate) *ship {
@@ -22,11 +24,81 @@ func newShip(p1, p2 coordinates.Coordinate) *ship {
 		c:          c,
 		inner:      in,
 		outer:      out,
-		aliveCells: len(in),
+		aliveCells: in.Len(),
 	}
 }
 
-func makeShipsFromCoords(coords string) ([]*ship, error) {
+// dimensions returns width and height of the ship.
+func (s *ship) dimensions() (width, height uint) {
+	return distance(s.c[0].X, s.c[1].X) + 1, distance(s.c[0].Y, s.c[1].Y) + 1
+}
+
+// newShipOn creates ship with the corners on the grid.
+func newShipOn(g coordinates.Grid, p1, p2 coordinates.Coordinate) *ship {
+	if g == coordinates.Square {
+		// rectangles are faster to build directly
+		return newShip(p1, p2)
+	}
+	cells := g.Area(p1, p2)
+	return &ship{
+		c:          [2]coordinates.Coordinate{p1, p2},
+		inner:      cells,
+		outer:      g.Ring(cells),
+		aliveCells: cells.Len(),
+	}
+}
+
+// newPolyomino creates ship of the cells on the grid,
+// c are corners of the bounding box.
+func newPolyomino(g coordinates.Grid, cells coordinates.Coordinates) *ship {
+	sorted := cells.Sorted()
+	lx, ly := sorted[0].X, sorted[0].Y
+	bx, by := lx, sorted[len(sorted)-1].Y
+	for _, c := range sorted {
+		if c.X < lx {
+			lx = c.X
+		}
+		if c.X > bx {
+			bx = c.X
+		}
+	}
+
+	return &ship{
+		c:          [2]coordinates.Coordinate{{X: lx, Y: ly}, {X: bx, Y: by}},
+		inner:      cells,
+		outer:      g.Ring(cells),
+		aliveCells: cells.Len(),
+	}
+}
+
+// isRect reports if the ship fills its bounding box.
+func (s *ship) isRect() bool {
+	width, height := s.dimensions()
+	return uint(s.inner.Len()) == width*height
+}
+
+// String returns the ship corners, e.g. "A1 A4", or cells of
+// the ship not filling its bounding box, e.g. "A1+A2+B2".
+func (s *ship) String() string {
+	if s.isRect() {
+		return s.c[0].String() + " " + s.c[1].String()
+	}
+	cells := s.inner.Sorted()
+	l := make([]string, len(cells))
+	for i, c := range cells {
+		l[i] = c.String()
+	}
+	return strings.Join(l, cellSeparator)
+}
+
+func distance(a, b uint) uint {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func makeShipsFromCoords(coords string, g coordinates.Grid) ([]*ship, error) {
 	if len(coords) == 0 {
 		return nil, errorInvalidCoordinate
 	}
@@ -35,20 +107,53 @@ func makeShipsFromCoords(coords string) ([]*ship, error) {
 
 	ships := make([]*ship, 0, len(s))
 
-	for _, sc := range s {
-		l := strings.Split(sc, " ")
-		if len(l) != 2 {
-			return nil, errorInvalidCoordinate
-		}
-		p1, ok := coordinates.ConvertCoordinate(l[0])
+	for i, sc := range s {
+		sh, ok := parseShip(sc, g)
 		if !ok {
-			return nil, errorInvalidCoordinate
+			return nil, errorInvalidCoordinate.withShip(i)
 		}
-		p2, ok := coordinates.ConvertCoordinate(l[1])
+		ships = append(ships, sh)
+	}
+	return ships, nil
+}
+
+// cellSeparator separates cells of the ship defined by the cells.
+const cellSeparator = "+"
+
+// parseShip parses ship corners, e.g. "A1 A4", or ship cells, e.g. "A1+A2+B2",
+// on the grid. Cells should not repeat, but they may be not connected.
+func parseShip(s string, g coordinates.Grid) (*ship, bool) {
+	if strings.Contains(s, cellSeparator) {
+		return parsePolyomino(s, g)
+	}
+	l := strings.Split(s, " ")
+	if len(l) != 2 {
+		return nil, false
+	}
+	p1, ok := coordinates.ConvertCoordinate(l[0])
+	if !ok {
+		return nil, false
+	}
+	p2, ok := coordinates.ConvertCoordinate(l[1])
+	if !ok {
+		return nil, false
+	}
+	return newShipOn(g, p1, p2), true
+}
+
+func parsePolyomino(s string, g coordinates.Grid) (*ship, bool) {
+	l := strings.Split(s, cellSeparator)
+	cells := make([]coordinates.Coordinate, len(l))
+	for i, v := range l {
+		c, ok := coordinates.ConvertCoordinate(v)
 		if !ok {
-			return nil, errorInvalidCoordinate
+			return nil, false
 		}
-		ships = append(ships, newShip(p1, p2))
+		cells[i] = c
 	}
-	return ships, nil
+	set := coordinates.NewCoordinates(cells...)
+	if set.Len() != len(cells) {
+		return nil, false
+	}
+	return newPolyomino(g, set), true
 }
@@ -12,12 +12,12 @@ func TestNewShip(t *testing.T) {
 	c := [2]coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 0}}
 	want := &ship{
 		c:     [2]coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 0}},
-		inner: coordinates.Coordinates{{X: 0, Y: 0}: {}},
-		outer: coordinates.Coordinates{
-			{X: 1, Y: 0}: {},
-			{X: 0, Y: 1}: {},
-			{X: 1, Y: 1}: {},
-		},
+		inner: coordinates.NewCoordinates([]coordinates.Coordinate{{X: 0, Y: 0}}...),
+		outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+			{X: 1, Y: 0},
+			{X: 0, Y: 1},
+			{X: 1, Y: 1},
+		}...),
 		aliveCells: 1,
 	}
 	got := newShip(c[0], c[1])
@@ -37,14 +37,14 @@ func TestMakeShipsFromCoords(t *testing.T) {
 			args: "A1 A1",
 			want: []*ship{{
 				c: [2]coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 0}},
-				inner: coordinates.Coordinates{
-					{X: 0, Y: 0}: {},
-				},
-				outer: coordinates.Coordinates{
-					{X: 1, Y: 0}: {},
-					{X: 0, Y: 1}: {},
-					{X: 1, Y: 1}: {},
-				},
+				inner: coordinates.NewCoordinates([]coordinates.Coordinate{
+					{X: 0, Y: 0},
+				}...),
+				outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+					{X: 1, Y: 0},
+					{X: 0, Y: 1},
+					{X: 1, Y: 1},
+				}...),
 				aliveCells: 1,
 			}},
 			wantErr: nil,
@@ -55,36 +55,70 @@ func TestMakeShipsFromCoords(t *testing.T) {
 			want: []*ship{
 				{
 					c: [2]coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 0}},
-					inner: coordinates.Coordinates{
-						{X: 0, Y: 0}: {},
-					},
-					outer: coordinates.Coordinates{
-						{X: 1, Y: 0}: {},
-						{X: 0, Y: 1}: {},
-						{X: 1, Y: 1}: {},
-					},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{
+						{X: 0, Y: 0},
+					}...),
+					outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+						{X: 1, Y: 0},
+						{X: 0, Y: 1},
+						{X: 1, Y: 1},
+					}...),
 					aliveCells: 1,
 				},
 				{
 					c: [2]coordinates.Coordinate{{X: 1, Y: 2}, {X: 1, Y: 2}},
-					inner: coordinates.Coordinates{
-						{X: 1, Y: 2}: {},
-					},
-					outer: coordinates.Coordinates{
-						{X: 0, Y: 1}: {},
-						{X: 0, Y: 2}: {},
-						{X: 0, Y: 3}: {},
-						{X: 1, Y: 1}: {},
-						{X: 1, Y: 3}: {},
-						{X: 2, Y: 3}: {},
-						{X: 2, Y: 2}: {},
-						{X: 2, Y: 1}: {},
-					},
+					inner: coordinates.NewCoordinates([]coordinates.Coordinate{
+						{X: 1, Y: 2},
+					}...),
+					outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+						{X: 0, Y: 1},
+						{X: 0, Y: 2},
+						{X: 0, Y: 3},
+						{X: 1, Y: 1},
+						{X: 1, Y: 3},
+						{X: 2, Y: 3},
+						{X: 2, Y: 2},
+						{X: 2, Y: 1},
+					}...),
 					aliveCells: 1,
 				},
 			},
 			wantErr: nil,
 		},
+		{
+			name: "success, ship cells",
+			args: "A1+B1+B2",
+			want: []*ship{{
+				c: [2]coordinates.Coordinate{{X: 0, Y: 0}, {X: 1, Y: 1}},
+				inner: coordinates.NewCoordinates([]coordinates.Coordinate{
+					{X: 0, Y: 0},
+					{X: 1, Y: 0},
+					{X: 1, Y: 1},
+				}...),
+				outer: coordinates.NewCoordinates([]coordinates.Coordinate{
+					{X: 2, Y: 0},
+					{X: 0, Y: 1},
+					{X: 2, Y: 1},
+					{X: 0, Y: 2},
+					{X: 1, Y: 2},
+					{X: 2, Y: 2},
+				}...),
+				aliveCells: 3,
+			}},
+			wantErr: nil,
+		},
+		{
+			name:    "error, repeated cell",
+			args:    "A1+A2+A1",
+			want:    nil,
+			wantErr: errorInvalidCoordinate.withShip(0),
+		},
+		{
+			name:    "error, invalid cell",
+			args:    "A1 A1,A1+2",
+			want:    nil,
+			wantErr: errorInvalidCoordinate.withShip(1),
+		},
 		{
 			name:    "error, zero ships",
 			args:    "",
@@ -95,26 +129,78 @@ func TestMakeShipsFromCoords(t *testing.T) {
 			name:    "error, single coordinate provided",
 			args:    "A1",
 			want:    nil,
-			wantErr: errorInvalidCoordinate,
+			wantErr: errorInvalidCoordinate.withShip(0),
 		},
 		{
 			name:    "error, first coordinate is invalid",
 			args:    "A B2",
 			want:    nil,
-			wantErr: errorInvalidCoordinate,
+			wantErr: errorInvalidCoordinate.withShip(0),
 		},
 		{
 			name:    "error, second coordinate is invalid",
 			args:    "A1 2",
 			want:    nil,
-			wantErr: errorInvalidCoordinate,
+			wantErr: errorInvalidCoordinate.withShip(0),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ship, err := makeShipsFromCoords(tt.args)
+			ship, err := makeShipsFromCoords(tt.args, coordinates.Square)
 			assert.Equal(t, tt.want, ship)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
+
+func TestShip_String(t *testing.T) {
+	tests := []struct {
+		coords string
+		want   string
+	}{
+		{coords: "A1 A4", want: "A1 A4"},
+		{coords: "B2 A1", want: "B2 A1"},
+		{coords: "A1+A2", want: "A1 A2"},
+		{coords: "B1+A2+A1", want: "A1+B1+A2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coords, func(t *testing.T) {
+			sh, ok := parseShip(tt.coords, coordinates.Square)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, sh.String())
+
+			// the string describes the same ship
+			again, ok := parseShip(sh.String(), coordinates.Square)
+			assert.True(t, ok)
+			assert.Equal(t, sh.inner, again.inner)
+		})
+	}
+}
+
+func TestShip_String_Hex(t *testing.T) {
+	tests := []struct {
+		coords string
+		want   string
+		cells  int
+	}{
+		{coords: "A1 A4", want: "A1 A4", cells: 4},
+		{coords: "A1 B2", want: "A1 B2", cells: 4},
+		{coords: "C1 A3", want: "C1+B2+A3", cells: 3},
+		{coords: "A3+B2", want: "B2+A3", cells: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coords, func(t *testing.T) {
+			sh, ok := parseShip(tt.coords, coordinates.Hexagonal)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, sh.String())
+			assert.Equal(t, tt.cells, sh.aliveCells)
+
+			again, ok := parseShip(sh.String(), coordinates.Hexagonal)
+			assert.True(t, ok)
+			assert.Equal(t, sh.inner, again.inner)
+			assert.Equal(t, sh.outer, again.outer)
+		})
+	}
+}
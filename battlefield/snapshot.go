@@ -0,0 +1,285 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"my/battleship/storage"
+)
+
+// activeGameKey is the store key of the current game.
+const activeGameKey = "games/active"
+
+// Kinds of recorded events.
+const (
+	eventShips = "ships"
+	eventShot  = "shot"
+	// eventAuto is random placement of ships, Arg is lengths of ships.
+	eventAuto = "auto"
+	// Weapons of the advanced mode, Arg is the target.
+	eventSonar = "sonar"
+	eventRadar = "radar"
+	eventBomb  = "bomb"
+	// eventMove is the move of the ship, Arg is the index of the ship
+	// and the direction, see formatShipArg.
+	eventMove = "move"
+	// eventRepair is the repair of the ship, Arg is the index of the ship
+	// and the cell, see formatShipArg.
+	eventRepair = "repair"
+	// eventMines is placement of mines, eventSkip is the shot which
+	// spent the turn the attacker lost on the mine.
+	eventMines = "mines"
+	eventSkip  = "skip"
+	// eventPause and eventResume stop and start the clock of the game.
+	eventPause  = "pause"
+	eventResume = "resume"
+)
+
+// event is a recorded game move.
+type event struct {
+	Kind   string    `json:"kind"`
+	Player string    `json:"player,omitempty"`
+	Admin  bool      `json:"admin,omitempty"`
+	Arg    string    `json:"arg"`
+	At     time.Time `json:"at"`
+}
+
+// snapshot is the persistent form of the game. The game is restored
+// by replaying recorded events on a new field.
+type snapshot struct {
+	ID    string `json:"id,omitempty"`
+	Size  uint   `json:"size"`
+	Rules string `json:"rules,omitempty"`
+	// Grid is the name of the grid, GridSquare if empty.
+	Grid string `json:"grid,omitempty"`
+	// Wrap is set if the field is a torus.
+	Wrap bool `json:"wrap,omitempty"`
+	// Advanced is set if the game has weapons, the inventory
+	// is restored by replaying moves.
+	Advanced bool `json:"advanced,omitempty"`
+	// Moving is set if ships can move.
+	Moving bool `json:"moving,omitempty"`
+	// Repairs is the number of repairs of every ship.
+	Repairs int `json:"repairs,omitempty"`
+	// Mines is the number of mines, MinePenalty is the penalty of hitting them.
+	Mines       int    `json:"mines,omitempty"`
+	MinePenalty string `json:"mine_penalty,omitempty"`
+	Seed        int64  `json:"seed"`
+	Owner       string `json:"owner,omitempty"`
+	// Created is the time the field was created, the clock of the game
+	// is restored from it and from times of pauses.
+	Created time.Time `json:"created"`
+	// Terrain is terrain of the field, named maps are saved as terrain.
+	Terrain *Terrain `json:"terrain,omitempty"`
+	Log     []event  `json:"log"`
+}
+
+// record appends successful move to the game log.
+func (f *Field) record(kind string, cl caller, arg string) {
+	f.log = append(f.log, event{
+		Kind:   kind,
+		Player: cl.player,
+		Admin:  cl.admin,
+		Arg:    arg,
+		At:     time.Now().UTC(),
+	})
+}
+
+func (f Field) snapshot() snapshot {
+	snap := snapshot{
+		ID:       f.id,
+		Size:     f.size,
+		Rules:    f.rules,
+		Seed:     f.seed,
+		Owner:    f.owner,
+		Created:  f.created,
+		Wrap:     f.wrap,
+		Advanced: f.weapons != nil,
+		Moving:   f.moving,
+		Repairs:  f.repairs,
+		Mines:    f.mines,
+		Log:      f.log,
+	}
+	if f.grid != GridSquare {
+		snap.Grid = f.grid
+	}
+	if f.mines > 0 {
+		snap.MinePenalty = f.minePenalty
+	}
+	if !f.terrain.isEmpty() {
+		t := f.terrain
+		snap.Terrain = &t
+	}
+	return snap
+}
+
+// replay restores the field from the snapshot.
+func replay(l *logrus.Logger, snap snapshot) (Field, error) {
+	return replaySteps(l, snap, nil)
+}
+
+// replaySteps restores the field from the snapshot and calls step
+// with the field after every event if step is set.
+func replaySteps(l *logrus.Logger, snap snapshot, step func(e event, f Field)) (Field, error) {
+	tmp := &Service{logger: l}
+	opts := fieldOptions{size: snap.Size, rules: snap.Rules, grid: snap.Grid, wrap: snap.Wrap, advanced: snap.Advanced, moving: snap.Moving, repairs: snap.Repairs, mines: snap.Mines, minePenalty: snap.MinePenalty, seed: snap.Seed}
+	if snap.Terrain != nil {
+		opts.terrain = *snap.Terrain
+	}
+	if err := tmp.createField(opts, caller{player: snap.Owner}); err != nil {
+		return Field{}, err
+	}
+	for i, e := range snap.Log {
+		cl := caller{player: e.Player, admin: e.Admin}
+
+		var err error
+		switch e.Kind {
+		case eventShips:
+			err = tmp.addShipsByCoordinates(e.Arg, cl)
+		case eventAuto:
+			lengths, ok := parseLengths(e.Arg)
+			if !ok {
+				err = fmt.Errorf("invalid lengths %q", e.Arg)
+				break
+			}
+			err = tmp.autoPlaceShips(lengths, cl)
+		case eventShot:
+			_, err = tmp.shot(e.Arg, cl)
+		case eventSonar:
+			_, err = tmp.sonar(e.Arg, cl)
+		case eventRadar:
+			_, err = tmp.radar(e.Arg, cl)
+		case eventBomb:
+			_, err = tmp.bomb(e.Arg, cl)
+		case eventMove:
+			id, direction, ok := parseShipArg(e.Arg)
+			if !ok {
+				err = fmt.Errorf("invalid move %q", e.Arg)
+				break
+			}
+			_, err = tmp.moveShip(id, direction, cl)
+		case eventPause:
+			tmp.f.pause(e.At)
+			tmp.changed()
+		case eventResume:
+			tmp.f.resume(e.At)
+			tmp.changed()
+		case eventRepair:
+			id, coordinate, ok := parseShipArg(e.Arg)
+			if !ok {
+				err = fmt.Errorf("invalid repair %q", e.Arg)
+				break
+			}
+			_, err = tmp.repairShip(id, coordinate, cl)
+		case eventMines:
+			err = tmp.placeMines(e.Arg, cl)
+		case eventSkip:
+			if !tmp.loseTurn(e.Arg, cl) {
+				err = fmt.Errorf("turn is not lost")
+			}
+		default:
+			err = fmt.Errorf("unknown event kind %q", e.Kind)
+		}
+		if err != nil {
+			return Field{}, fmt.Errorf("can't replay event %d: %v", i, err)
+		}
+		if step != nil {
+			step(e, tmp.f)
+		}
+	}
+	// keep original identifier and timestamps
+	tmp.f.id = snap.ID
+	tmp.f.created = snap.Created
+	tmp.f.log = snap.Log
+	return tmp.f, nil
+}
+
+// SetStore sets the store to persist the game to.
+func (s *Service) SetStore(st storage.Store) {
+	s.store = st
+	s.archiveMu.Lock()
+	s.archive = nil
+	s.archiveMu.Unlock()
+}
+
+// Restore loads the game saved in the store.
+func (s *Service) Restore() error {
+	s.lock()
+	defer s.Unlock()
+
+	if s.store == nil {
+		return nil
+	}
+	if err := s.restoreStats(); err != nil {
+		return err
+	}
+	b, err := s.store.Get(activeGameKey)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	snap := snapshot{}
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return err
+	}
+	f, err := replay(s.logger, snap)
+	if err != nil {
+		return err
+	}
+
+	s.f = f
+	if !f.gameIsOver && !f.paused {
+		s.notify().GameCreated()
+	}
+	s.logger.Infof("GAME RESTORED WITH %d MOVES", len(f.log))
+	return nil
+}
+
+// Flush saves the game to the store if it was changed since the last call,
+// the finished game is archived.
+func (s *Service) Flush() error {
+	s.archiveFinished()
+
+	s.lock()
+	defer s.Unlock()
+
+	if s.store == nil {
+		return nil
+	}
+	if !s.dirty {
+		return nil
+	}
+
+	var err error
+	if s.f.isSet {
+		var b []byte
+		b, err = json.Marshal(s.f.snapshot())
+		if err == nil {
+			err = s.store.Put(activeGameKey, b)
+		}
+	} else {
+		err = s.store.Delete(activeGameKey)
+	}
+	if err == nil {
+		err = s.flushStats()
+	}
+	if err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Ping checks if the store is available.
+func (s *Service) Ping() error {
+	if s.store == nil {
+		return nil
+	}
+	return s.store.Ping()
+}
@@ -0,0 +1,219 @@
+package battlefield
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/storage"
+)
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name      string
+		snap      snapshot
+		wantState state
+		wantErr   bool
+	}{
+		{
+			name: "success, game in progress",
+			snap: snapshot{
+				Size:  3,
+				Rules: RulesFree,
+				Owner: "alice",
+				Log: []event{
+					{Kind: eventShips, Player: "alice", Arg: "A1 A2,C3 C3"},
+					{Kind: eventShot, Player: "bob", Arg: "A1"},
+					{Kind: eventShot, Player: "bob", Arg: "B3"},
+				},
+			},
+			wantState: state{shipCount: 2, knocked: 1, shotCount: 2},
+		},
+		{
+			name: "success, ships added by admin",
+			snap: snapshot{
+				Size:  3,
+				Rules: RulesFree,
+				Owner: "alice",
+				Log: []event{
+					{Kind: eventShips, Player: "root", Admin: true, Arg: "A1 A1"},
+					{Kind: eventShot, Player: "bob", Arg: "A1"},
+				},
+			},
+			wantState: state{shipCount: 1, destroyed: 1, shotCount: 1},
+		},
+		{
+			name: "success, classic rules",
+			snap: snapshot{
+				Size:  10,
+				Rules: RulesClassic,
+				Log: []event{
+					{Kind: eventShips, Arg: "A1 A4,C1 C3,E1 E3,G1 G2,I1 I2,A6 B6,J4 J4,J6 J6,J8 J8,J10 J10"},
+				},
+			},
+			wantState: state{shipCount: 10},
+		},
+		{
+			name: "success, terrain",
+			snap: snapshot{
+				Size:    3,
+				Rules:   RulesFree,
+				Terrain: &Terrain{Islands: []string{"B1"}, Reefs: []string{"B3"}},
+				Log: []event{
+					{Kind: eventShips, Arg: "A1 A3"},
+					{Kind: eventShot, Arg: "B3"},
+				},
+			},
+			wantState: state{shipCount: 1, shotCount: 1},
+		},
+		{
+			name: "error, ship on terrain",
+			snap: snapshot{
+				Size:    3,
+				Terrain: &Terrain{Islands: []string{"A2"}},
+				Log: []event{
+					{Kind: eventShips, Arg: "A1 A3"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown rules",
+			snap:    snapshot{Size: 3, Rules: "chess"},
+			wantErr: true,
+		},
+		{
+			name:    "error, invalid size",
+			snap:    snapshot{Size: 0},
+			wantErr: true,
+		},
+		{
+			name: "error, invalid move",
+			snap: snapshot{
+				Size: 3,
+				Log: []event{
+					{Kind: eventShot, Arg: "A1"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "error, unknown event",
+			snap: snapshot{
+				Size: 3,
+				Log:  []event{{Kind: "teleport"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := replay(logrus.New(), tt.snap)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.wantState, f.state)
+			assert.Equal(t, tt.snap, f.snapshot())
+		})
+	}
+}
+
+func TestService_FlushRestore(t *testing.T) {
+	store := storage.NewMemory()
+	alice := caller{player: "alice"}
+	bob := caller{player: "bob"}
+
+	s := NewService(logrus.New())
+	s.SetStore(store)
+
+	// nothing to flush
+	assert.NoError(t, s.Flush())
+	_, err := store.Get(activeGameKey)
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A2,C3 C3", alice))
+	_, err = s.shot("A1", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+
+	restored := NewService(logrus.New())
+	restored.SetStore(store)
+	assert.NoError(t, restored.Restore())
+	assert.Equal(t, s.f.snapshot(), restored.f.snapshot())
+	assert.Equal(t, s.f.state, restored.f.state)
+	assert.Equal(t, s.f.stats, restored.f.stats)
+	// the clock goes on while states are taken
+	want, got := s.state(), restored.state()
+	assert.InDelta(t, want.clock, got.clock, float64(time.Second))
+	want.clock, got.clock = 0, 0
+	assert.Equal(t, want, got)
+	assert.Equal(t, "bob", restored.f.attacker)
+
+	// restored game goes on
+	_, err = restored.shot("A1", bob)
+	assertError(t, errorCellAlreadyShot, err)
+	_, err = restored.shot("A2", bob)
+	assert.NoError(t, err)
+
+	// cleared game is removed from the store
+	assert.NoError(t, s.clearField(caller{admin: true}))
+	assert.NoError(t, s.Flush())
+	_, err = store.Get(activeGameKey)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+func TestService_Restore(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{
+			name:    "error, invalid json",
+			value:   "{not a json",
+			wantErr: true,
+		},
+		{
+			name:    "error, invalid snapshot",
+			value:   `{"size": 100}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemory()
+			_ = store.Put(activeGameKey, []byte(tt.value))
+
+			s := NewService(logrus.New())
+			s.SetStore(store)
+			err := s.Restore()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+type failingStore struct {
+	storage.Store
+}
+
+func (failingStore) Ping() error {
+	return errors.New("disk is gone")
+}
+
+func TestService_Ping(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.Ping())
+
+	s.SetStore(storage.NewMemory())
+	assert.NoError(t, s.Ping())
+
+	s.SetStore(failingStore{})
+	assert.Error(t, s.Ping())
+}
@@ -0,0 +1,242 @@
+package battlefield
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"my/battleship/storage"
+)
+
+// statsKey is the store key of statistics of finished games.
+const statsKey = "stats"
+
+// anonymous names the player if authentication is disabled.
+const anonymous = "anonymous"
+
+// gameStats tracks shots of a single game.
+type gameStats struct {
+	shots  int
+	hits   int
+	sunk   int
+	streak int
+	// longestStreak is the longest sequence of hits in a row.
+	longestStreak int
+	// firstHit is the number of shots made to the first hit, zero if no hits.
+	firstHit int
+}
+
+func (g *gameStats) shot(knock, destroy bool) {
+	g.shots++
+	if !knock {
+		g.streak = 0
+		return
+	}
+	g.hits++
+	g.streak++
+	if g.streak > g.longestStreak {
+		g.longestStreak = g.streak
+	}
+	if g.firstHit == 0 {
+		g.firstHit = g.shots
+	}
+	if destroy {
+		g.sunk++
+	}
+}
+
+// playerStats aggregates finished games of the attacker.
+type playerStats struct {
+	Games         int `json:"games"`
+	Shots         int `json:"shots"`
+	Hits          int `json:"hits"`
+	Sunk          int `json:"sunk"`
+	LongestStreak int `json:"longest_streak"`
+	// FirstHits is the sum of shots to the first hit of all games.
+	FirstHits int `json:"first_hits"`
+}
+
+// heatmap counts shots and hits of every cell across finished games
+// of the same field size. Cells are indexed by row, then by column.
+type heatmap struct {
+	Games int     `json:"games"`
+	Shots [][]int `json:"shots"`
+	Hits  [][]int `json:"hits"`
+}
+
+func newHeatmap(size uint) *heatmap {
+	h := &heatmap{
+		Shots: make([][]int, size),
+		Hits:  make([][]int, size),
+	}
+	for i := range h.Shots {
+		h.Shots[i] = make([]int, size)
+		h.Hits[i] = make([]int, size)
+	}
+	return h
+}
+
+func (h *heatmap) copy() heatmap {
+	c := heatmap{Games: h.Games, Shots: make([][]int, len(h.Shots)), Hits: make([][]int, len(h.Hits))}
+	for i := range h.Shots {
+		c.Shots[i] = append([]int(nil), h.Shots[i]...)
+		c.Hits[i] = append([]int(nil), h.Hits[i]...)
+	}
+	return c
+}
+
+// aggregate contains statistics of finished games.
+type aggregate struct {
+	Games    int                     `json:"games"`
+	Players  map[string]*playerStats `json:"players"`
+	Heatmaps map[uint]*heatmap       `json:"heatmaps"`
+}
+
+// add adds finished game to the statistics.
+func (a *aggregate) add(f Field) {
+	if a.Players == nil {
+		a.Players = make(map[string]*playerStats)
+	}
+	if a.Heatmaps == nil {
+		a.Heatmaps = make(map[uint]*heatmap)
+	}
+	a.Games++
+
+	player := f.attacker
+	if player == "" {
+		player = anonymous
+	}
+	p, ok := a.Players[player]
+	if !ok {
+		p = &playerStats{}
+		a.Players[player] = p
+	}
+	p.Games++
+	p.Shots += f.stats.shots
+	p.Hits += f.stats.hits
+	p.Sunk += f.stats.sunk
+	p.FirstHits += f.stats.firstHit
+	if f.stats.longestStreak > p.LongestStreak {
+		p.LongestStreak = f.stats.longestStreak
+	}
+
+	h, ok := a.Heatmaps[f.size]
+	if !ok {
+		h = newHeatmap(f.size)
+		a.Heatmaps[f.size] = h
+	}
+	h.Games++
+	for x := range f.field {
+		for y, c := range f.field[x] {
+			if !c.shot {
+				continue
+			}
+			h.Shots[y][x]++
+			if c.ship != nil {
+				h.Hits[y][x]++
+			}
+		}
+	}
+}
+
+// heatmapCellSize is the size of the cell in heatmap image in pixels.
+const heatmapCellSize = 16
+
+// renderHeatmap writes PNG image of cells indexed by row, then by column.
+// Cells are shaded from white to red relative to the maximum count.
+func renderHeatmap(w io.Writer, cells [][]int) error {
+	max := 0
+	for _, row := range cells {
+		for _, n := range row {
+			if n > max {
+				max = n
+			}
+		}
+	}
+
+	size := len(cells) * heatmapCellSize
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			n := cells[y/heatmapCellSize][x/heatmapCellSize]
+			shade := uint8(255)
+			if max > 0 {
+				shade = uint8(255 - 255*n/max)
+			}
+			img.Set(x, y, color.RGBA{R: 255, G: shade, B: shade, A: 255})
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// statsReport is the statistics of the current and finished games.
+type statsReport struct {
+	game    gameStats
+	playing bool
+	games   int
+	players map[string]playerStats
+}
+
+func (s *Service) stats() statsReport {
+	s.rLock()
+	defer s.RUnlock()
+
+	s.logger.Debug("Service: stats started")
+
+	r := statsReport{
+		game:    s.f.stats,
+		playing: s.f.shipsAdded,
+		games:   s.total.Games,
+		players: make(map[string]playerStats, len(s.total.Players)),
+	}
+	for name, p := range s.total.Players {
+		r.players[name] = *p
+	}
+	return r
+}
+
+// heatmap returns heatmap of finished games of the field size,
+// size of the current field is used if size is zero.
+func (s *Service) heatmap(size uint) (heatmap, error) {
+	s.rLock()
+	defer s.RUnlock()
+
+	s.logger.WithField("size", size).Debug("Service: heatmap started")
+
+	if size == 0 {
+		size = s.f.size
+	}
+	if size < 1 || size > s.fieldSizeLimit() {
+		return heatmap{}, errorInvalidFieldSize
+	}
+	if h, ok := s.total.Heatmaps[size]; ok {
+		return h.copy(), nil
+	}
+	return *newHeatmap(size), nil
+}
+
+// restoreStats loads statistics saved in the store, the lock should be held.
+func (s *Service) restoreStats() error {
+	b, err := s.store.Get(statsKey)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &s.total)
+}
+
+// flushStats saves statistics to the store, the lock should be held.
+func (s *Service) flushStats() error {
+	if s.total.Games == 0 {
+		return nil
+	}
+	b, err := json.Marshal(s.total)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(statsKey, b)
+}
@@ -0,0 +1,187 @@
+package battlefield
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/storage"
+)
+
+func TestGameStats_Shot(t *testing.T) {
+	type shot struct {
+		knock, destroy bool
+	}
+
+	tests := []struct {
+		name  string
+		shots []shot
+		want  gameStats
+	}{
+		{
+			name: "no shots",
+			want: gameStats{},
+		},
+		{
+			name:  "misses",
+			shots: []shot{{}, {}},
+			want:  gameStats{shots: 2},
+		},
+		{
+			name:  "streaks",
+			shots: []shot{{}, {knock: true}, {knock: true, destroy: true}, {}, {knock: true}},
+			want:  gameStats{shots: 5, hits: 3, sunk: 1, streak: 1, longestStreak: 2, firstHit: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gameStats{}
+			for _, sh := range tt.shots {
+				g.shot(sh.knock, sh.destroy)
+			}
+			assert.Equal(t, tt.want, g)
+		})
+	}
+}
+
+func TestService_Stats(t *testing.T) {
+	alice := caller{player: "alice"}
+	bob := caller{player: "bob"}
+
+	s := NewService(logrus.New())
+	assert.Equal(t, statsReport{players: map[string]playerStats{}}, s.stats())
+	_, err := s.heatmap(0)
+	assertError(t, errorInvalidFieldSize, err)
+
+	play := func(shots ...string) {
+		assert.NoError(t, s.createField(fieldOptions{size: 3}, alice))
+		assert.NoError(t, s.addShipsByCoordinates("A1 A2,C3 C3", alice))
+		for _, c := range shots {
+			_, err := s.shot(c, bob)
+			assert.NoError(t, err)
+		}
+	}
+
+	play("B3", "A1", "A2", "C3")
+	play("A1", "C1", "A2")
+	assert.Equal(t, statsReport{
+		game:    gameStats{shots: 3, hits: 2, sunk: 1, streak: 1, longestStreak: 1, firstHit: 1},
+		playing: true,
+		games:   1,
+		players: map[string]playerStats{
+			"bob": {Games: 1, Shots: 4, Hits: 3, Sunk: 2, LongestStreak: 3, FirstHits: 2},
+		},
+	}, s.stats())
+
+	_, err = s.shot("C3", bob)
+	assert.NoError(t, err)
+	assert.Equal(t, playerStats{Games: 2, Shots: 8, Hits: 6, Sunk: 4, LongestStreak: 3, FirstHits: 3}, s.stats().players["bob"])
+
+	h, err := s.heatmap(0)
+	assert.NoError(t, err)
+	assert.Equal(t, heatmap{
+		Games: 2,
+		Shots: [][]int{{2, 0, 1}, {2, 0, 0}, {0, 1, 2}},
+		Hits:  [][]int{{2, 0, 0}, {2, 0, 0}, {0, 0, 2}},
+	}, h)
+
+	// heatmap is a copy
+	h.Shots[0][0] = 10
+	h, err = s.heatmap(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, h.Shots[0][0])
+
+	h, err = s.heatmap(2)
+	assert.NoError(t, err)
+	assert.Equal(t, heatmap{Shots: [][]int{{0, 0}, {0, 0}}, Hits: [][]int{{0, 0}, {0, 0}}}, h)
+
+	_, err = s.heatmap(maxFieldSize + 1)
+	assertError(t, errorInvalidFieldSize, err)
+}
+
+func TestService_Stats_Anonymous(t *testing.T) {
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 1}, caller{admin: true}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{admin: true}))
+	_, err := s.shot("A1", caller{admin: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]playerStats{
+		anonymous: {Games: 1, Shots: 1, Hits: 1, Sunk: 1, LongestStreak: 1, FirstHits: 1},
+	}, s.stats().players)
+}
+
+func TestService_Stats_GameOver(t *testing.T) {
+	bob := caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{player: "alice"}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{player: "alice"}))
+	_, err := s.shot("A1", bob)
+	assert.NoError(t, err)
+
+	// shots after the last ship is sunk don't count the game again
+	for _, c := range []string{"C3", "B2"} {
+		_, err = s.shot(c, bob)
+		assert.Equal(t, errorGameIsOver, err)
+	}
+	assert.Equal(t, 1, s.total.Games)
+	assert.Equal(t, playerStats{Games: 1, Shots: 1, Hits: 1, Sunk: 1, LongestStreak: 1, FirstHits: 1}, s.stats().players["bob"])
+	h, err := s.heatmap(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, h.Games)
+	assert.Equal(t, [][]int{{1, 0, 0}, {0, 0, 0}, {0, 0, 0}}, h.Shots)
+}
+
+func TestService_FlushRestoreStats(t *testing.T) {
+	store := storage.NewMemory()
+
+	s := NewService(logrus.New())
+	s.SetStore(store)
+	assert.NoError(t, s.createField(fieldOptions{size: 1}, caller{admin: true}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{admin: true}))
+	assert.NoError(t, s.Flush())
+	_, err := store.Get(statsKey)
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	_, err = s.shot("A1", caller{admin: true})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+
+	restored := NewService(logrus.New())
+	restored.SetStore(store)
+	assert.NoError(t, restored.Restore())
+	assert.Equal(t, s.total, restored.total)
+	assert.Equal(t, s.stats(), restored.stats())
+
+	// finished games of the restored game are not counted twice
+	assert.Equal(t, 1, restored.total.Games)
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	b := &bytes.Buffer{}
+	assert.NoError(t, renderHeatmap(b, [][]int{{0, 1}, {2, 0}}))
+
+	img, err := png.Decode(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*heatmapCellSize, img.Bounds().Dx())
+	assert.Equal(t, 2*heatmapCellSize, img.Bounds().Dy())
+
+	rgba := func(x, y int) [4]uint32 {
+		r, g, b, a := img.At(x*heatmapCellSize, y*heatmapCellSize).RGBA()
+		return [4]uint32{r >> 8, g >> 8, b >> 8, a >> 8}
+	}
+	assert.Equal(t, [4]uint32{255, 255, 255, 255}, rgba(0, 0))
+	assert.Equal(t, [4]uint32{255, 128, 128, 255}, rgba(1, 0))
+	assert.Equal(t, [4]uint32{255, 0, 0, 255}, rgba(0, 1))
+
+	// empty heatmap is white
+	b.Reset()
+	assert.NoError(t, renderHeatmap(b, [][]int{{0}}))
+	img, err = png.Decode(b)
+	assert.NoError(t, err)
+	assert.Equal(t, [4]uint32{255, 255, 255, 255}, rgba(0, 0))
+}
@@ -0,0 +1,138 @@
+package battlefield
+
+import (
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// Terrain kinds.
+const (
+	// TerrainIsland can't hold ships and can't be shot.
+	TerrainIsland = "island"
+	// TerrainReef can't hold ships and absorbs shots.
+	TerrainReef = "reef"
+)
+
+// terrain is the kind of terrain of the cell.
+type terrain uint8
+
+const (
+	terrainWater terrain = iota
+	terrainIsland
+	terrainReef
+)
+
+// Terrain describes impassable cells of the field. Areas are set like
+// ships in AddShipsRequest, by two corners or cell by cell, or by one cell:
+//
+//	{"islands": ["C3 D4", "H7+H8+G8"], "reefs": ["F2"]}
+type Terrain struct {
+	Islands []string `json:"islands,omitempty"`
+	Reefs   []string `json:"reefs,omitempty"`
+}
+
+// isEmpty checks if the field has no terrain.
+func (t Terrain) isEmpty() bool {
+	return len(t.Islands) == 0 && len(t.Reefs) == 0
+}
+
+// terrainMap is the named terrain of the field of the size.
+type terrainMap struct {
+	size    uint
+	terrain Terrain
+}
+
+// maps are named terrain presets.
+var maps = map[string]terrainMap{
+	// archipelago is scattered small islands and reefs.
+	"archipelago": {
+		size: 10,
+		terrain: Terrain{
+			Islands: []string{"C3 D3", "H2", "E6+E7+F7", "B9"},
+			Reefs:   []string{"G4 H4", "J8", "D10"},
+		},
+	},
+	// strait is two big islands with the reef between them.
+	"strait": {
+		size: 10,
+		terrain: Terrain{
+			Islands: []string{"E1 F3", "E8 F10"},
+			Reefs:   []string{"E5 F6"},
+		},
+	},
+}
+
+// terrainByName returns terrain of the named map for the field of the size.
+func terrainByName(name string, size uint) (Terrain, error) {
+	m, ok := maps[name]
+	if !ok {
+		return Terrain{}, errorUnknownMap
+	}
+	if m.size != size {
+		return Terrain{}, errorInvalidFieldSize
+	}
+	return m.terrain, nil
+}
+
+// parseArea parses area of terrain on the grid: a ship or a single cell.
+func parseArea(s string, g coordinates.Grid) (coordinates.Coordinates, bool) {
+	if !strings.ContainsAny(s, " "+cellSeparator) {
+		c, ok := coordinates.ConvertCoordinate(s)
+		if !ok {
+			return coordinates.Coordinates{}, false
+		}
+		return coordinates.NewCoordinates(c), true
+	}
+	sh, ok := parseShip(s, g)
+	if !ok {
+		return coordinates.Coordinates{}, false
+	}
+	return sh.inner, true
+}
+
+// setTerrain puts terrain on the field. Islands and reefs
+// should be inside the field and should not overlap.
+func (f *Field) setTerrain(t Terrain) error {
+	kinds := []struct {
+		kind  terrain
+		areas []string
+	}{
+		{kind: terrainIsland, areas: t.Islands},
+		{kind: terrainReef, areas: t.Reefs},
+	}
+	for _, k := range kinds {
+		for _, a := range k.areas {
+			cells, ok := parseArea(a, f.geometry())
+			if !ok {
+				return errorInvalidTerrain
+			}
+			for _, c := range cells.Sorted() {
+				if c.X >= f.size || c.Y >= f.size {
+					return errorOutOfBonds.withCoord(c)
+				}
+				if cur := f.field[c.X][c.Y].terrain; cur != terrainWater && cur != k.kind {
+					return errorInvalidTerrain.withCoord(c)
+				}
+				f.field[c.X][c.Y].terrain = k.kind
+			}
+		}
+	}
+	if !t.isEmpty() {
+		f.terrain = t
+	}
+	return nil
+}
+
+// terrainCells returns all cells with terrain.
+func (f Field) terrainCells() coordinates.Coordinates {
+	cells := coordinates.Coordinates{}
+	for x := range f.field {
+		for y, c := range f.field[x] {
+			if c.terrain != terrainWater {
+				cells.Add(coordinates.Coordinate{X: uint(x), Y: uint(y)})
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,146 @@
+package battlefield
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestParseArea(t *testing.T) {
+	tests := []struct {
+		area   string
+		want   []coordinates.Coordinate
+		wantOK bool
+	}{
+		{area: "B2", want: []coordinates.Coordinate{{X: 1, Y: 1}}, wantOK: true},
+		{area: "A1 B1", want: []coordinates.Coordinate{{X: 0, Y: 0}, {X: 1, Y: 0}}, wantOK: true},
+		{area: "A2+A1", want: []coordinates.Coordinate{{X: 0, Y: 0}, {X: 0, Y: 1}}, wantOK: true},
+		{area: "", wantOK: false},
+		{area: "11", wantOK: false},
+		{area: "A1 B", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.area, func(t *testing.T) {
+			got, ok := parseArea(tt.area, coordinates.Square)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.Sorted())
+			}
+		})
+	}
+}
+
+func TestField_SetTerrain(t *testing.T) {
+	tests := []struct {
+		name    string
+		terrain Terrain
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "success",
+			terrain: Terrain{Islands: []string{"A1 B1", "C3"}, Reefs: []string{"A3+B3"}},
+			want:    "^^. ... ~~^",
+		},
+		{
+			name:    "success, same kind overlaps",
+			terrain: Terrain{Islands: []string{"A1 B1", "B1 C1"}},
+			want:    "^^^ ... ...",
+		},
+		{
+			name: "success, no terrain",
+			want: "... ... ...",
+		},
+		{
+			name:    "error, invalid area",
+			terrain: Terrain{Reefs: []string{"A1 Z"}},
+			wantErr: errorInvalidTerrain,
+		},
+		{
+			name:    "error, out of bonds",
+			terrain: Terrain{Islands: []string{"C3 C4"}},
+			wantErr: errorOutOfBonds.withCoord(coordinates.Coordinate{X: 2, Y: 3}),
+		},
+		{
+			name:    "error, island and reef overlap",
+			terrain: Terrain{Islands: []string{"A1 B1"}, Reefs: []string{"B1 B2"}},
+			wantErr: errorInvalidTerrain.withCoord(coordinates.Coordinate{X: 1, Y: 0}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewField(3)
+			err := f.setTerrain(tt.terrain)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			rows := make([]string, 3)
+			for y := range rows {
+				for x := 0; x < 3; x++ {
+					rows[y] += string(f.field[x][y].symbol(false))
+				}
+			}
+			assert.Equal(t, tt.want, strings.Join(rows, " "))
+			assert.Equal(t, tt.terrain, f.terrain)
+		})
+	}
+}
+
+func TestTerrainByName(t *testing.T) {
+	for name, m := range maps {
+		t.Run(name, func(t *testing.T) {
+			terrain, err := terrainByName(name, m.size)
+			assert.NoError(t, err)
+
+			// maps fit the field and leave room for the classic fleet
+			s := NewService(logrus.New())
+			assert.NoError(t, s.createField(fieldOptions{size: m.size, rules: RulesClassic, terrain: terrain}, caller{}))
+			assert.NoError(t, s.autoPlaceShips(nil, caller{}))
+		})
+	}
+
+	_, err := terrainByName("strait", 8)
+	assert.Equal(t, errorInvalidFieldSize, err)
+	_, err = terrainByName("atlantis", 10)
+	assert.Equal(t, errorUnknownMap, err)
+}
+
+func TestService_Terrain(t *testing.T) {
+	s := NewService(logrus.New())
+	terrain := Terrain{Islands: []string{"B1"}, Reefs: []string{"B3"}}
+	assert.NoError(t, s.createField(fieldOptions{size: 3, terrain: terrain}, caller{}))
+	assert.Equal(t, coordinates.NewCoordinates(
+		coordinates.Coordinate{X: 1, Y: 0},
+		coordinates.Coordinate{X: 1, Y: 2},
+	), s.f.terrainCells())
+
+	// ships can't be placed on terrain, but can be near it
+	err := s.addShipsByCoordinates("A1 C1", caller{})
+	assert.Equal(t, errorShipOnTerrain.withShip(0).withCoord(coordinates.Coordinate{X: 1, Y: 0}), err)
+	assert.NoError(t, s.addShipsByCoordinates("A1 A2,C1 C3", caller{}))
+
+	tests := []struct {
+		coord   string
+		want    shotResult
+		wantErr error
+	}{
+		{coord: "B1", wantErr: errorCellNotShootable.withCoord(coordinates.Coordinate{X: 1, Y: 0})},
+		{coord: "B3", want: shotResult{Terrain: TerrainReef}},
+		{coord: "B3", wantErr: errorCellAlreadyShot.withCoord(coordinates.Coordinate{X: 1, Y: 2})},
+		{coord: "A1", want: shotResult{Knock: true}},
+	}
+	for _, tt := range tests {
+		res, err := s.shot(tt.coord, caller{})
+		assert.Equal(t, tt.wantErr, err, tt.coord)
+		assert.Equal(t, tt.want, res, tt.coord)
+	}
+	assert.Equal(t, 2, s.state().shotCount)
+	assert.Equal(t, &terrain, s.f.snapshot().Terrain)
+}
@@ -0,0 +1,120 @@
+package battlefield
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Symbols of cells of the field view.
+const (
+	symbolWater    = '.'
+	symbolShip     = '#'
+	symbolHit      = 'x'
+	symbolSunk     = 'X'
+	symbolMiss     = 'o'
+	symbolIsland   = '^'
+	symbolReef     = '~'
+	symbolShotReef = '*'
+	symbolMine     = '+'
+	symbolBlast    = '!'
+)
+
+// fieldView is the field as seen by a player.
+type fieldView struct {
+	game    string
+	version uint64
+	grid    string
+	// fog hides ships which are not hit.
+	fog bool
+	// rows are symbols of cells, row by row.
+	rows []string
+}
+
+// symbol returns symbol of the cell, ships and mines are hidden in fog,
+// repaired cells are seen as hits in fog. Terrain and mines which
+// are hit are visible to both sides.
+func (c cell) symbol(fog bool) byte {
+	switch {
+	case c.terrain == terrainIsland:
+		return symbolIsland
+	case c.mine && c.shot:
+		return symbolBlast
+	case c.mine && !fog:
+		return symbolMine
+	case c.repaired && fog:
+		return symbolHit
+	case c.terrain == terrainReef && c.shot:
+		return symbolShotReef
+	case c.terrain == terrainReef:
+		return symbolReef
+	case c.ship != nil && c.ship.aliveCells == 0:
+		return symbolSunk
+	case c.ship != nil && c.shot:
+		return symbolHit
+	case c.ship != nil && !fog:
+		return symbolShip
+	case c.shot:
+		return symbolMiss
+	}
+	return symbolWater
+}
+
+// view returns the field as seen by the caller. Only the owner
+// sees the ships, and only if fog is not requested.
+func (s *Service) view(cl caller, fog bool) fieldView {
+	s.rLock()
+	defer s.RUnlock()
+
+	s.logger.WithField("fog", fog).Debug("Service: view started")
+
+	grid, _, _ := gridByName(s.f.grid)
+	fog = fog || !s.f.isOwnedBy(cl)
+	return fieldView{
+		game:    s.f.id,
+		grid:    grid,
+		version: s.f.version,
+		fog:     fog,
+		rows:    s.f.rows(fog),
+	}
+}
+
+// rows returns symbols of cells of the field, row by row.
+func (f Field) rows(fog bool) []string {
+	rows := make([]string, f.size)
+	row := make([]byte, f.size)
+	for y := range rows {
+		for x := range row {
+			row[x] = f.field[x][y].symbol(fog)
+		}
+		rows[y] = string(row)
+	}
+	return rows
+}
+
+// renderField writes rows of the field view as text with column
+// letters and row numbers. Rows of hex grid are shifted by half a cell
+// per row, so every hex touches its six neighbours.
+func renderField(rows []string, hex bool) []byte {
+	b := &bytes.Buffer{}
+	width := len(strconv.Itoa(len(rows)))
+	fmt.Fprintf(b, "%*s", width, "")
+	for x := range rows {
+		b.WriteByte(' ')
+		b.WriteByte(byte('A' + x))
+	}
+	b.WriteByte('\n')
+	for y, row := range rows {
+		fmt.Fprintf(b, "%*d", width, y+1)
+		if hex {
+			b.WriteString(strings.Repeat(" ", y))
+		}
+		for i := 0; i < len(row); i++ {
+			b.WriteByte(' ')
+			b.WriteByte(row[i])
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
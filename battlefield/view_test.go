@@ -0,0 +1,80 @@
+package battlefield
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCell_Symbol(t *testing.T) {
+	alive := &ship{aliveCells: 1}
+	sunk := &ship{}
+
+	tests := []struct {
+		name    string
+		c       cell
+		want    byte
+		wantFog byte
+	}{
+		{name: "water", c: cell{}, want: '.', wantFog: '.'},
+		{name: "near ship", c: cell{occupied: true}, want: '.', wantFog: '.'},
+		{name: "miss", c: cell{shot: true}, want: 'o', wantFog: 'o'},
+		{name: "ship", c: cell{ship: alive}, want: '#', wantFog: '.'},
+		{name: "hit", c: cell{ship: alive, shot: true}, want: 'x', wantFog: 'x'},
+		{name: "sunk", c: cell{ship: sunk, shot: true}, want: 'X', wantFog: 'X'},
+		{name: "island", c: cell{terrain: terrainIsland}, want: '^', wantFog: '^'},
+		{name: "reef", c: cell{terrain: terrainReef}, want: '~', wantFog: '~'},
+		{name: "shot reef", c: cell{terrain: terrainReef, shot: true}, want: '*', wantFog: '*'},
+		{name: "repaired", c: cell{ship: alive, repaired: true}, want: '#', wantFog: 'x'},
+		{name: "mine", c: cell{mine: true}, want: '+', wantFog: '.'},
+		{name: "blast", c: cell{mine: true, shot: true}, want: '!', wantFog: '!'},
+		{name: "blast on reef", c: cell{mine: true, terrain: terrainReef, shot: true}, want: '!', wantFog: '!'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, string(tt.want), string(tt.c.symbol(false)))
+			assert.Equal(t, string(tt.wantFog), string(tt.c.symbol(true)))
+		})
+	}
+}
+
+func TestService_View(t *testing.T) {
+	alice, bob := caller{player: "alice"}, caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3, terrain: Terrain{Islands: []string{"C1"}}}, alice))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A2,C3 C3", alice))
+	for _, c := range []string{"A1", "B3", "C3"} {
+		_, err := s.shot(c, bob)
+		assert.NoError(t, err)
+	}
+
+	v := s.view(alice, false)
+	assert.Equal(t, fieldView{game: s.f.id, version: s.f.version, grid: GridSquare, rows: []string{"x.^", "#..", ".oX"}}, v)
+	assert.Equal(t, []string{"x.^", "...", ".oX"}, s.view(alice, true).rows)
+	assert.True(t, s.view(bob, false).fog)
+	assert.Equal(t, []string{"x.^", "...", ".oX"}, s.view(bob, false).rows)
+
+	assert.Equal(t, fieldView{grid: GridSquare, rows: []string{}}, NewService(logrus.New()).view(alice, false))
+}
+
+func TestRenderField(t *testing.T) {
+	rows := make([]string, 10)
+	for i := range rows {
+		rows[i] = strings.Repeat(".", 10)
+	}
+	rows[9] = "#" + rows[9][1:]
+
+	lines := strings.Split(string(renderField(rows, false)), "\n")
+	assert.Equal(t, "   A B C D E F G H I J", lines[0])
+	assert.Equal(t, " 1 . . . . . . . . . .", lines[1])
+	assert.Equal(t, "10 # . . . . . . . . .", lines[10])
+	assert.Equal(t, "", lines[11])
+}
+
+func TestRenderField_Hex(t *testing.T) {
+	want := "  A B C\n1 # . .\n2  . ^ .\n3   . . ~\n"
+	assert.Equal(t, want, string(renderField([]string{"#..", ".^.", "..~"}, true)))
+}
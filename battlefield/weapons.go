@@ -0,0 +1,259 @@
+package battlefield
+
+import (
+	"strconv"
+	"strings"
+
+	"my/battleship/coordinates"
+)
+
+// Weapons of the advanced mode.
+const (
+	// WeaponSonar finds ships in the cell and cells touching it without shooting,
+	// the 3x3 area on the square grid.
+	WeaponSonar = "sonar"
+	// WeaponRadar counts cells of ships in the row or in the column.
+	WeaponRadar = "radar"
+	// WeaponBomb shoots at the cell and at cells sharing a side with it.
+	WeaponBomb = "bomb"
+)
+
+// weaponStock is the inventory of every game in the advanced mode.
+var weaponStock = map[string]int{
+	WeaponSonar: 2,
+	WeaponRadar: 1,
+	WeaponBomb:  1,
+}
+
+// newInventory returns weapons of the new game, nil if the advanced mode is off.
+func newInventory(advanced bool) map[string]int {
+	if !advanced {
+		return nil
+	}
+	inv := make(map[string]int, len(weaponStock))
+	for w, n := range weaponStock {
+		inv[w] = n
+	}
+	return inv
+}
+
+// cellShot is the result of the shot at the cell.
+type cellShot struct {
+	coord coordinates.Coordinate
+	shotResult
+}
+
+// cellAt returns the cell moved by dx and dy, false if it's out of
+// the field. Cells wrap around edges of the torus field.
+func (f Field) cellAt(c coordinates.Coordinate, dx, dy int) (coordinates.Coordinate, bool) {
+	x, y := int(c.X)+dx, int(c.Y)+dy
+	if f.wrap {
+		size := int(f.size)
+		x, y = (x+size)%size, (y+size)%size
+	}
+	if x < 0 || y < 0 || uint(x) >= f.size || uint(y) >= f.size {
+		return coordinates.Coordinate{}, false
+	}
+	return coordinates.Coordinate{X: uint(x), Y: uint(y)}, true
+}
+
+// around returns the cell and cells touching it on the field if ring is set,
+// otherwise the cell and cells sharing a side with it, see coordinates.Grid.
+func (f Field) around(c coordinates.Coordinate, ring bool) coordinates.Coordinates {
+	g, cell := f.geometry(), coordinates.NewCoordinates(c)
+	n := g.Neighbours(cell)
+	if ring {
+		n = g.Ring(cell)
+	}
+	return cell.Union(n).Clip(f.size)
+}
+
+// afloat reports if the cell holds a ship and isn't shot yet.
+func (c cell) afloat() bool {
+	return c.ship != nil && !c.shot
+}
+
+// canUse checks if the weapon is left in the inventory. Weapons can't be
+// used until the turn lost on the mine is spent by the shot.
+func (f Field) canUse(weapon string) error {
+	if f.weapons == nil {
+		return errorWeaponsDisabled
+	}
+	if f.turnLost {
+		return errorTurnLost
+	}
+	if f.weapons[weapon] == 0 {
+		return errorWeaponExhausted
+	}
+	return nil
+}
+
+// use takes the weapon from the inventory, the first player who uses
+// it takes the attacker seat and gives the turn to the owner like the
+// shooter does.
+func (f *Field) use(weapon string, cl caller) {
+	f.weapons[weapon]--
+	f.defenderTurn = f.defends()
+	if f.attacker == "" {
+		f.attacker = cl.player
+	}
+}
+
+// parseTarget parses the coordinate on the field.
+func (f Field) parseTarget(coordinate string) (coordinates.Coordinate, error) {
+	c, ok := coordinates.ConvertCoordinate(coordinate)
+	if !ok {
+		return coordinates.Coordinate{}, errorInvalidCoordinate
+	}
+	if c.X >= f.size || c.Y >= f.size {
+		return coordinates.Coordinate{}, errorOutOfBonds.withCoord(c)
+	}
+	return c, nil
+}
+
+// parseLine parses the column letter, e.g. "C", or the row number, e.g. "3",
+// and returns cells of the line on the field of the size.
+func parseLine(s string, size uint) ([]coordinates.Coordinate, bool) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if len(s) == 0 {
+		return nil, false
+	}
+	cells := make([]coordinates.Coordinate, size)
+	if len(s) == 1 && s[0] >= 'A' && s[0] <= 'Z' {
+		x := uint(s[0] - 'A')
+		if x >= size {
+			return nil, false
+		}
+		for y := range cells {
+			cells[y] = coordinates.Coordinate{X: x, Y: uint(y)}
+		}
+		return cells, true
+	}
+	row, err := strconv.ParseUint(s, 10, 0)
+	if err != nil || row < 1 || uint(row) > size {
+		return nil, false
+	}
+	for x := range cells {
+		cells[x] = coordinates.Coordinate{X: uint(x), Y: uint(row - 1)}
+	}
+	return cells, true
+}
+
+// sonar reports if any ship cell which isn't shot yet is in the coordinate
+// or in cells touching it. Cells are not marked as shot.
+func (s *Service) sonar(coordinate string, cl caller) (bool, error) {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("coordinate", coordinate).Debug("Service: sonar started")
+
+	if err := s.canShoot(cl); err != nil {
+		return false, err
+	}
+	if err := s.f.canUse(WeaponSonar); err != nil {
+		return false, err
+	}
+	c, err := s.f.parseTarget(coordinate)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, n := range s.f.around(c, true).Sorted() {
+		if s.f.field[n.X][n.Y].afloat() {
+			found = true
+		}
+	}
+	s.f.use(WeaponSonar, cl)
+	s.f.record(eventSonar, cl, coordinate)
+	s.changed()
+	return found, nil
+}
+
+// radar returns the number of ship cells which aren't shot yet
+// in the row or in the column, see parseLine.
+func (s *Service) radar(line string, cl caller) (int, error) {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("line", line).Debug("Service: radar started")
+
+	if err := s.canShoot(cl); err != nil {
+		return 0, err
+	}
+	if err := s.f.canUse(WeaponRadar); err != nil {
+		return 0, err
+	}
+	cells, ok := parseLine(line, s.f.size)
+	if !ok {
+		return 0, errorInvalidCoordinate
+	}
+
+	n := 0
+	for _, c := range cells {
+		if s.f.field[c.X][c.Y].afloat() {
+			n++
+		}
+	}
+	s.f.use(WeaponRadar, cl)
+	s.f.record(eventRadar, cl, line)
+	s.changed()
+	return n, nil
+}
+
+// bomb shoots at the coordinate and at cells sharing a side with it.
+// Cells already shot, islands and cells out of the field are skipped,
+// the bomb stops when the game is over.
+func (s *Service) bomb(coordinate string, cl caller) ([]cellShot, error) {
+	s.lock()
+	defer s.Unlock()
+
+	s.logger.WithField("coordinate", coordinate).Debug("Service: bomb started")
+
+	if err := s.canShoot(cl); err != nil {
+		return nil, err
+	}
+	if err := s.f.canUse(WeaponBomb); err != nil {
+		return nil, err
+	}
+	c, err := s.f.parseTarget(coordinate)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := coordinates.Coordinates{}
+	for _, n := range s.f.around(c, false).Sorted() {
+		if cell := s.f.field[n.X][n.Y]; cell.shot || cell.terrain == terrainIsland {
+			continue
+		}
+		targets.Add(n)
+	}
+	if targets.Len() == 0 {
+		return nil, errorCellAlreadyShot.withCoord(c)
+	}
+
+	shots := make([]cellShot, 0, targets.Len())
+	end := false
+	for _, t := range targets.Sorted() {
+		res := s.fire(t, cl)
+		shots = append(shots, cellShot{coord: t, shotResult: res})
+		if res.End {
+			end = true
+			break
+		}
+	}
+	if end {
+		s.total.add(s.f)
+	}
+	s.f.use(WeaponBomb, cl)
+	s.f.record(eventBomb, cl, coordinate)
+	s.changed()
+
+	for _, sh := range shots {
+		s.notify().ShotFired(sh.Knock, sh.Destroy)
+	}
+	if end {
+		s.notify().GameFinished()
+	}
+	return shots, nil
+}
@@ -0,0 +1,383 @@
+package battlefield
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/coordinates"
+)
+
+func TestNewInventory(t *testing.T) {
+	assert.Nil(t, newInventory(false))
+
+	inv := newInventory(true)
+	assert.Equal(t, weaponStock, inv)
+	inv[WeaponBomb]--
+	assert.Equal(t, 1, weaponStock[WeaponBomb], "the stock is not changed")
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   []coordinates.Coordinate
+		wantOK bool
+	}{
+		{
+			name:   "column",
+			line:   "b",
+			want:   []coordinates.Coordinate{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}},
+			wantOK: true,
+		},
+		{
+			name:   "row",
+			line:   " 3 ",
+			want:   []coordinates.Coordinate{{X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}},
+			wantOK: true,
+		},
+		{name: "column out of the field", line: "D"},
+		{name: "row out of the field", line: "4"},
+		{name: "zero row", line: "0"},
+		{name: "cell", line: "A1"},
+		{name: "empty", line: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLine(tt.line, 3)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestField_CellAt(t *testing.T) {
+	f := NewField(3)
+	_, ok := f.cellAt(coordinates.Coordinate{X: 0, Y: 0}, -1, 0)
+	assert.False(t, ok)
+	c, ok := f.cellAt(coordinates.Coordinate{X: 1, Y: 1}, 1, -1)
+	assert.True(t, ok)
+	assert.Equal(t, coordinates.Coordinate{X: 2, Y: 0}, c)
+
+	f.wrap = true
+	c, ok = f.cellAt(coordinates.Coordinate{X: 0, Y: 2}, -1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, coordinates.Coordinate{X: 2, Y: 0}, c)
+}
+
+// newWeaponsGame creates the advanced game with ships on the field of the size.
+func newWeaponsGame(t *testing.T, opts fieldOptions, coords string) *Service {
+	s := NewService(logrus.New())
+	opts.advanced = true
+	assert.NoError(t, s.createField(opts, caller{player: "alice"}))
+	assert.NoError(t, s.addShipsByCoordinates(coords, caller{player: "alice"}))
+	return s
+}
+
+func TestService_Sonar(t *testing.T) {
+	bob := caller{player: "bob"}
+	tests := []struct {
+		name      string
+		opts      fieldOptions
+		shots     []string
+		target    string
+		cl        caller
+		want      bool
+		wantErr   error
+		wantStock int
+	}{
+		{
+			name:      "success, ship in the area",
+			target:    "B2",
+			cl:        bob,
+			want:      true,
+			wantStock: 1,
+		},
+		{
+			name:      "success, no ship in the area",
+			target:    "C5",
+			cl:        bob,
+			wantStock: 1,
+		},
+		{
+			name:      "success, shot cells are not found",
+			shots:     []string{"A1", "B1"},
+			target:    "A2",
+			cl:        bob,
+			wantStock: 1,
+		},
+		{
+			name:      "success, area wraps on the torus",
+			opts:      fieldOptions{wrap: true},
+			target:    "E5",
+			cl:        bob,
+			want:      true,
+			wantStock: 1,
+		},
+		{
+			name:      "success, ship in the corner of the area",
+			target:    "C2",
+			cl:        bob,
+			want:      true,
+			wantStock: 1,
+		},
+		{
+			name:      "success, corners are out of the hex area",
+			opts:      fieldOptions{grid: GridHex},
+			target:    "C2",
+			cl:        bob,
+			wantStock: 1,
+		},
+		{
+			name:      "success, hex area",
+			opts:      fieldOptions{grid: GridHex},
+			target:    "A2",
+			cl:        bob,
+			want:      true,
+			wantStock: 1,
+		},
+		{
+			name:      "error, game is over",
+			shots:     []string{"A1", "B1", "E3"},
+			target:    "C5",
+			cl:        bob,
+			wantErr:   errorGameIsOver,
+			wantStock: 2,
+		},
+		{
+			name:      "error, owner can't use weapons",
+			target:    "B2",
+			cl:        caller{player: "alice"},
+			wantErr:   errorNotYourTurn,
+			wantStock: 2,
+		},
+		{
+			name:      "error, out of the field",
+			target:    "F1",
+			cl:        bob,
+			wantErr:   errorOutOfBonds.withCoord(coordinates.Coordinate{X: 5, Y: 0}),
+			wantStock: 2,
+		},
+		{
+			name:      "error, invalid coordinate",
+			target:    "1A",
+			cl:        bob,
+			wantErr:   errorInvalidCoordinate,
+			wantStock: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.size = 5
+			s := newWeaponsGame(t, tt.opts, "A1 B1,E3 E3")
+			for _, c := range tt.shots {
+				_, err := s.shot(c, bob)
+				assert.NoError(t, err)
+			}
+			version := s.f.version
+
+			got, err := s.sonar(tt.target, tt.cl)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantStock, s.f.weapons[WeaponSonar])
+			if err != nil {
+				assert.Equal(t, version, s.f.version)
+				return
+			}
+			// sonar doesn't shoot
+			assert.Equal(t, len(tt.shots), s.state().shotCount)
+			assert.Equal(t, version+1, s.f.version)
+		})
+	}
+}
+
+func TestService_Sonar_Inventory(t *testing.T) {
+	bob := caller{player: "bob"}
+	s := NewService(logrus.New())
+	assert.NoError(t, s.createField(fieldOptions{size: 3}, caller{}))
+	assert.NoError(t, s.addShipsByCoordinates("A1 A1", caller{}))
+	_, err := s.sonar("B2", bob)
+	assert.Equal(t, errorWeaponsDisabled, err)
+	assert.Nil(t, s.state().weapons)
+
+	s = newWeaponsGame(t, fieldOptions{size: 3}, "A1 A1")
+	for i := 0; i < weaponStock[WeaponSonar]; i++ {
+		_, err = s.sonar("B2", bob)
+		assert.NoError(t, err)
+	}
+	_, err = s.sonar("B2", bob)
+	assert.Equal(t, errorWeaponExhausted, err)
+	assert.Equal(t, "bob", s.f.attacker)
+	assert.Equal(t, map[string]int{WeaponSonar: 0, WeaponRadar: 1, WeaponBomb: 1}, s.state().weapons)
+}
+
+func TestService_Radar(t *testing.T) {
+	bob := caller{player: "bob"}
+	tests := []struct {
+		name    string
+		shots   []string
+		line    string
+		want    int
+		wantErr error
+	}{
+		{name: "success, column", line: "A", want: 3},
+		{name: "success, row", line: "1", want: 2},
+		{name: "success, shot cells are not counted", shots: []string{"A2"}, line: "A", want: 2},
+		{name: "success, nothing found", line: "C"},
+		{name: "error, game is over", shots: []string{"A1", "A2", "A3", "E1"}, line: "C", wantErr: errorGameIsOver},
+		{name: "error, out of the field", line: "F", wantErr: errorInvalidCoordinate},
+		{name: "error, cell", line: "A1", wantErr: errorInvalidCoordinate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newWeaponsGame(t, fieldOptions{size: 5}, "A1 A3,E1 E1")
+			for _, c := range tt.shots {
+				_, err := s.shot(c, bob)
+				assert.NoError(t, err)
+			}
+
+			got, err := s.radar(tt.line, bob)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.shots), s.state().shotCount)
+		})
+	}
+}
+
+func TestService_Bomb(t *testing.T) {
+	bob := caller{player: "bob"}
+	tests := []struct {
+		name    string
+		opts    fieldOptions
+		shots   []string
+		target  string
+		want    []cellShot
+		wantErr error
+	}{
+		{
+			name:   "success, plus shape",
+			target: "B3",
+			want: []cellShot{
+				{coord: coord("B2")},
+				{coord: coord("A3"), shotResult: shotResult{Knock: true}},
+				{coord: coord("B3")},
+				{coord: coord("C3")},
+				{coord: coord("B4")},
+			},
+		},
+		{
+			name:   "success, edges clip the plus",
+			target: "A1",
+			want: []cellShot{
+				{coord: coord("A1")},
+				{coord: coord("B1")},
+				{coord: coord("A2"), shotResult: shotResult{Knock: true}},
+			},
+		},
+		{
+			name:   "success, plus wraps on the torus",
+			opts:   fieldOptions{wrap: true},
+			target: "A1",
+			want: []cellShot{
+				{coord: coord("A1")},
+				{coord: coord("B1")},
+				{coord: coord("E1")},
+				{coord: coord("A2"), shotResult: shotResult{Knock: true}},
+				{coord: coord("A5")},
+			},
+		},
+		{
+			name:   "success, hex neighbours",
+			opts:   fieldOptions{grid: GridHex},
+			target: "B3",
+			want: []cellShot{
+				{coord: coord("B2")},
+				{coord: coord("C2")},
+				{coord: coord("A3"), shotResult: shotResult{Knock: true}},
+				{coord: coord("B3")},
+				{coord: coord("C3")},
+				{coord: coord("A4"), shotResult: shotResult{Knock: true}},
+				{coord: coord("B4")},
+			},
+		},
+		{
+			name:   "success, shot cells and islands are skipped",
+			opts:   fieldOptions{terrain: Terrain{Islands: []string{"C3"}, Reefs: []string{"B4"}}},
+			shots:  []string{"B2"},
+			target: "B3",
+			want: []cellShot{
+				{coord: coord("A3"), shotResult: shotResult{Knock: true}},
+				{coord: coord("B3")},
+				{coord: coord("B4"), shotResult: shotResult{Terrain: TerrainReef}},
+			},
+		},
+		{
+			name:   "success, the bomb stops when the game is over",
+			shots:  []string{"A2", "A3", "A4"},
+			target: "D2",
+			want: []cellShot{
+				{coord: coord("D1"), shotResult: shotResult{Knock: true, Destroy: true, End: true}},
+			},
+		},
+		{
+			name:    "error, game is over",
+			shots:   []string{"A2", "A3", "A4", "D1"},
+			target:  "C3",
+			wantErr: errorGameIsOver,
+		},
+		{
+			name:    "error, nothing to shoot",
+			shots:   []string{"D1", "C2", "D2", "E2", "D3"},
+			target:  "D2",
+			wantErr: errorCellAlreadyShot.withCoord(coord("D2")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.size = 5
+			s := newWeaponsGame(t, tt.opts, "A2 A4,D1 D1")
+			for _, c := range tt.shots {
+				_, err := s.shot(c, bob)
+				assert.NoError(t, err)
+			}
+
+			got, err := s.bomb(tt.target, bob)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+			if err != nil {
+				assert.Equal(t, 1, s.f.weapons[WeaponBomb])
+				return
+			}
+			assert.Equal(t, 0, s.f.weapons[WeaponBomb])
+			// every cell is counted like the shot
+			assert.Equal(t, len(tt.shots)+len(tt.want), s.state().shotCount)
+			for _, sh := range tt.want {
+				assert.True(t, s.f.field[sh.coord.X][sh.coord.Y].shot)
+			}
+		})
+	}
+}
+
+func TestService_Weapons_Replay(t *testing.T) {
+	bob := caller{player: "bob"}
+	s := newWeaponsGame(t, fieldOptions{size: 5, seed: 7}, "A2 A4,E1 E1")
+	_, err := s.sonar("B3", bob)
+	assert.NoError(t, err)
+	_, err = s.radar("A", bob)
+	assert.NoError(t, err)
+	_, err = s.bomb("B3", bob)
+	assert.NoError(t, err)
+
+	snap := s.f.snapshot()
+	assert.True(t, snap.Advanced)
+	f, err := replay(logrus.New(), snap)
+	assert.NoError(t, err)
+	assert.Equal(t, s.f.weapons, f.weapons)
+	assert.Equal(t, s.f.state, f.state)
+	assert.Equal(t, "bob", f.attacker)
+}
@@ -0,0 +1,303 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"my/battleship/battlefield"
+)
+
+// Client calls the battleship server API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	apiKey  string
+	token   string
+}
+
+// Option configures the Client.
+type Option func(c *Client)
+
+// WithHTTPClient sets HTTP client used for requests, http.DefaultClient is used by default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithAPIKey authenticates requests with static API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithToken authenticates requests with signed bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New creates new Client of the server at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// CreateField creates new battlefield with provided size.
+func (c *Client) CreateField(ctx context.Context, size uint) error {
+	return c.CreateFieldWith(ctx, battlefield.CreateFieldRequest{Size: size})
+}
+
+// CreateFieldWith creates the battlefield with all options of the request,
+// e.g. rules, grid or weapons.
+func (c *Client) CreateFieldWith(ctx context.Context, r battlefield.CreateFieldRequest) error {
+	return c.do(ctx, http.MethodPost, "/create-matrix", r, nil)
+}
+
+// AddShips adds ships to the battlefield, e.g. "A1 B2,C4 C6".
+func (c *Client) AddShips(ctx context.Context, coords string) error {
+	return c.do(ctx, http.MethodPost, "/ship", battlefield.AddShipsRequest{Coords: coords}, nil)
+}
+
+// AutoPlaceShips places ships of the lengths randomly,
+// the fleet of the rules is used if lengths are empty.
+func (c *Client) AutoPlaceShips(ctx context.Context, lengths ...int) error {
+	return c.do(ctx, http.MethodPost, "/ship/auto", battlefield.AutoPlaceShipsRequest{Ships: lengths}, nil)
+}
+
+// ValidateShips checks ships placement without adding ships to the battlefield.
+func (c *Client) ValidateShips(ctx context.Context, coords string) (battlefield.ValidateShipsResponse, error) {
+	resp := battlefield.ValidateShipsResponse{}
+	err := c.do(ctx, http.MethodPost, "/ship/validate", battlefield.AddShipsRequest{Coords: coords}, &resp)
+	return resp, err
+}
+
+// ExportShips returns layout of placed ships, requires board ownership.
+func (c *Client) ExportShips(ctx context.Context) (battlefield.Layout, error) {
+	resp := battlefield.Layout{}
+	err := c.do(ctx, http.MethodGet, "/ship/export", nil, &resp)
+	return resp, err
+}
+
+// ImportShips adds ships from the layout to the battlefield.
+func (c *Client) ImportShips(ctx context.Context, l battlefield.Layout) error {
+	return c.do(ctx, http.MethodPost, "/ship/import", l, nil)
+}
+
+// MoveShip moves the ship with the index by one cell in the direction, e.g. "up".
+func (c *Client) MoveShip(ctx context.Context, id int, direction string) (battlefield.MoveShipResponse, error) {
+	resp := battlefield.MoveShipResponse{}
+	path := "/ship/" + strconv.Itoa(id) + "/move"
+	err := c.do(ctx, http.MethodPost, path, battlefield.MoveShipRequest{Direction: direction}, &resp)
+	return resp, err
+}
+
+// RepairShip repairs the hit cell of the ship with the index, e.g. "A1",
+// and returns repairs left for the ship.
+func (c *Client) RepairShip(ctx context.Context, id int, coord string) (battlefield.RepairShipResponse, error) {
+	resp := battlefield.RepairShipResponse{}
+	path := "/ship/" + strconv.Itoa(id) + "/repair"
+	err := c.do(ctx, http.MethodPost, path, battlefield.RepairShipRequest{Coord: coord}, &resp)
+	return resp, err
+}
+
+// PauseGame pauses the game with the identifier.
+func (c *Client) PauseGame(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/games/"+url.PathEscape(id)+"/pause", nil, nil)
+}
+
+// ResumeGame resumes the paused game with the identifier.
+func (c *Client) ResumeGame(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/games/"+url.PathEscape(id)+"/resume", nil, nil)
+}
+
+// PausedGames lists paused games of the player.
+func (c *Client) PausedGames(ctx context.Context) (battlefield.PausedGamesResponse, error) {
+	resp := battlefield.PausedGamesResponse{}
+	err := c.do(ctx, http.MethodGet, "/games/paused", nil, &resp)
+	return resp, err
+}
+
+// History lists archived games matching the request.
+func (c *Client) History(ctx context.Context, r battlefield.HistoryRequest) (battlefield.HistoryResponse, error) {
+	q := url.Values{}
+	for k, v := range map[string]string{"player": r.Player, "rules": r.Rules, "outcome": r.Outcome} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if !r.From.IsZero() {
+		q.Set("from", r.From.Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		q.Set("to", r.To.Format(time.RFC3339))
+	}
+	if r.Offset != 0 {
+		q.Set("offset", strconv.Itoa(r.Offset))
+	}
+	if r.Limit != 0 {
+		q.Set("limit", strconv.Itoa(r.Limit))
+	}
+	path := "/history"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	resp := battlefield.HistoryResponse{}
+	err := c.do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// HistoryRecord returns the archived game with the identifier.
+func (c *Client) HistoryRecord(ctx context.Context, id string) (battlefield.HistoryRecordResponse, error) {
+	resp := battlefield.HistoryRecordResponse{}
+	err := c.do(ctx, http.MethodGet, "/history/"+url.PathEscape(id), nil, &resp)
+	return resp, err
+}
+
+// PlaceMines hides mines in cells without ships, e.g. "C3,E5".
+func (c *Client) PlaceMines(ctx context.Context, coords string) error {
+	return c.do(ctx, http.MethodPost, "/mines", battlefield.PlaceMinesRequest{Coords: coords}, nil)
+}
+
+// Shot makes a shot to the coordinate, e.g. "A1".
+func (c *Client) Shot(ctx context.Context, coord string) (battlefield.ShotResponse, error) {
+	resp := battlefield.ShotResponse{}
+	err := c.do(ctx, http.MethodPost, "/shot", battlefield.ShotRequest{Coord: coord}, &resp)
+	return resp, err
+}
+
+// Sonar pings the 3x3 area around the coordinate, e.g. "B2".
+func (c *Client) Sonar(ctx context.Context, coord string) (battlefield.SonarResponse, error) {
+	resp := battlefield.SonarResponse{}
+	err := c.do(ctx, http.MethodPost, "/weapon/sonar", battlefield.ShotRequest{Coord: coord}, &resp)
+	return resp, err
+}
+
+// Radar scans the column, e.g. "C", or the row, e.g. "3".
+func (c *Client) Radar(ctx context.Context, line string) (battlefield.RadarResponse, error) {
+	resp := battlefield.RadarResponse{}
+	err := c.do(ctx, http.MethodPost, "/weapon/radar", battlefield.RadarRequest{Line: line}, &resp)
+	return resp, err
+}
+
+// Bomb drops the plus-shaped bomb at the coordinate, e.g. "B2".
+func (c *Client) Bomb(ctx context.Context, coord string) (battlefield.BombResponse, error) {
+	resp := battlefield.BombResponse{}
+	err := c.do(ctx, http.MethodPost, "/weapon/bomb", battlefield.ShotRequest{Coord: coord}, &resp)
+	return resp, err
+}
+
+// State returns the state of current game.
+func (c *Client) State(ctx context.Context) (battlefield.StateResponse, error) {
+	resp := battlefield.StateResponse{}
+	err := c.do(ctx, http.MethodGet, "/state", nil, &resp)
+	return resp, err
+}
+
+// Field returns the field as seen by the player,
+// fog hides ships from the owner too.
+func (c *Client) Field(ctx context.Context, fog bool) (battlefield.FieldResponse, error) {
+	path := "/field"
+	if fog {
+		path += "?fog=true"
+	}
+	resp := battlefield.FieldResponse{}
+	err := c.do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// Stats returns statistics of the current and finished games.
+func (c *Client) Stats(ctx context.Context) (battlefield.StatsResponse, error) {
+	resp := battlefield.StatsResponse{}
+	err := c.do(ctx, http.MethodGet, "/stats", nil, &resp)
+	return resp, err
+}
+
+// Heatmap returns heatmap of finished games of the field size,
+// size of the current field is used if size is zero.
+func (c *Client) Heatmap(ctx context.Context, size uint) (battlefield.HeatmapResponse, error) {
+	path := "/stats/heatmap"
+	if size != 0 {
+		path += "?size=" + strconv.FormatUint(uint64(size), 10)
+	}
+	resp := battlefield.HeatmapResponse{}
+	err := c.do(ctx, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+// Clear clears the battlefield, requires admin role.
+func (c *Client) Clear(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/clear", nil, nil)
+}
+
+// do sends request with JSON encoded body and decodes JSON response into resp.
+// Error responses are returned as battlefield.HTTPError.
+func (c *Client) do(ctx context.Context, method, path string, body, resp interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return decodeError(res)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
+
+func decodeError(res *http.Response) error {
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	e := battlefield.HTTPError{}
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") ||
+		json.Unmarshal(b, &e) != nil || e.Err == "" {
+		e.Err = strings.TrimSpace(string(b))
+	}
+	e.Code = res.StatusCode
+	return e
+}
@@ -0,0 +1,386 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"my/battleship/battlefield"
+	"my/battleship/storage"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	srv, _ := newServerWithService(t)
+	return srv
+}
+
+// newServerWithService returns the server and its Service,
+// e.g. to flush the game.
+func newServerWithService(t *testing.T) (*httptest.Server, *battlefield.Service) {
+	l := logrus.New()
+	keys := map[string]battlefield.Principal{
+		"alice": {Player: "alice", Role: battlefield.RolePlayer},
+		"bob":   {Player: "bob", Role: battlefield.RolePlayer},
+		"carol": {Player: "carol", Role: battlefield.RolePlayer},
+		"root":  {Player: "root", Role: battlefield.RoleAdmin},
+	}
+
+	s := battlefield.NewService(l)
+	s.SetStore(storage.NewMemory())
+	h := battlefield.NewHandlers(l, battlefield.NewEndpoints(l, s))
+
+	r := mux.NewRouter()
+	r.Use(battlefield.NewAuthenticator(l, keys, nil).Middleware)
+	h.Register(r)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, s
+}
+
+func TestClient(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	alice := New(srv.URL, WithAPIKey("alice"), WithHTTPClient(srv.Client()))
+	bob := New(srv.URL+"/", WithAPIKey("bob"))
+	root := New(srv.URL, WithAPIKey("root"))
+
+	assert.NoError(t, alice.CreateField(ctx, 3))
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeFieldAlreadySet,
+		Err:     "field is already set",
+		Code:    http.StatusConflict,
+	}, bob.CreateField(ctx, 3))
+
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeNotBoardOwner,
+		Err:     "only board owner can do this",
+		Code:    http.StatusForbidden,
+	}, bob.AddShips(ctx, "A1 A1"))
+	v, err := alice.ValidateShips(ctx, "A1 A2,B2 B2")
+	assert.NoError(t, err)
+	assert.False(t, v.Valid)
+	assert.Len(t, v.Problems, 1)
+	assert.Equal(t, battlefield.CodeCellOccupiedNearby, v.Problems[0].ErrCode)
+	assert.Equal(t, "B2", v.Problems[0].Details.Coord)
+
+	assert.NoError(t, alice.AddShips(ctx, "A1 A2,C3 C3"))
+
+	layout, err := alice.ExportShips(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.Layout{Size: 3, Rules: battlefield.RulesFree, Ships: []string{"A1 A2", "C3 C3"}}, layout)
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeShipsAlreadyAdded,
+		Err:     "ships are already added",
+		Code:    http.StatusBadRequest,
+	}, alice.ImportShips(ctx, layout))
+
+	res, err := bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.ShotResponse{Knock: true}, res)
+
+	res, err = bob.Shot(ctx, "A2")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.ShotResponse{Knock: true, Destroy: true}, res)
+
+	_, err = bob.Shot(ctx, "A2")
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeCellAlreadyShot,
+		Err:     "cell was already shot",
+		Details: &battlefield.ErrorDetails{Coord: "A2"},
+		Code:    http.StatusBadRequest,
+	}, err)
+
+	state, err := alice.State(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, state.Game)
+	state.Game, state.Seed = "", 0
+	assert.Equal(t, battlefield.StateResponse{Version: 4, ShipCount: 2, Destroyed: 1, ShotCount: 2}, state)
+
+	field, err := alice.Field(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"X..", "X..", "..#"}, field.Rows)
+	field, err = bob.Field(ctx, false)
+	assert.NoError(t, err)
+	assert.True(t, field.Fog)
+	assert.Equal(t, []string{"X..", "X..", "..."}, field.Rows)
+
+	res, err = bob.Shot(ctx, "C3")
+	assert.NoError(t, err)
+	assert.True(t, res.End)
+
+	stats, err := alice.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Games)
+	assert.Equal(t, 3, stats.Game.Hits)
+	assert.Equal(t, battlefield.PlayerStats{Games: 1, ShotStats: *stats.Game}, stats.Players["bob"])
+
+	heatmap, err := alice.Heatmap(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.HeatmapResponse{
+		Size:  3,
+		Games: 1,
+		Shots: [][]int{{1, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+		Hits:  [][]int{{1, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+	}, heatmap)
+
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeAdminRequired,
+		Err:     "admin role required",
+		Code:    http.StatusForbidden,
+	}, alice.Clear(ctx))
+	assert.NoError(t, root.Clear(ctx))
+
+	_, err = New(srv.URL).State(ctx)
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeUnauthorized,
+		Err:     "authentication required",
+		Code:    http.StatusUnauthorized,
+	}, err)
+}
+
+func TestClient_AutoPlaceShips(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+
+	assert.NoError(t, alice.CreateField(ctx, 3))
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeFleetDoesNotFit,
+		Err:     "ships don't fit the field",
+		Code:    http.StatusBadRequest,
+	}, alice.AutoPlaceShips(ctx, 4))
+	assert.NoError(t, alice.AutoPlaceShips(ctx, 2, 1))
+
+	state, err := alice.State(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, state.ShipCount)
+}
+
+func TestClient_Weapons(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	assert.NoError(t, alice.CreateFieldWith(ctx, battlefield.CreateFieldRequest{Size: 5, Advanced: true}))
+	assert.NoError(t, alice.AddShips(ctx, "A1 A2,E5 E5"))
+
+	sonar, err := bob.Sonar(ctx, "C3")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.SonarResponse{Found: false}, sonar)
+
+	radar, err := bob.Radar(ctx, "A")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.RadarResponse{Cells: 2}, radar)
+	_, err = bob.Radar(ctx, "5")
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeWeaponExhausted,
+		Err:     "no weapon left",
+		Code:    http.StatusBadRequest,
+	}, err)
+
+	bomb, err := bob.Bomb(ctx, "A1")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.BombResponse{Shots: []battlefield.BombShot{
+		{Coord: "A1", Knock: true},
+		{Coord: "B1"},
+		{Coord: "A2", Knock: true, Destroy: true},
+	}}, bomb)
+
+	state, err := alice.State(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, state.ShotCount)
+	assert.Equal(t, 1, state.Destroyed)
+	assert.Equal(t, map[string]int{battlefield.WeaponSonar: 1, battlefield.WeaponRadar: 0, battlefield.WeaponBomb: 0}, state.Weapons)
+}
+
+func TestClient_MoveShip(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	assert.NoError(t, alice.CreateFieldWith(ctx, battlefield.CreateFieldRequest{Size: 5, Moving: true}))
+	assert.NoError(t, alice.AddShips(ctx, "A1 B1,E5 E5"))
+	_, err := alice.MoveShip(ctx, 0, battlefield.DirectionDown)
+	assert.Equal(t, battlefield.CodeNotYourTurn, err.(battlefield.HTTPError).ErrCode)
+
+	_, err = bob.Shot(ctx, "C3")
+	assert.NoError(t, err)
+	moved, err := alice.MoveShip(ctx, 0, battlefield.DirectionDown)
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.MoveShipResponse{Ship: "A2 B2"}, moved)
+
+	_, err = bob.Shot(ctx, "C4")
+	assert.NoError(t, err)
+	_, err = alice.MoveShip(ctx, 5, battlefield.DirectionDown)
+	assert.Equal(t, battlefield.HTTPError{
+		ErrCode: battlefield.CodeShipNotFound,
+		Err:     "ship not found",
+		Code:    http.StatusNotFound,
+	}, err)
+}
+
+func TestClient_RepairShip(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	assert.NoError(t, alice.CreateFieldWith(ctx, battlefield.CreateFieldRequest{Size: 5, Repairs: 1}))
+	assert.NoError(t, alice.AddShips(ctx, "A1 B1,E5 E5"))
+	res, err := bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+
+	repaired, err := alice.RepairShip(ctx, 0, "A1")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.RepairShipResponse{Repairs: 0}, repaired)
+	st, err := bob.State(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, st.Knocked)
+
+	// the attacker learns about the repair by shooting the cell again
+	res, err = bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+	_, err = alice.RepairShip(ctx, 0, "A1")
+	assert.Equal(t, battlefield.CodeRepairsExhausted, err.(battlefield.HTTPError).ErrCode)
+}
+
+func TestClient_PauseGame(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	assert.NoError(t, alice.CreateField(ctx, 3))
+	assert.NoError(t, alice.AddShips(ctx, "A1 B1"))
+	_, err := bob.Shot(ctx, "C3")
+	assert.NoError(t, err)
+	st, err := bob.State(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, bob.PauseGame(ctx, st.Game))
+	_, err = bob.Shot(ctx, "A1")
+	assert.Equal(t, battlefield.CodeGamePaused, err.(battlefield.HTTPError).ErrCode)
+	paused, err := alice.PausedGames(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, paused.Games, 1) {
+		assert.Equal(t, st.Game, paused.Games[0].ID)
+		assert.Equal(t, "bob", paused.Games[0].Attacker)
+	}
+
+	err = alice.ResumeGame(ctx, "0123456789abcdef")
+	assert.Equal(t, battlefield.CodeGameNotFound, err.(battlefield.HTTPError).ErrCode)
+	assert.NoError(t, alice.ResumeGame(ctx, st.Game))
+	_, err = bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+}
+
+func TestClient_History(t *testing.T) {
+	srv, s := newServerWithService(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	assert.NoError(t, alice.CreateFieldWith(ctx, battlefield.CreateFieldRequest{Size: 3, Rules: battlefield.RulesFree}))
+	assert.NoError(t, alice.AddShips(ctx, "A1 A1"))
+	st, err := bob.State(ctx)
+	assert.NoError(t, err)
+	_, err = bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Flush())
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	history, err := alice.History(ctx, battlefield.HistoryRequest{
+		Player:  "bob",
+		From:    day,
+		To:      day.Add(24 * time.Hour),
+		Rules:   battlefield.RulesFree,
+		Outcome: battlefield.OutcomeWon,
+		Limit:   1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, history.Total)
+	if assert.Len(t, history.Games, 1) {
+		assert.Equal(t, st.Game, history.Games[0].ID)
+	}
+	history, err = alice.History(ctx, battlefield.HistoryRequest{Offset: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, history.Total)
+	assert.Empty(t, history.Games)
+
+	record, err := bob.HistoryRecord(ctx, st.Game)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"X..", "...", "..."}, record.Board)
+	assert.Len(t, record.Log, 2)
+	_, err = bob.HistoryRecord(ctx, "0123456789abcdef")
+	assert.Equal(t, battlefield.CodeGameNotFound, err.(battlefield.HTTPError).ErrCode)
+
+	// games of other players are hidden
+	carol := New(srv.URL, WithAPIKey("carol"))
+	history, err = carol.History(ctx, battlefield.HistoryRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, history.Total)
+	_, err = carol.HistoryRecord(ctx, st.Game)
+	assert.Equal(t, battlefield.CodeNotGamePlayer, err.(battlefield.HTTPError).ErrCode)
+}
+
+func TestClient_Mines(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+	alice := New(srv.URL, WithAPIKey("alice"))
+	bob := New(srv.URL, WithAPIKey("bob"))
+
+	req := battlefield.CreateFieldRequest{Size: 5, Mines: 1, MinePenalty: battlefield.MinePenaltySkip}
+	assert.NoError(t, alice.CreateFieldWith(ctx, req))
+	assert.NoError(t, alice.AddShips(ctx, "A1 B1"))
+	assert.NoError(t, alice.PlaceMines(ctx, "C3"))
+
+	res, err := bob.Shot(ctx, "C3")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.ShotResponse{Mine: true, Penalty: battlefield.MinePenaltySkip}, res)
+
+	res, err = bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.Equal(t, battlefield.ShotResponse{Skipped: true}, res)
+	res, err = bob.Shot(ctx, "A1")
+	assert.NoError(t, err)
+	assert.True(t, res.Knock)
+
+	st, err := bob.State(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, st.MineCount)
+	assert.Equal(t, 1, st.MinesHit)
+}
+
+func TestClient_PlainTextError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).State(context.Background())
+	assert.Equal(t, battlefield.HTTPError{
+		Err:  "something went wrong",
+		Code: http.StatusInternalServerError,
+	}, err)
+}
+
+func TestClient_Canceled(t *testing.T) {
+	srv := newServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(srv.URL, WithAPIKey("alice")).State(ctx)
+	assert.Error(t, err)
+}
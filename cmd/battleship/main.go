@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"github.com/swaggo/http-swagger"
 
 	_ "my/battleship/docs"
 
 	"my/battleship/battlefield"
+	"my/battleship/config"
+	"my/battleship/metrics"
+	"my/battleship/storage"
 )
 
 // @title Swagger Example API
@@ -22,27 +33,160 @@ import (
 // @host localhost:8080
 // @BasePath /
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 func main() {
 	log := logrus.New()
 
+	cfg, printConfig, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalf("can't load config: %v", err)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("can't print config: %v", err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// validated above
+	level, _ := logrus.ParseLevel(cfg.Log.Level)
+	log.SetLevel(level)
+	if cfg.Log.Format == config.LogFormatJSON {
+		log.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	m := metrics.New(prometheus.DefaultRegisterer)
+
 	bs := battlefield.NewService(log)
+	bs.SetObserver(m)
+	if err := bs.SetMaxFieldSize(cfg.MaxFieldSize); err != nil {
+		log.Fatalf("can't set max field size: %v", err)
+	}
+	store, err := newStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("can't open storage: %v", err)
+	}
+	bs.SetStore(store)
+	if err := bs.Restore(); err != nil {
+		log.Fatalf("can't restore game: %v", err)
+	}
 	be := battlefield.NewEndpoints(log, bs)
 	bh := battlefield.NewHandlers(log, be)
+	health := battlefield.NewHealth(log, bs.Ping)
 
 	router := mux.NewRouter()
+	router.Use(m.Middleware)
+
+	// metrics
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+
+	// probes
+	router.HandleFunc("/healthz", health.Live).Methods("GET")
+	router.HandleFunc("/readyz", health.Ready).Methods("GET")
 
 	// swagger
-	router.PathPrefix("/swagger/").
-		Handler(httpSwagger.Handler(httpSwagger.URL("http://localhost:8080/swagger/doc.json"))).
-		Methods("GET")
+	if cfg.Swagger {
+		router.PathPrefix("/swagger/").
+			Handler(httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json"))).
+			Methods("GET")
+	}
 
 	// API
-	router.HandleFunc("/create-matrix", bh.CreateBattleField).Methods("POST")
-	router.HandleFunc("/clear", bh.ClearBattleField).Methods("POST")
-	router.HandleFunc("/ship", bh.AddShips).Methods("POST")
-	router.HandleFunc("/shot", bh.Shot).Methods("POST")
-	router.HandleFunc("/state", bh.State).Methods("GET")
-
-	log.Infof("listening at :8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	keys, err := battlefield.ParseAPIKeys(cfg.Auth.APIKeys)
+	if err != nil {
+		log.Fatalf("can't parse API keys: %v", err)
+	}
+	secret := []byte(cfg.Auth.TokenSecret)
+
+	api := router.NewRoute().Subrouter()
+	if len(keys) > 0 || len(secret) > 0 {
+		api.Use(battlefield.NewAuthenticator(log, keys, secret).Middleware)
+	} else {
+		log.Warn("AUTHENTICATION IS DISABLED")
+	}
+	limiter := battlefield.NewRateLimiter(
+		log,
+		battlefield.Limit{Rate: cfg.Limits.WriteRate, Burst: cfg.Limits.WriteBurst},
+		battlefield.Limit{Rate: cfg.Limits.ReadRate, Burst: cfg.Limits.ReadBurst},
+	)
+	api.Use(limiter.Middleware)
+	api.Use(battlefield.LimitBody(cfg.Limits.MaxBodySize))
+	idempotency := battlefield.NewIdempotency(
+		log,
+		bs.GameID,
+		cfg.Idempotency.Size,
+		time.Duration(cfg.Idempotency.TTL),
+	)
+	api.Use(idempotency.Middleware)
+
+	bh.Register(api)
+
+	srv := &http.Server{Addr: cfg.Listen, Handler: router}
+	go func() {
+		log.Infof("listening at %s", cfg.Listen)
+		var err error
+		if cfg.TLS.Cert != "" {
+			err = srv.ListenAndServeTLS(cfg.TLS.Cert, cfg.TLS.Key)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	ticker := time.NewTicker(time.Duration(cfg.Storage.FlushInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := bs.Flush(); err != nil {
+				log.Errorf("can't flush game: %v", err)
+			}
+			if cfg.Games.PauseTTL > 0 {
+				if _, err := bs.ArchivePaused(time.Duration(cfg.Games.PauseTTL)); err != nil {
+					log.Errorf("can't archive paused games: %v", err)
+				}
+			}
+		case sig := <-stop:
+			log.Infof("%s RECEIVED, SHUTTING DOWN", sig)
+			health.Drain()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Errorf("can't finish in-flight requests: %v", err)
+			}
+			cancel()
+
+			if err := bs.Flush(); err != nil {
+				log.Fatalf("can't flush game: %v", err)
+			}
+			log.Info("SERVER STOPPED")
+			return
+		}
+	}
+}
+
+func newStore(cfg config.Storage) (storage.Store, error) {
+	if cfg.Backend == config.StorageFile {
+		return storage.NewFile(cfg.Path)
+	}
+	return storage.NewMemory(), nil
 }
@@ -0,0 +1,427 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"my/battleship/coordinates"
+)
+
+// Storage backends supported by the server.
+const (
+	StorageMemory = "memory"
+	StorageFile   = "file"
+)
+
+// Log formats supported by the server.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// redacted replaces secrets in printed config.
+const redacted = "<redacted>"
+
+// Config contains all server settings.
+type Config struct {
+	Listen       string      `yaml:"listen" json:"listen"`
+	TLS          TLS         `yaml:"tls" json:"tls"`
+	Log          Log         `yaml:"log" json:"log"`
+	MaxFieldSize uint        `yaml:"max_field_size" json:"max_field_size"`
+	Storage      Storage     `yaml:"storage" json:"storage"`
+	Swagger      bool        `yaml:"swagger" json:"swagger"`
+	Auth         Auth        `yaml:"auth" json:"auth"`
+	Limits       Limits      `yaml:"limits" json:"limits"`
+	Idempotency  Idempotency `yaml:"idempotency" json:"idempotency"`
+	Games        Games       `yaml:"games" json:"games"`
+	// ShutdownTimeout limits time to finish in-flight requests on shutdown.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+}
+
+// TLS contains paths to certificate and key. TLS is disabled if both are empty.
+type TLS struct {
+	Cert string `yaml:"cert" json:"cert"`
+	Key  string `yaml:"key" json:"key"`
+}
+
+// Log contains logger settings.
+type Log struct {
+	Level  string `yaml:"level" json:"level"`
+	Format string `yaml:"format" json:"format"`
+}
+
+// Storage contains game state storage settings.
+// Path is the data directory of file backend.
+// Changes are saved to the storage every FlushInterval and on shutdown.
+type Storage struct {
+	Backend       string   `yaml:"backend" json:"backend"`
+	Path          string   `yaml:"path" json:"path"`
+	FlushInterval Duration `yaml:"flush_interval" json:"flush_interval"`
+}
+
+// Auth contains authentication settings.
+// Authentication is disabled if both are empty.
+type Auth struct {
+	APIKeys     string `yaml:"api_keys" json:"api_keys"`
+	TokenSecret string `yaml:"token_secret" json:"token_secret"`
+}
+
+// Limits contains rate and request size limits.
+type Limits struct {
+	WriteRate   float64 `yaml:"write_rate" json:"write_rate"`
+	WriteBurst  int     `yaml:"write_burst" json:"write_burst"`
+	ReadRate    float64 `yaml:"read_rate" json:"read_rate"`
+	ReadBurst   int     `yaml:"read_burst" json:"read_burst"`
+	MaxBodySize int64   `yaml:"max_body_size" json:"max_body_size"`
+}
+
+// Idempotency contains settings of Idempotency-Key cache.
+// At most Size keys are remembered for TTL, zero Size disables the cache.
+type Idempotency struct {
+	Size int      `yaml:"size" json:"size"`
+	TTL  Duration `yaml:"ttl" json:"ttl"`
+}
+
+// Games contains game lifecycle settings.
+// Games paused for longer than PauseTTL are archived, zero PauseTTL keeps them forever.
+type Games struct {
+	PauseTTL Duration `yaml:"pause_ttl" json:"pause_ttl"`
+}
+
+// Default returns config with default values.
+func Default() Config {
+	return Config{
+		Listen: ":8080",
+		Log: Log{
+			Level:  "info",
+			Format: LogFormatText,
+		},
+		MaxFieldSize: coordinates.MaxSize,
+		Storage: Storage{
+			Backend:       StorageMemory,
+			Path:          "data",
+			FlushInterval: Duration(5 * time.Second),
+		},
+		Swagger: true,
+		Limits: Limits{
+			WriteRate:   5,
+			WriteBurst:  10,
+			ReadRate:    20,
+			ReadBurst:   40,
+			MaxBodySize: 64 << 10,
+		},
+		Idempotency: Idempotency{
+			Size: 10000,
+			TTL:  Duration(time.Hour),
+		},
+		Games: Games{
+			PauseTTL: Duration(30 * 24 * time.Hour),
+		},
+		ShutdownTimeout: Duration(10 * time.Second),
+	}
+}
+
+// option binds config value to its environment variable and flag.
+type option struct {
+	flag  string
+	env   string
+	usage string
+	value func(c *Config) interface{}
+}
+
+var options = []option{
+	{"listen", "BATTLESHIP_LISTEN", "address to listen at", func(c *Config) interface{} { return &c.Listen }},
+	{"tls-cert", "BATTLESHIP_TLS_CERT", "path to TLS certificate", func(c *Config) interface{} { return &c.TLS.Cert }},
+	{"tls-key", "BATTLESHIP_TLS_KEY", "path to TLS key", func(c *Config) interface{} { return &c.TLS.Key }},
+	{"log-level", "BATTLESHIP_LOG_LEVEL", "log level", func(c *Config) interface{} { return &c.Log.Level }},
+	{"log-format", "BATTLESHIP_LOG_FORMAT", "log format: text or json", func(c *Config) interface{} { return &c.Log.Format }},
+	{"max-field-size", "BATTLESHIP_MAX_FIELD_SIZE", "maximum field size", func(c *Config) interface{} { return &c.MaxFieldSize }},
+	{"storage", "BATTLESHIP_STORAGE", "storage backend: memory or file", func(c *Config) interface{} { return &c.Storage.Backend }},
+	{"storage-path", "BATTLESHIP_STORAGE_PATH", "data directory of file storage", func(c *Config) interface{} { return &c.Storage.Path }},
+	{"flush-interval", "BATTLESHIP_FLUSH_INTERVAL", "interval of saving changes to storage", func(c *Config) interface{} { return &c.Storage.FlushInterval }},
+	{"swagger", "BATTLESHIP_SWAGGER", "serve swagger docs", func(c *Config) interface{} { return &c.Swagger }},
+	{"api-keys", "BATTLESHIP_API_KEYS", "API keys in key:player:role,... format", func(c *Config) interface{} { return &c.Auth.APIKeys }},
+	{"token-secret", "BATTLESHIP_TOKEN_SECRET", "secret of signed tokens", func(c *Config) interface{} { return &c.Auth.TokenSecret }},
+	{"write-rate", "BATTLESHIP_WRITE_RATE", "mutating requests per second per client", func(c *Config) interface{} { return &c.Limits.WriteRate }},
+	{"write-burst", "BATTLESHIP_WRITE_BURST", "burst of mutating requests per client", func(c *Config) interface{} { return &c.Limits.WriteBurst }},
+	{"read-rate", "BATTLESHIP_READ_RATE", "read requests per second per client", func(c *Config) interface{} { return &c.Limits.ReadRate }},
+	{"read-burst", "BATTLESHIP_READ_BURST", "burst of read requests per client", func(c *Config) interface{} { return &c.Limits.ReadBurst }},
+	{"max-body-size", "BATTLESHIP_MAX_BODY_SIZE", "maximum request body size in bytes", func(c *Config) interface{} { return &c.Limits.MaxBodySize }},
+	{"idempotency-size", "BATTLESHIP_IDEMPOTENCY_SIZE", "number of remembered idempotency keys", func(c *Config) interface{} { return &c.Idempotency.Size }},
+	{"idempotency-ttl", "BATTLESHIP_IDEMPOTENCY_TTL", "time to remember idempotency keys", func(c *Config) interface{} { return &c.Idempotency.TTL }},
+	{"pause-ttl", "BATTLESHIP_PAUSE_TTL", "time to keep paused games before archiving, 0 keeps them forever", func(c *Config) interface{} { return &c.Games.PauseTTL }},
+	{"shutdown-timeout", "BATTLESHIP_SHUTDOWN_TIMEOUT", "time to finish in-flight requests on shutdown", func(c *Config) interface{} { return &c.ShutdownTimeout }},
+}
+
+// Load loads config from file, then from environment variables, then from
+// command line arguments, every next source overrides the previous one.
+// Config file is set with -config flag or BATTLESHIP_CONFIG variable.
+// printConfig reports if -print-config flag is provided.
+func Load(args []string, lookupEnv func(string) (string, bool)) (c Config, printConfig bool, err error) {
+	// the first pass only finds config file and validates flags
+	path, _ := lookupEnv("BATTLESHIP_CONFIG")
+	defaults := Default()
+	fs := newFlagSet(&defaults, &path, &printConfig)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, false, err
+	}
+
+	c = Default()
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return Config{}, false, err
+		}
+	}
+
+	for _, o := range options {
+		v, ok := lookupEnv(o.env)
+		if !ok {
+			continue
+		}
+		if err := (value{o.value(&c)}).Set(v); err != nil {
+			return Config{}, false, fmt.Errorf("invalid value of %s: %v", o.env, err)
+		}
+	}
+
+	if err := newFlagSet(&c, &path, &printConfig).Parse(args); err != nil {
+		return Config{}, false, err
+	}
+	return c, printConfig, nil
+}
+
+func newFlagSet(c *Config, path *string, printConfig *bool) *flag.FlagSet {
+	fs := flag.NewFlagSet("battleship", flag.ContinueOnError)
+	fs.StringVar(path, "config", *path, "path to YAML or JSON config file")
+	fs.BoolVar(printConfig, "print-config", *printConfig, "print effective config and exit")
+	for _, o := range options {
+		fs.Var(value{o.value(c)}, o.flag, o.usage+" ("+o.env+")")
+	}
+	return fs
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("can't read config file: %v", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = decodeJSONStrict(b, c)
+	} else {
+		err = yaml.UnmarshalStrict(b, c)
+	}
+	if err != nil {
+		return fmt.Errorf("can't parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// decodeJSONStrict decodes JSON like yaml.UnmarshalStrict decodes YAML:
+// unknown options are errors.
+func decodeJSONStrict(b []byte, c *Config) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the config")
+	}
+	return nil
+}
+
+// Validate checks config values.
+func (c Config) Validate() error {
+	var errs []string
+	if c.Listen == "" {
+		errs = append(errs, "listen address is empty")
+	}
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		errs = append(errs, "both TLS certificate and key should be set")
+	}
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Sprintf("unknown log level %q", c.Log.Level))
+	}
+	switch c.Log.Format {
+	case LogFormatText, LogFormatJSON:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown log format %q", c.Log.Format))
+	}
+	if c.MaxFieldSize < 1 || c.MaxFieldSize > coordinates.MaxSize {
+		errs = append(errs, fmt.Sprintf("max field size should be in range 1-%d", coordinates.MaxSize))
+	}
+	switch c.Storage.Backend {
+	case StorageMemory:
+	case StorageFile:
+		if c.Storage.Path == "" {
+			errs = append(errs, "storage path is empty")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown storage backend %q", c.Storage.Backend))
+	}
+	if c.Storage.FlushInterval <= 0 {
+		errs = append(errs, "flush interval should be positive")
+	}
+	if c.ShutdownTimeout < 0 {
+		errs = append(errs, "shutdown timeout can't be negative")
+	}
+	if c.Limits.WriteRate < 0 || c.Limits.ReadRate < 0 {
+		errs = append(errs, "rate limits can't be negative")
+	}
+	if c.Limits.WriteRate > 0 && c.Limits.WriteBurst < 1 || c.Limits.ReadRate > 0 && c.Limits.ReadBurst < 1 {
+		errs = append(errs, "burst should be positive if rate is limited")
+	}
+	if c.Limits.MaxBodySize < 1 {
+		errs = append(errs, "max body size should be positive")
+	}
+	if c.Idempotency.Size < 0 {
+		errs = append(errs, "idempotency cache size can't be negative")
+	}
+	if c.Idempotency.Size > 0 && c.Idempotency.TTL <= 0 {
+		errs = append(errs, "idempotency TTL should be positive")
+	}
+	if c.Games.PauseTTL < 0 {
+		errs = append(errs, "pause TTL can't be negative")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Print writes config in YAML format with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	if c.Auth.APIKeys != "" {
+		c.Auth.APIKeys = redacted
+	}
+	if c.Auth.TokenSecret != "" {
+		c.Auth.TokenSecret = redacted
+	}
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// value implements flag.Value for pointers to config fields.
+type value struct {
+	ptr interface{}
+}
+
+func (v value) String() string {
+	switch p := v.ptr.(type) {
+	case *string:
+		return *p
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *uint:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *int:
+		return strconv.Itoa(*p)
+	case *int64:
+		return strconv.FormatInt(*p, 10)
+	case *float64:
+		return strconv.FormatFloat(*p, 'g', -1, 64)
+	case *Duration:
+		return p.String()
+	}
+	return ""
+}
+
+func (v value) Set(s string) error {
+	switch p := v.ptr.(type) {
+	case *string:
+		*p = s
+	case *bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		*p = b
+	case *uint:
+		n, err := strconv.ParseUint(s, 10, 0)
+		if err != nil {
+			return err
+		}
+		*p = uint(n)
+	case *int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		*p = n
+	case *int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		*p = n
+	case *float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		*p = f
+	case *Duration:
+		return p.UnmarshalText([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %T", v.ptr)
+	}
+	return nil
+}
+
+// IsBoolFlag allows bool flags without value, e.g. -swagger.
+func (v value) IsBoolFlag() bool {
+	_, ok := v.ptr.(*bool)
+	return ok
+}
+
+// Duration is time.Duration written as string, e.g. "1m30s", in config files.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "battleship-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlPath := writeFile(t, "config.yaml", "listen: :9000\nlog:\n  level: debug\nmax_field_size: 10\nshutdown_timeout: 30s\n")
+	jsonPath := writeFile(t, "config.json", `{"listen": ":9001", "swagger": false, "storage": {"flush_interval": "1s"}}`)
+	badPath := writeFile(t, "bad.yaml", "unknown_option: 1\n")
+	badJSONPath := writeFile(t, "bad.json", `{"listen": ":9001", "unknown_option": 1}`)
+
+	tests := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		want      func(c *Config)
+		wantPrint bool
+		wantErr   bool
+	}{
+		{
+			name: "success, defaults",
+			want: func(c *Config) {},
+		},
+		{
+			name: "success, yaml file",
+			args: []string{"-config", yamlPath},
+			want: func(c *Config) {
+				c.Listen = ":9000"
+				c.Log.Level = "debug"
+				c.MaxFieldSize = 10
+				c.ShutdownTimeout = Duration(30 * time.Second)
+			},
+		},
+		{
+			name: "success, json file from environment",
+			env:  map[string]string{"BATTLESHIP_CONFIG": jsonPath},
+			want: func(c *Config) {
+				c.Listen = ":9001"
+				c.Swagger = false
+				c.Storage.FlushInterval = Duration(time.Second)
+			},
+		},
+		{
+			name: "success, environment overrides file",
+			args: []string{"-config", yamlPath},
+			env: map[string]string{
+				"BATTLESHIP_LISTEN":          ":9002",
+				"BATTLESHIP_READ_RATE":       "1.5",
+				"BATTLESHIP_LOG_FORMAT":      "json",
+				"BATTLESHIP_STORAGE":         "file",
+				"BATTLESHIP_IDEMPOTENCY_TTL": "10m",
+				"BATTLESHIP_PAUSE_TTL":       "24h",
+			},
+			want: func(c *Config) {
+				c.Listen = ":9002"
+				c.Log.Level = "debug"
+				c.Log.Format = LogFormatJSON
+				c.MaxFieldSize = 10
+				c.Limits.ReadRate = 1.5
+				c.ShutdownTimeout = Duration(30 * time.Second)
+				c.Storage.Backend = StorageFile
+				c.Idempotency.TTL = Duration(10 * time.Minute)
+				c.Games.PauseTTL = Duration(24 * time.Hour)
+			},
+		},
+		{
+			name: "success, flags override environment",
+			args: []string{"--config", yamlPath, "--listen", ":9003", "-swagger=false", "-shutdown-timeout", "1m", "--print-config"},
+			env:  map[string]string{"BATTLESHIP_LISTEN": ":9002"},
+			want: func(c *Config) {
+				c.Listen = ":9003"
+				c.Log.Level = "debug"
+				c.MaxFieldSize = 10
+				c.Swagger = false
+				c.ShutdownTimeout = Duration(time.Minute)
+			},
+			wantPrint: true,
+		},
+		{
+			name:    "error, unknown flag",
+			args:    []string{"-unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "error, invalid environment value",
+			env:     map[string]string{"BATTLESHIP_MAX_FIELD_SIZE": "ten"},
+			wantErr: true,
+		},
+		{
+			name:    "error, missing file",
+			args:    []string{"-config", yamlPath + ".missing"},
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown option in file",
+			args:    []string{"-config", badPath},
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown option in json file",
+			args:    []string{"-config", badJSONPath},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPrint, err := Load(tt.args, env(tt.env))
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				return
+			}
+			want := Default()
+			tt.want(&want)
+			assert.Equal(t, want, got)
+			assert.Equal(t, tt.wantPrint, gotPrint)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{
+			name:   "success, defaults",
+			modify: func(c *Config) {},
+		},
+		{
+			name:    "error, empty listen address",
+			modify:  func(c *Config) { c.Listen = "" },
+			wantErr: true,
+		},
+		{
+			name:    "error, TLS key without certificate",
+			modify:  func(c *Config) { c.TLS.Key = "key.pem" },
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown log level",
+			modify:  func(c *Config) { c.Log.Level = "loud" },
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown log format",
+			modify:  func(c *Config) { c.Log.Format = "xml" },
+			wantErr: true,
+		},
+		{
+			name:    "error, field size too big",
+			modify:  func(c *Config) { c.MaxFieldSize = 27 },
+			wantErr: true,
+		},
+		{
+			name:    "error, unknown storage",
+			modify:  func(c *Config) { c.Storage.Backend = "floppy" },
+			wantErr: true,
+		},
+		{
+			name:    "error, file storage without path",
+			modify:  func(c *Config) { c.Storage.Backend = StorageFile; c.Storage.Path = "" },
+			wantErr: true,
+		},
+		{
+			name:    "error, zero flush interval",
+			modify:  func(c *Config) { c.Storage.FlushInterval = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "error, zero burst",
+			modify:  func(c *Config) { c.Limits.WriteBurst = 0 },
+			wantErr: true,
+		},
+		{
+			name:   "success, idempotency disabled",
+			modify: func(c *Config) { c.Idempotency = Idempotency{} },
+		},
+		{
+			name:    "error, zero idempotency TTL",
+			modify:  func(c *Config) { c.Idempotency.TTL = 0 },
+			wantErr: true,
+		},
+		{
+			name:   "success, paused games are kept forever",
+			modify: func(c *Config) { c.Games.PauseTTL = 0 },
+		},
+		{
+			name:    "error, negative pause TTL",
+			modify:  func(c *Config) { c.Games.PauseTTL = Duration(-time.Hour) },
+			wantErr: true,
+		},
+		{
+			name:    "error, zero body size",
+			modify:  func(c *Config) { c.Limits.MaxBodySize = 0 },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.modify(&c)
+			err := c.Validate()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	c := Default()
+	c.Auth.TokenSecret = "secret"
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, c.Print(buf))
+	assert.Contains(t, buf.String(), "listen: :8080")
+	assert.Contains(t, buf.String(), "shutdown_timeout: 10s")
+	assert.Contains(t, buf.String(), "token_secret: <redacted>")
+	assert.NotContains(t, buf.String(), "secret\n")
+}
@@ -5,8 +5,9 @@ import (
 	"strings"
 )
 
-// Coordinates represents collections of coordinates.
-type Coordinates map[Coordinate]struct{}
+// MaxSize is the biggest field size coordinates can address,
+// since columns are named with english letters.
+const MaxSize uint = 'Z' - 'A' + 1
 
 // Coordinate represents coordinate on battlefield.
 // Both X and Y starts with zero.
@@ -14,6 +15,11 @@ type Coordinate struct {
 	X, Y uint
 }
 
+// String formats coordinate the way ConvertCoordinate parses it, e.g. "A1".
+func (c Coordinate) String() string {
+	return string(rune('A'+c.X)) + strconv.FormatUint(uint64(c.Y)+1, 10)
+}
+
 // ConvertCoordinate converts string representation of ship's coordinate
 // into internal coordinate value.
 func ConvertCoordinate(s string) (c Coordinate, ok bool) {
@@ -40,7 +46,7 @@ func ConvertCoordinate(s string) (c Coordinate, ok bool) {
 
 // GetInnerOuterCells calculates and returns ship cells and
 // cells that in close vicinity f ship cells.
-func GetInnerOuterCells(c [2]Coordinate) (inner, outer map[Coordinate]struct{}) {
+func GetInnerOuterCells(c [2]Coordinate) (inner, outer Coordinates) {
 	lx, bx := sortX(c)
 	ly, by := sortY(c)
 
@@ -49,48 +55,31 @@ func GetInnerOuterCells(c [2]Coordinate) (inner, outer map[Coordinate]struct{})
 	return inner, outer
 }
 
-func outerCells(lx, bx, ly, by uint) map[Coordinate]struct{} {
-	inner := innerCells(lx, bx, ly, by)
-
-	if lx != 0 {
-		lx--
+// outerCells returns the ring around the rectangle, the same as
+// Neighbours8 of the rectangle but without sorting.
+func outerCells(lx, bx, ly, by uint) Coordinates {
+	ox, oy := lx, ly
+	if ox != 0 {
+		ox--
 	}
-	if ly != 0 {
-		ly--
+	if oy != 0 {
+		oy--
 	}
-	bx++
-	by++
 
-	distX := 1 + (bx - lx)
-	distY := 1 + (by - ly)
-
-	totalCoordinates := make(map[Coordinate]struct{}, distX*distY)
-
-	for x := lx; x <= bx; x++ {
-		for y := ly; y <= by; y++ {
-			totalCoordinates[Coordinate{X: x, Y: y}] = struct{}{}
+	c := make([]Coordinate, 0, (bx-ox+2)*(by-oy+2)-(bx-lx+1)*(by-ly+1))
+	for y := oy; y <= by+1; y++ {
+		for x := ox; x <= bx+1; x++ {
+			if x >= lx && x <= bx && y >= ly && y <= by {
+				continue
+			}
+			c = append(c, Coordinate{X: x, Y: y})
 		}
 	}
-
-	// exclude inner from totalOuter
-	for c := range inner {
-		delete(totalCoordinates, c)
-	}
-	return totalCoordinates
+	return Coordinates{c: c}
 }
 
-func innerCells(lx, bx, ly, by uint) map[Coordinate]struct{} {
-	distX := 1 + (bx - lx)
-	distY := 1 + (by - ly)
-
-	totalCoordinates := make(map[Coordinate]struct{}, distX*distY)
-
-	for x := lx; x <= bx; x++ {
-		for y := ly; y <= by; y++ {
-			totalCoordinates[Coordinate{X: x, Y: y}] = struct{}{}
-		}
-	}
-	return totalCoordinates
+func innerCells(lx, bx, ly, by uint) Coordinates {
+	return Rect(Coordinate{X: lx, Y: ly}, Coordinate{X: bx, Y: by})
 }
 
 func sortX(c [2]Coordinate) (lesser, bigger uint) {
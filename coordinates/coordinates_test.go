@@ -66,32 +66,32 @@ func TestGetInnerOuterCells(t *testing.T) {
 	tests := []struct {
 		name      string
 		args      [2]Coordinate
-		wantInner map[Coordinate]struct{}
-		wantOuter map[Coordinate]struct{}
+		wantInner Coordinates
+		wantOuter Coordinates
 	}{
 		{
 			name:      "not zero",
 			args:      [2]Coordinate{{1, 1}, {1, 1}},
-			wantInner: map[Coordinate]struct{}{{1, 1}: {}},
-			wantOuter: map[Coordinate]struct{}{{0, 0}: {}, {0, 1}: {}, {0, 2}: {}, {1, 2}: {}, {2, 2}: {}, {2, 1}: {}, {2, 0}: {}, {1, 0}: {}},
+			wantInner: NewCoordinates([]Coordinate{{1, 1}}...),
+			wantOuter: NewCoordinates([]Coordinate{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {1, 0}}...),
 		},
 		{
 			name:      "x is zero",
 			args:      [2]Coordinate{{0, 1}, {0, 1}},
-			wantInner: map[Coordinate]struct{}{{0, 1}: {}},
-			wantOuter: map[Coordinate]struct{}{{0, 0}: {}, {1, 0}: {}, {1, 1}: {}, {1, 2}: {}, {0, 2}: {}},
+			wantInner: NewCoordinates([]Coordinate{{0, 1}}...),
+			wantOuter: NewCoordinates([]Coordinate{{0, 0}, {1, 0}, {1, 1}, {1, 2}, {0, 2}}...),
 		},
 		{
 			name:      "y is zero",
 			args:      [2]Coordinate{{1, 0}, {1, 0}},
-			wantInner: map[Coordinate]struct{}{{1, 0}: {}},
-			wantOuter: map[Coordinate]struct{}{{0, 0}: {}, {0, 1}: {}, {1, 1}: {}, {2, 1}: {}, {2, 0}: {}},
+			wantInner: NewCoordinates([]Coordinate{{1, 0}}...),
+			wantOuter: NewCoordinates([]Coordinate{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {2, 0}}...),
 		},
 		{
 			name:      "both coordinates are zero",
 			args:      [2]Coordinate{{0, 0}, {0, 0}},
-			wantInner: map[Coordinate]struct{}{{0, 0}: {}},
-			wantOuter: map[Coordinate]struct{}{{0, 1}: {}, {1, 1}: {}, {1, 0}: {}},
+			wantInner: NewCoordinates([]Coordinate{{0, 0}}...),
+			wantOuter: NewCoordinates([]Coordinate{{0, 1}, {1, 1}, {1, 0}}...),
 		},
 	}
 	for _, tt := range tests {
@@ -111,7 +111,7 @@ func TestOuterCells(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want map[Coordinate]struct{}
+		want Coordinates
 	}{
 		{
 			name: "not zero",
@@ -121,7 +121,7 @@ func TestOuterCells(t *testing.T) {
 				ly: 1,
 				by: 1,
 			},
-			want: map[Coordinate]struct{}{{0, 0}: {}, {0, 1}: {}, {0, 2}: {}, {1, 2}: {}, {2, 2}: {}, {2, 1}: {}, {2, 0}: {}, {1, 0}: {}},
+			want: NewCoordinates([]Coordinate{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {1, 0}}...),
 		},
 		{
 			name: "x is zero",
@@ -131,7 +131,7 @@ func TestOuterCells(t *testing.T) {
 				ly: 1,
 				by: 1,
 			},
-			want: map[Coordinate]struct{}{{0, 0}: {}, {1, 0}: {}, {1, 1}: {}, {1, 2}: {}, {0, 2}: {}},
+			want: NewCoordinates([]Coordinate{{0, 0}, {1, 0}, {1, 1}, {1, 2}, {0, 2}}...),
 		},
 		{
 			name: "y is zero",
@@ -141,7 +141,7 @@ func TestOuterCells(t *testing.T) {
 				ly: 0,
 				by: 0,
 			},
-			want: map[Coordinate]struct{}{{0, 0}: {}, {0, 1}: {}, {1, 1}: {}, {2, 1}: {}, {2, 0}: {}},
+			want: NewCoordinates([]Coordinate{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {2, 0}}...),
 		},
 		{
 			name: "both coordinates are zero",
@@ -151,7 +151,7 @@ func TestOuterCells(t *testing.T) {
 				ly: 0,
 				by: 0,
 			},
-			want: map[Coordinate]struct{}{{0, 1}: {}, {1, 1}: {}, {1, 0}: {}},
+			want: NewCoordinates([]Coordinate{{0, 1}, {1, 1}, {1, 0}}...),
 		},
 	}
 	for _, tt := range tests {
@@ -164,6 +164,8 @@ func TestOuterCells(t *testing.T) {
 			)
 
 			assert.Equal(t, tt.want, got)
+			inner := innerCells(tt.args.lx, tt.args.bx, tt.args.ly, tt.args.by)
+			assert.Equal(t, inner.Neighbours8(), got)
 		})
 	}
 }
@@ -176,7 +178,7 @@ func TestInnerCells(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want map[Coordinate]struct{}
+		want Coordinates
 	}{
 		{
 			name: "single cell",
@@ -186,7 +188,7 @@ func TestInnerCells(t *testing.T) {
 				ly: 1,
 				by: 1,
 			},
-			want: map[Coordinate]struct{}{{1, 1}: {}},
+			want: NewCoordinates([]Coordinate{{1, 1}}...),
 		},
 		{
 			name: "multiple cells, left to right",
@@ -196,7 +198,7 @@ func TestInnerCells(t *testing.T) {
 				ly: 1,
 				by: 1,
 			},
-			want: map[Coordinate]struct{}{{1, 1}: {}, {2, 1}: {}, {3, 1}: {}},
+			want: NewCoordinates([]Coordinate{{1, 1}, {2, 1}, {3, 1}}...),
 		},
 		{
 			name: "multiple cells, up to bottom",
@@ -206,7 +208,7 @@ func TestInnerCells(t *testing.T) {
 				ly: 1,
 				by: 3,
 			},
-			want: map[Coordinate]struct{}{{1, 1}: {}, {1, 2}: {}, {1, 3}: {}},
+			want: NewCoordinates([]Coordinate{{1, 1}, {1, 2}, {1, 3}}...),
 		},
 		{
 			name: "multiple cells, diagonal",
@@ -216,7 +218,7 @@ func TestInnerCells(t *testing.T) {
 				ly: 1,
 				by: 2,
 			},
-			want: map[Coordinate]struct{}{{1, 1}: {}, {1, 2}: {}, {2, 1}: {}, {2, 2}: {}},
+			want: NewCoordinates([]Coordinate{{1, 1}, {1, 2}, {2, 1}, {2, 2}}...),
 		},
 	}
 	for _, tt := range tests {
@@ -301,3 +303,9 @@ func TestSortX(t *testing.T) {
 		})
 	}
 }
+
+func TestCoordinate_String(t *testing.T) {
+	assert.Equal(t, "A1", Coordinate{X: 0, Y: 0}.String())
+	assert.Equal(t, "C10", Coordinate{X: 2, Y: 9}.String())
+	assert.Equal(t, "Z26", Coordinate{X: 25, Y: 25}.String())
+}
@@ -0,0 +1,112 @@
+package coordinates
+
+// Grid is geometry of the field: which cells ships are made of
+// and which cells touch each other.
+type Grid interface {
+	// Area returns cells of the ship set by two cells.
+	Area(c1, c2 Coordinate) Coordinates
+	// Neighbours returns cells sharing a side with the set, excluding the set.
+	Neighbours(s Coordinates) Coordinates
+	// Ring returns cells touching the set, excluding the set.
+	// Other ships can't be placed there.
+	Ring(s Coordinates) Coordinates
+	// Connected reports if every cell of the set can be reached
+	// from any other one through neighbours within the set.
+	Connected(s Coordinates) bool
+	// Line reports if cells of the set are on one straight line.
+	Line(s Coordinates) bool
+	// Unwrap returns the set moved so that it doesn't cross edges
+	// of the field, the set itself if edges can't be crossed.
+	Unwrap(s Coordinates) Coordinates
+}
+
+// Grids of the field. Coordinates of both grids are the same,
+// only the geometry differs.
+var (
+	// Square is the grid of square cells, ships set by two cells are rectangles,
+	// cells touch each other by sides and by corners.
+	Square Grid = squareGrid{}
+	// Hexagonal is the grid of hexes, see Hex. Ships set by two cells are
+	// straight lines if cells are on one axis, otherwise rhombuses.
+	Hexagonal Grid = hexGrid{}
+)
+
+type squareGrid struct{}
+
+func (squareGrid) Area(c1, c2 Coordinate) Coordinates {
+	return Rect(c1, c2)
+}
+
+func (squareGrid) Neighbours(s Coordinates) Coordinates {
+	return s.Neighbours4()
+}
+
+func (squareGrid) Ring(s Coordinates) Coordinates {
+	return s.Neighbours8()
+}
+
+func (squareGrid) Connected(s Coordinates) bool {
+	return s.Connected()
+}
+
+func (squareGrid) Line(s Coordinates) bool {
+	sameX, sameY := true, true
+	for _, c := range s.c {
+		sameX = sameX && c.X == s.c[0].X
+		sameY = sameY && c.Y == s.c[0].Y
+	}
+	return sameX || sameY
+}
+
+func (squareGrid) Unwrap(s Coordinates) Coordinates {
+	return s
+}
+
+type hexGrid struct{}
+
+func (hexGrid) Area(c1, c2 Coordinate) Coordinates {
+	line, ok := HexLine(HexOf(c1), HexOf(c2))
+	if !ok {
+		return Rect(c1, c2)
+	}
+	c := make([]Coordinate, len(line))
+	for i, h := range line {
+		c[i], _ = h.Coordinate()
+	}
+	return sortedCoordinates(c)
+}
+
+func (hexGrid) Neighbours(s Coordinates) Coordinates {
+	c := make([]Coordinate, 0, 6*len(s.c))
+	for _, v := range s.c {
+		for _, h := range HexOf(v).Neighbours() {
+			if n, ok := h.Coordinate(); ok {
+				c = append(c, n)
+			}
+		}
+	}
+	return sortedCoordinates(c).Difference(s)
+}
+
+// Ring is the same as Neighbours, hexes don't touch by corners.
+func (g hexGrid) Ring(s Coordinates) Coordinates {
+	return g.Neighbours(s)
+}
+
+func (g hexGrid) Connected(s Coordinates) bool {
+	return s.connected(g.Neighbours)
+}
+
+func (hexGrid) Line(s Coordinates) bool {
+	sameQ, sameR, sameS := true, true, true
+	for _, c := range s.c {
+		sameQ = sameQ && c.X == s.c[0].X
+		sameR = sameR && c.Y == s.c[0].Y
+		sameS = sameS && c.X+c.Y == s.c[0].X+s.c[0].Y
+	}
+	return sameQ || sameR || sameS
+}
+
+func (hexGrid) Unwrap(s Coordinates) Coordinates {
+	return s
+}
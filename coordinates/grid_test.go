@@ -0,0 +1,119 @@
+package coordinates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrid_Area(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      Grid
+		c1, c2 Coordinate
+		want   Coordinates
+	}{
+		{
+			name: "square, rectangle",
+			g:    Square,
+			c1:   Coordinate{X: 1, Y: 0},
+			c2:   Coordinate{X: 0, Y: 1},
+			want: Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1}),
+		},
+		{
+			name: "hex, diagonal line",
+			g:    Hexagonal,
+			c1:   Coordinate{X: 1, Y: 0},
+			c2:   Coordinate{X: 0, Y: 1},
+			want: set(Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}),
+		},
+		{
+			name: "hex, row",
+			g:    Hexagonal,
+			c1:   Coordinate{X: 0, Y: 2},
+			c2:   Coordinate{X: 2, Y: 2},
+			want: Rect(Coordinate{X: 0, Y: 2}, Coordinate{X: 2, Y: 2}),
+		},
+		{
+			name: "hex, rhombus",
+			g:    Hexagonal,
+			c1:   Coordinate{X: 0, Y: 0},
+			c2:   Coordinate{X: 1, Y: 1},
+			want: Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.g.Area(tt.c1, tt.c2))
+		})
+	}
+}
+
+func TestGrid_Neighbours(t *testing.T) {
+	c := set(Coordinate{X: 1, Y: 1})
+	assert.Equal(t, c.Neighbours4(), Square.Neighbours(c))
+	assert.Equal(t, c.Neighbours8(), Square.Ring(c))
+
+	hex := set(
+		Coordinate{X: 1, Y: 0}, Coordinate{X: 2, Y: 0},
+		Coordinate{X: 0, Y: 1}, Coordinate{X: 2, Y: 1},
+		Coordinate{X: 0, Y: 2}, Coordinate{X: 1, Y: 2},
+	)
+	assert.Equal(t, hex, Hexagonal.Neighbours(c))
+	assert.Equal(t, hex, Hexagonal.Ring(c))
+
+	// hexes out of the field are skipped
+	corner := set(Coordinate{X: 0, Y: 0})
+	assert.Equal(t, set(Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}), Hexagonal.Ring(corner))
+}
+
+func TestGrid_Connected(t *testing.T) {
+	diagonal := set(Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1})
+	assert.False(t, Square.Connected(diagonal))
+	assert.True(t, Hexagonal.Connected(diagonal))
+
+	other := set(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1})
+	assert.False(t, Square.Connected(other))
+	assert.False(t, Hexagonal.Connected(other))
+	assert.False(t, Hexagonal.Connected(Coordinates{}))
+}
+
+func TestGrid_Line(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       Coordinates
+		wantSq  bool
+		wantHex bool
+	}{
+		{
+			name:    "row",
+			c:       Rect(Coordinate{X: 0, Y: 1}, Coordinate{X: 3, Y: 1}),
+			wantSq:  true,
+			wantHex: true,
+		},
+		{
+			name:    "column",
+			c:       Rect(Coordinate{X: 2, Y: 0}, Coordinate{X: 2, Y: 3}),
+			wantSq:  true,
+			wantHex: true,
+		},
+		{
+			name:    "hex diagonal",
+			c:       set(Coordinate{X: 2, Y: 0}, Coordinate{X: 1, Y: 1}, Coordinate{X: 0, Y: 2}),
+			wantSq:  false,
+			wantHex: true,
+		},
+		{
+			name:    "square",
+			c:       Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1}),
+			wantSq:  false,
+			wantHex: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantSq, Square.Line(tt.c))
+			assert.Equal(t, tt.wantHex, Hexagonal.Line(tt.c))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package coordinates
+
+// Hex is axial coordinate of the cell of hexagonal grid. The field is
+// the rhombus of columns Q and rows R, the third cube coordinate is -Q-R.
+// Cell (Q, R) is stored as Coordinate{X: Q, Y: R} and is written the same
+// way, e.g. "A1", so hexes and squares share parsing and formatting.
+type Hex struct {
+	Q, R int
+}
+
+// hexDirections are offsets of the six neighbours of the hex.
+var hexDirections = [6]Hex{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, -1}, {-1, 1}}
+
+// HexOf returns hex stored at the coordinate.
+func HexOf(c Coordinate) Hex {
+	return Hex{Q: int(c.X), R: int(c.Y)}
+}
+
+// ParseHex parses hex written like the coordinate, e.g. "A1".
+func ParseHex(s string) (Hex, bool) {
+	c, ok := ConvertCoordinate(s)
+	if !ok {
+		return Hex{}, false
+	}
+	return HexOf(c), true
+}
+
+// Coordinate returns coordinate the hex is stored at,
+// false if the hex is out of the field.
+func (h Hex) Coordinate() (Coordinate, bool) {
+	if h.Q < 0 || h.R < 0 {
+		return Coordinate{}, false
+	}
+	return Coordinate{X: uint(h.Q), Y: uint(h.R)}, true
+}
+
+// String formats the hex the way ParseHex parses it,
+// empty if the hex is out of the field.
+func (h Hex) String() string {
+	c, ok := h.Coordinate()
+	if !ok {
+		return ""
+	}
+	return c.String()
+}
+
+// Neighbours returns six hexes sharing a side with the hex.
+func (h Hex) Neighbours() [6]Hex {
+	var n [6]Hex
+	for i, d := range hexDirections {
+		n[i] = Hex{Q: h.Q + d.Q, R: h.R + d.R}
+	}
+	return n
+}
+
+// Distance returns the number of steps between hexes.
+func (h Hex) Distance(o Hex) int {
+	dq, dr := abs(h.Q-o.Q), abs(h.R-o.R)
+	ds := abs(h.Q + h.R - o.Q - o.R)
+	return (dq + dr + ds) / 2
+}
+
+// HexLine returns hexes of the straight line between hexes,
+// false if hexes are not on one of the three axes.
+func HexLine(a, b Hex) ([]Hex, bool) {
+	if a.Q != b.Q && a.R != b.R && a.Q+a.R != b.Q+b.R {
+		return nil, false
+	}
+	n := a.Distance(b)
+	step := Hex{Q: sign(b.Q - a.Q), R: sign(b.R - a.R)}
+	line := make([]Hex, 0, n+1)
+	for i := 0; i <= n; i++ {
+		line = append(line, Hex{Q: a.Q + i*step.Q, R: a.R + i*step.R})
+	}
+	return line, true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
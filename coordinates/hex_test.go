@@ -0,0 +1,96 @@
+package coordinates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHex(t *testing.T) {
+	h, ok := ParseHex("C2")
+	assert.True(t, ok)
+	assert.Equal(t, Hex{Q: 2, R: 1}, h)
+	assert.Equal(t, "C2", h.String())
+
+	_, ok = ParseHex("2C")
+	assert.False(t, ok)
+
+	_, ok = Hex{Q: -1, R: 2}.Coordinate()
+	assert.False(t, ok)
+	assert.Equal(t, "", Hex{Q: 1, R: -1}.String())
+}
+
+func TestHex_Neighbours(t *testing.T) {
+	h := Hex{Q: 1, R: 1}
+	for _, n := range h.Neighbours() {
+		assert.Equal(t, 1, h.Distance(n), n)
+	}
+	assert.Equal(t, [6]Hex{{2, 1}, {0, 1}, {1, 2}, {1, 0}, {2, 0}, {0, 2}}, h.Neighbours())
+}
+
+func TestHex_Distance(t *testing.T) {
+	tests := []struct {
+		a, b Hex
+		want int
+	}{
+		{a: Hex{0, 0}, b: Hex{0, 0}, want: 0},
+		{a: Hex{0, 0}, b: Hex{3, 0}, want: 3},
+		{a: Hex{0, 3}, b: Hex{3, 0}, want: 3},
+		{a: Hex{0, 0}, b: Hex{2, 2}, want: 4},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.a.Distance(tt.b))
+		assert.Equal(t, tt.want, tt.b.Distance(tt.a))
+	}
+}
+
+func TestHexLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   Hex
+		want   []Hex
+		wantOK bool
+	}{
+		{
+			name:   "single hex",
+			a:      Hex{1, 1},
+			b:      Hex{1, 1},
+			want:   []Hex{{1, 1}},
+			wantOK: true,
+		},
+		{
+			name:   "along the row",
+			a:      Hex{2, 0},
+			b:      Hex{0, 0},
+			want:   []Hex{{2, 0}, {1, 0}, {0, 0}},
+			wantOK: true,
+		},
+		{
+			name:   "along the column",
+			a:      Hex{1, 0},
+			b:      Hex{1, 2},
+			want:   []Hex{{1, 0}, {1, 1}, {1, 2}},
+			wantOK: true,
+		},
+		{
+			name:   "along the diagonal",
+			a:      Hex{2, 0},
+			b:      Hex{0, 2},
+			want:   []Hex{{2, 0}, {1, 1}, {0, 2}},
+			wantOK: true,
+		},
+		{
+			name:   "not on one axis",
+			a:      Hex{0, 0},
+			b:      Hex{1, 1},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := HexLine(tt.a, tt.b)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package coordinates
+
+import "sort"
+
+// Coordinates is the set of coordinates ordered in reading order:
+// row by row, left to right. The zero value is the empty set.
+type Coordinates struct {
+	c []Coordinate
+}
+
+// less reports if a goes before b in reading order.
+func less(a, b Coordinate) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
+
+// NewCoordinates creates the set of coordinates, duplicates are ignored.
+func NewCoordinates(cs ...Coordinate) Coordinates {
+	return sortedCoordinates(append([]Coordinate(nil), cs...))
+}
+
+// sortedCoordinates sorts coordinates and removes duplicates in place.
+func sortedCoordinates(c []Coordinate) Coordinates {
+	if len(c) == 0 {
+		return Coordinates{}
+	}
+	sort.Slice(c, func(i, j int) bool { return less(c[i], c[j]) })
+	n := 1
+	for i := 1; i < len(c); i++ {
+		if c[i] != c[n-1] {
+			c[n] = c[i]
+			n++
+		}
+	}
+	return Coordinates{c: c[:n]}
+}
+
+// Rect returns all coordinates of the rectangle with the corners.
+func Rect(c1, c2 Coordinate) Coordinates {
+	lx, bx := sortX([2]Coordinate{c1, c2})
+	ly, by := sortY([2]Coordinate{c1, c2})
+
+	c := make([]Coordinate, 0, (bx-lx+1)*(by-ly+1))
+	for y := ly; y <= by; y++ {
+		for x := lx; x <= bx; x++ {
+			c = append(c, Coordinate{X: x, Y: y})
+		}
+	}
+	return Coordinates{c: c}
+}
+
+// Len returns number of coordinates in the set.
+func (s Coordinates) Len() int {
+	return len(s.c)
+}
+
+// Sorted returns coordinates in reading order: row by row, left to right.
+// The result should not be modified.
+func (s Coordinates) Sorted() []Coordinate {
+	return s.c
+}
+
+// Contains reports if the coordinate is in the set.
+func (s Coordinates) Contains(c Coordinate) bool {
+	i := s.search(c)
+	return i < len(s.c) && s.c[i] == c
+}
+
+// search returns index of the first coordinate not before c.
+func (s Coordinates) search(c Coordinate) int {
+	return sort.Search(len(s.c), func(i int) bool { return !less(s.c[i], c) })
+}
+
+// Add adds the coordinate to the set.
+func (s *Coordinates) Add(c Coordinate) {
+	i := s.search(c)
+	if i < len(s.c) && s.c[i] == c {
+		return
+	}
+	s.c = append(s.c, Coordinate{})
+	copy(s.c[i+1:], s.c[i:])
+	s.c[i] = c
+}
+
+// Union returns coordinates which are in any of the sets.
+func (s Coordinates) Union(o Coordinates) Coordinates {
+	c := make([]Coordinate, 0, len(s.c)+len(o.c))
+	i, j := 0, 0
+	for i < len(s.c) && j < len(o.c) {
+		switch {
+		case less(s.c[i], o.c[j]):
+			c = append(c, s.c[i])
+			i++
+		case less(o.c[j], s.c[i]):
+			c = append(c, o.c[j])
+			j++
+		default:
+			c = append(c, s.c[i])
+			i++
+			j++
+		}
+	}
+	c = append(c, s.c[i:]...)
+	c = append(c, o.c[j:]...)
+	return newCoordinates(c)
+}
+
+// Intersection returns coordinates which are in both sets.
+func (s Coordinates) Intersection(o Coordinates) Coordinates {
+	var c []Coordinate
+	i, j := 0, 0
+	for i < len(s.c) && j < len(o.c) {
+		switch {
+		case less(s.c[i], o.c[j]):
+			i++
+		case less(o.c[j], s.c[i]):
+			j++
+		default:
+			c = append(c, s.c[i])
+			i++
+			j++
+		}
+	}
+	return newCoordinates(c)
+}
+
+// Difference returns coordinates of the set which are not in o.
+func (s Coordinates) Difference(o Coordinates) Coordinates {
+	c := make([]Coordinate, 0, len(s.c))
+	i, j := 0, 0
+	for i < len(s.c) {
+		switch {
+		case j == len(o.c) || less(s.c[i], o.c[j]):
+			c = append(c, s.c[i])
+			i++
+		case less(o.c[j], s.c[i]):
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return newCoordinates(c)
+}
+
+// Clip returns coordinates of the set which are on the field of the size.
+func (s Coordinates) Clip(size uint) Coordinates {
+	c := make([]Coordinate, 0, len(s.c))
+	for _, v := range s.c {
+		if v.X < size && v.Y < size {
+			c = append(c, v)
+		}
+	}
+	return newCoordinates(c)
+}
+
+// Neighbours4 returns coordinates next to the set horizontally
+// or vertically, excluding the set itself. Coordinates aren't
+// negative, use Clip to drop coordinates beyond the field.
+func (s Coordinates) Neighbours4() Coordinates {
+	return s.neighbours(false)
+}
+
+// Neighbours8 returns coordinates next to the set including
+// diagonals, excluding the set itself. Coordinates aren't
+// negative, use Clip to drop coordinates beyond the field.
+func (s Coordinates) Neighbours8() Coordinates {
+	return s.neighbours(true)
+}
+
+// Connected reports if every coordinate of the set can be reached
+// from any other one moving horizontally or vertically within the set.
+// The empty set is not connected.
+func (s Coordinates) Connected() bool {
+	return s.connected(Coordinates.Neighbours4)
+}
+
+// connected reports if the set is connected through the neighbours.
+func (s Coordinates) connected(neighbours func(Coordinates) Coordinates) bool {
+	if len(s.c) == 0 {
+		return false
+	}
+	seen := make([]bool, len(s.c))
+	seen[0] = true
+	queue := []Coordinate{s.c[0]}
+	reached := 1
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, n := range neighbours(Coordinates{c: []Coordinate{v}}).c {
+			i := s.search(n)
+			if i < len(s.c) && s.c[i] == n && !seen[i] {
+				seen[i] = true
+				reached++
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reached == len(s.c)
+}
+
+func (s Coordinates) neighbours(diagonal bool) Coordinates {
+	c := make([]Coordinate, 0, 8*len(s.c))
+	for _, v := range s.c {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if (dx == 0 && dy == 0) || (!diagonal && dx != 0 && dy != 0) {
+					continue
+				}
+				if (v.X == 0 && dx < 0) || (v.Y == 0 && dy < 0) {
+					continue
+				}
+				c = append(c, Coordinate{X: uint(int(v.X) + dx), Y: uint(int(v.Y) + dy)})
+			}
+		}
+	}
+	return sortedCoordinates(c).Difference(s)
+}
+
+// newCoordinates wraps sorted coordinates without duplicates,
+// nil is used for the empty set.
+func newCoordinates(c []Coordinate) Coordinates {
+	if len(c) == 0 {
+		return Coordinates{}
+	}
+	return Coordinates{c: c}
+}
@@ -0,0 +1,237 @@
+package coordinates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func set(c ...Coordinate) Coordinates {
+	return NewCoordinates(c...)
+}
+
+func TestNewCoordinates(t *testing.T) {
+	c := NewCoordinates(Coordinate{X: 1, Y: 1}, Coordinate{X: 0, Y: 1}, Coordinate{X: 2, Y: 0}, Coordinate{X: 1, Y: 1})
+	assert.Equal(t, []Coordinate{{X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}, c.Sorted())
+	assert.Equal(t, 3, c.Len())
+	assert.Empty(t, NewCoordinates().Sorted())
+	assert.Equal(t, Coordinates{}, NewCoordinates())
+}
+
+func TestRect(t *testing.T) {
+	tests := []struct {
+		name   string
+		c1, c2 Coordinate
+		want   []Coordinate
+	}{
+		{
+			name: "single cell",
+			c1:   Coordinate{X: 1, Y: 1},
+			c2:   Coordinate{X: 1, Y: 1},
+			want: []Coordinate{{X: 1, Y: 1}},
+		},
+		{
+			name: "reversed corners",
+			c1:   Coordinate{X: 1, Y: 1},
+			c2:   Coordinate{X: 0, Y: 0},
+			want: []Coordinate{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Rect(tt.c1, tt.c2).Sorted())
+		})
+	}
+}
+
+func TestCoordinates_Add(t *testing.T) {
+	c := Coordinates{}
+	for _, v := range []Coordinate{{X: 1, Y: 1}, {X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 0}} {
+		c.Add(v)
+	}
+	assert.Equal(t, set(Coordinate{X: 0, Y: 0}, Coordinate{X: 0, Y: 1}, Coordinate{X: 1, Y: 1}), c)
+	assert.True(t, c.Contains(Coordinate{X: 0, Y: 1}))
+	assert.False(t, c.Contains(Coordinate{X: 1, Y: 0}))
+	assert.False(t, Coordinates{}.Contains(Coordinate{}))
+}
+
+func TestCoordinates_SetOperations(t *testing.T) {
+	a := Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1})
+	b := Rect(Coordinate{X: 1, Y: 1}, Coordinate{X: 2, Y: 1})
+
+	tests := []struct {
+		name string
+		got  Coordinates
+		want Coordinates
+	}{
+		{
+			name: "union",
+			got:  a.Union(b),
+			want: set(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}, Coordinate{X: 1, Y: 1}, Coordinate{X: 2, Y: 1}),
+		},
+		{
+			name: "union, empty",
+			got:  Coordinates{}.Union(Coordinates{}),
+			want: Coordinates{},
+		},
+		{
+			name: "intersection",
+			got:  a.Intersection(b),
+			want: set(Coordinate{X: 1, Y: 1}),
+		},
+		{
+			name: "intersection, empty",
+			got:  a.Intersection(Rect(Coordinate{X: 5, Y: 5}, Coordinate{X: 5, Y: 5})),
+			want: Coordinates{},
+		},
+		{
+			name: "difference",
+			got:  a.Difference(b),
+			want: set(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}),
+		},
+		{
+			name: "difference, everything",
+			got:  a.Difference(a),
+			want: Coordinates{},
+		},
+		{
+			name: "clip",
+			got:  b.Clip(2),
+			want: set(Coordinate{X: 1, Y: 1}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestCoordinates_Neighbours(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     Coordinates
+		want4 Coordinates
+		want8 Coordinates
+	}{
+		{
+			name:  "corner",
+			c:     set(Coordinate{X: 0, Y: 0}),
+			want4: set(Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}),
+			want8: set(Coordinate{X: 1, Y: 0}, Coordinate{X: 0, Y: 1}, Coordinate{X: 1, Y: 1}),
+		},
+		{
+			name:  "line",
+			c:     Rect(Coordinate{X: 1, Y: 1}, Coordinate{X: 2, Y: 1}),
+			want4: set(Coordinate{X: 1, Y: 0}, Coordinate{X: 2, Y: 0}, Coordinate{X: 0, Y: 1}, Coordinate{X: 3, Y: 1}, Coordinate{X: 1, Y: 2}, Coordinate{X: 2, Y: 2}),
+			want8: Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 3, Y: 2}).Difference(Rect(Coordinate{X: 1, Y: 1}, Coordinate{X: 2, Y: 1})),
+		},
+		{
+			name: "empty",
+			c:    Coordinates{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want4, tt.c.Neighbours4())
+			assert.Equal(t, tt.want8, tt.c.Neighbours8())
+		})
+	}
+}
+
+func TestCoordinates_Connected(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Coordinates
+		want bool
+	}{
+		{
+			name: "single cell",
+			c:    set(Coordinate{X: 3, Y: 3}),
+			want: true,
+		},
+		{
+			name: "L shape",
+			c:    set(Coordinate{X: 0, Y: 0}, Coordinate{X: 0, Y: 1}, Coordinate{X: 0, Y: 2}, Coordinate{X: 1, Y: 2}),
+			want: true,
+		},
+		{
+			name: "diagonal only",
+			c:    set(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 1}),
+			want: false,
+		},
+		{
+			name: "two parts",
+			c:    set(Coordinate{X: 0, Y: 0}, Coordinate{X: 1, Y: 0}, Coordinate{X: 3, Y: 0}),
+			want: false,
+		},
+		{
+			name: "empty",
+			c:    Coordinates{},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.c.Connected())
+		})
+	}
+}
+
+// mapInnerOuterCells is the map based implementation of GetInnerOuterCells
+// to compare the performance with.
+func mapInnerOuterCells(lx, bx, ly, by uint) (inner, outer map[Coordinate]struct{}) {
+	inner = make(map[Coordinate]struct{})
+	for x := lx; x <= bx; x++ {
+		for y := ly; y <= by; y++ {
+			inner[Coordinate{X: x, Y: y}] = struct{}{}
+		}
+	}
+	if lx != 0 {
+		lx--
+	}
+	if ly != 0 {
+		ly--
+	}
+	outer = make(map[Coordinate]struct{})
+	for x := lx; x <= bx+1; x++ {
+		for y := ly; y <= by+1; y++ {
+			if _, ok := inner[Coordinate{X: x, Y: y}]; !ok {
+				outer[Coordinate{X: x, Y: y}] = struct{}{}
+			}
+		}
+	}
+	return inner, outer
+}
+
+func BenchmarkGetInnerOuterCells(b *testing.B) {
+	c := [2]Coordinate{{X: 3, Y: 2}, {X: 3, Y: 5}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetInnerOuterCells(c)
+	}
+}
+
+func BenchmarkMapInnerOuterCells(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mapInnerOuterCells(3, 3, 2, 5)
+	}
+}
+
+func BenchmarkCoordinates_Union(b *testing.B) {
+	x := Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 9, Y: 4})
+	y := Rect(Coordinate{X: 0, Y: 3}, Coordinate{X: 9, Y: 9})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.Union(y)
+	}
+}
+
+func BenchmarkCoordinates_Contains(b *testing.B) {
+	x := Rect(Coordinate{X: 0, Y: 0}, Coordinate{X: 9, Y: 9})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.Contains(Coordinate{X: uint(i % 10), Y: uint(i % 7)})
+	}
+}
@@ -0,0 +1,99 @@
+package coordinates
+
+// Torus returns the grid g on the field of the size which wraps around
+// its edges: the right column touches the left one and the bottom row
+// touches the top one. Coordinates of the field don't change, only the
+// geometry does. Ships set by two cells go the shorter way around the field,
+// longer ships crossing the edge should be set cell by cell.
+func Torus(g Grid, size uint) Grid {
+	return torusGrid{g: g, size: size}
+}
+
+type torusGrid struct {
+	g    Grid
+	size uint
+}
+
+// inside checks if the coordinate is on the field.
+func (t torusGrid) inside(c Coordinate) bool {
+	return c.X < t.size && c.Y < t.size
+}
+
+// nearest returns image of v closest to the origin, both are shifted
+// by the size, so the result is in [0, 3*size).
+func (t torusGrid) nearest(origin, v uint) uint {
+	best := v
+	for _, img := range []uint{v, v + t.size, v + 2*t.size} {
+		if distance(origin, img) < distance(origin, best) {
+			best = img
+		}
+	}
+	return best
+}
+
+// shift moves cells by the size along both axes, so neighbours
+// of shifted cells are never negative.
+func (t torusGrid) shift(s Coordinates) Coordinates {
+	c := make([]Coordinate, len(s.c))
+	for i, v := range s.c {
+		c[i] = Coordinate{X: v.X + t.size, Y: v.Y + t.size}
+	}
+	return newCoordinates(c)
+}
+
+// wrap returns cells moved onto the field.
+func (t torusGrid) wrap(s Coordinates) Coordinates {
+	c := make([]Coordinate, len(s.c))
+	for i, v := range s.c {
+		c[i] = Coordinate{X: v.X % t.size, Y: v.Y % t.size}
+	}
+	return sortedCoordinates(c)
+}
+
+// Area returns cells between c1 and the image of c2 closest to it,
+// cells out of the field are not wrapped to be reported as such.
+func (t torusGrid) Area(c1, c2 Coordinate) Coordinates {
+	if !t.inside(c1) || !t.inside(c2) {
+		return t.g.Area(c1, c2)
+	}
+	o := Coordinate{X: c1.X + t.size, Y: c1.Y + t.size}
+	img := Coordinate{X: t.nearest(o.X, c2.X), Y: t.nearest(o.Y, c2.Y)}
+	return t.wrap(t.g.Area(o, img))
+}
+
+func (t torusGrid) Neighbours(s Coordinates) Coordinates {
+	return t.wrap(t.g.Neighbours(t.shift(s))).Difference(s)
+}
+
+func (t torusGrid) Ring(s Coordinates) Coordinates {
+	return t.wrap(t.g.Ring(t.shift(s))).Difference(s)
+}
+
+func (t torusGrid) Connected(s Coordinates) bool {
+	return s.connected(t.Neighbours)
+}
+
+func (t torusGrid) Line(s Coordinates) bool {
+	return t.g.Line(t.Unwrap(s))
+}
+
+// Unwrap moves every cell to its image closest to the first cell,
+// so ships crossing edges get the shape they have on the torus.
+func (t torusGrid) Unwrap(s Coordinates) Coordinates {
+	if len(s.c) == 0 {
+		return s
+	}
+	o := Coordinate{X: s.c[0].X + t.size, Y: s.c[0].Y + t.size}
+	c := make([]Coordinate, len(s.c))
+	for i, v := range s.c {
+		c[i] = Coordinate{X: t.nearest(o.X, v.X), Y: t.nearest(o.Y, v.Y)}
+	}
+	return sortedCoordinates(c)
+}
+
+func distance(a, b uint) uint {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,120 @@
+package coordinates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cells parses coordinates, e.g. "A1".
+func cells(t *testing.T, s ...string) Coordinates {
+	c := make([]Coordinate, len(s))
+	for i, v := range s {
+		var ok bool
+		c[i], ok = ConvertCoordinate(v)
+		assert.True(t, ok, v)
+	}
+	return NewCoordinates(c...)
+}
+
+func TestTorus_Area(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      Grid
+		c1, c2 string
+		want   []string
+	}{
+		{name: "A1, row", g: Square, c1: "J1", c2: "B1", want: []string{"J1", "A1", "B1"}},
+		{name: "A1, column", g: Square, c1: "A10", c2: "A2", want: []string{"A10", "A1", "A2"}},
+		{name: "J1, row", g: Square, c1: "I1", c2: "A1", want: []string{"I1", "J1", "A1"}},
+		{name: "J1, column", g: Square, c1: "J9", c2: "J1", want: []string{"J9", "J10", "J1"}},
+		{name: "A10, row", g: Square, c1: "J10", c2: "B10", want: []string{"J10", "A10", "B10"}},
+		{name: "A10, column", g: Square, c1: "A9", c2: "A1", want: []string{"A9", "A10", "A1"}},
+		{name: "J10, row", g: Square, c1: "I10", c2: "A10", want: []string{"I10", "J10", "A10"}},
+		{name: "J10, column", g: Square, c1: "J2", c2: "J9", want: []string{"J9", "J10", "J1", "J2"}},
+		{name: "all corners", g: Square, c1: "J10", c2: "A1", want: []string{"A1", "J1", "A10", "J10"}},
+		{name: "the shorter way", g: Square, c1: "A1", c2: "D1", want: []string{"A1", "B1", "C1", "D1"}},
+		{name: "hex, diagonal line", g: Hexagonal, c1: "A2", c2: "J3", want: []string{"A2", "J3"}},
+		{name: "hex, row", g: Hexagonal, c1: "J5", c2: "B5", want: []string{"J5", "A5", "B5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c1, _ := ConvertCoordinate(tt.c1)
+			c2, _ := ConvertCoordinate(tt.c2)
+			assert.Equal(t, cells(t, tt.want...), Torus(tt.g, 10).Area(c1, c2))
+		})
+	}
+
+	// cells out of the field are not wrapped
+	c1, c2 := Coordinate{X: 9, Y: 0}, Coordinate{X: 10, Y: 0}
+	assert.Equal(t, Rect(c1, c2), Torus(Square, 10).Area(c1, c2))
+}
+
+func TestTorus_Ring(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      Grid
+		corner string
+		want   []string
+	}{
+		{name: "A1", g: Square, corner: "A1", want: []string{"J10", "A10", "B10", "J1", "B1", "J2", "A2", "B2"}},
+		{name: "J1", g: Square, corner: "J1", want: []string{"I10", "J10", "A10", "I1", "A1", "I2", "J2", "A2"}},
+		{name: "A10", g: Square, corner: "A10", want: []string{"J9", "A9", "B9", "J10", "B10", "J1", "A1", "B1"}},
+		{name: "J10", g: Square, corner: "J10", want: []string{"I9", "J9", "A9", "I10", "A10", "I1", "J1", "A1"}},
+		{name: "hex, A1", g: Hexagonal, corner: "A1", want: []string{"A10", "B10", "J1", "B1", "J2", "A2"}},
+		{name: "hex, J10", g: Hexagonal, corner: "J10", want: []string{"J9", "A9", "I10", "A10", "I1", "J1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Torus(tt.g, 10)
+			c := cells(t, tt.corner)
+			assert.Equal(t, cells(t, tt.want...), g.Ring(c))
+			assert.Equal(t, tt.g.Ring(cells(t, "E5")).Len(), g.Ring(c).Len())
+		})
+	}
+
+	// ships crossing the edge are surrounded from both sides
+	g := Torus(Square, 10)
+	ship := cells(t, "J1", "A1")
+	assert.Equal(t, cells(t, "I10", "J10", "A10", "B10", "I1", "B1", "I2", "J2", "A2", "B2"), g.Ring(ship))
+	assert.Equal(t, cells(t, "I1", "B1", "J10", "A10", "J2", "A2"), g.Neighbours(ship))
+}
+
+func TestTorus_Connected(t *testing.T) {
+	edges := cells(t, "J1", "A1")
+	assert.False(t, Square.Connected(edges))
+	assert.True(t, Torus(Square, 10).Connected(edges))
+
+	corners := cells(t, "A1", "J1", "A10", "J10")
+	assert.True(t, Torus(Square, 10).Connected(corners))
+	assert.True(t, Torus(Hexagonal, 10).Connected(corners))
+
+	assert.False(t, Torus(Square, 10).Connected(cells(t, "A1", "C1")))
+}
+
+func TestTorus_Line(t *testing.T) {
+	g := Torus(Square, 10)
+	assert.True(t, g.Line(cells(t, "J1", "A1", "B1")))
+	assert.True(t, g.Line(cells(t, "A10", "A1", "A2")))
+	assert.False(t, g.Line(cells(t, "J10", "A10", "A1")))
+
+	// hex diagonal crossing edges
+	assert.True(t, Torus(Hexagonal, 10).Line(cells(t, "B1", "A2", "J3")))
+	assert.False(t, Hexagonal.Line(cells(t, "B1", "A2", "J3")))
+}
+
+func TestTorus_Unwrap(t *testing.T) {
+	g := Torus(Square, 10)
+	// the L crossing the corner gets its shape back
+	got := g.Unwrap(cells(t, "J10", "A10", "A1"))
+	want := NewCoordinates(
+		Coordinate{X: 9, Y: 9}, Coordinate{X: 10, Y: 9},
+		Coordinate{X: 10, Y: 10},
+	)
+	assert.Equal(t, want, got)
+	assert.Equal(t, Coordinates{}, g.Unwrap(Coordinates{}))
+
+	s := cells(t, "J1", "A1")
+	assert.Equal(t, s, Square.Unwrap(s))
+	assert.Equal(t, s, Hexagonal.Unwrap(s))
+}
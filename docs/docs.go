@@ -1,6 +1,6 @@
 // GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag at
-// 2020-04-05 16:52:16.090589666 +0300 MSK m=+0.023770886
+// 2026-10-19 17:38:15.165181612 +0000 UTC m=+0.131372773
 
 package docs
 
@@ -31,7 +31,15 @@ var doc = `{
     "paths": {
         "/clear": {
             "post": {
-                "description": "clear the battlefield",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "clear the battlefield\nrequires admin role",
                 "consumes": [
                     "application/json"
                 ],
@@ -39,8 +47,52 @@ var doc = `{
                     "BattleField"
                 ],
                 "summary": "clear the battlefield",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {},
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -52,7 +104,15 @@ var doc = `{
         },
         "/create-matrix": {
             "post": {
-                "description": "create new battlefield with provided size",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new battlefield with provided size.\ngrid is square or hex, hexes are addressed like squares, see coordinates.Hex.\nwrap makes the field a torus: ships may cross its edges and cells at opposite edges touch.\nadvanced gives the attacker limited weapons, see /weapon endpoints.\nmoving lets the owner move ships, see /ship/{id}/move.\nrepairs is the number of hit cells the owner can repair on every ship, see /ship/{id}/repair.\nmines is the number of mines the owner hides, see /mines, the attacker who hits a mine\nloses the next turn with \"skip\" mine_penalty, the default, or gives the owner a free turn\nto move or repair a ship with \"retaliate\", which needs moving or repairs.\nislands and reefs are set with terrain or with the name of the map,\nships can't be placed on them, islands can't be shot, reefs absorb shots.",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,6 +129,18 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/battlefield.CreateFieldRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -79,12 +151,42 @@ var doc = `{
                             "$ref": "#/definitions/battlefield.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/battlefield.HTTPError"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -94,37 +196,71 @@ var doc = `{
                 }
             }
         },
-        "/ship": {
-            "post": {
-                "description": "add ships to battlefield\ninput params should be like this:\n\"A1 B2,C4 C6,E7 F8\" where first coordinate is one corner of ship, second - other.\nships can be square or rectangular\nships can't be placed on top of each other and near each other.",
-                "consumes": [
-                    "application/json"
+        "/field": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the field as seen by the player, terrain is visible to everyone.\nonly the owner of the battlefield sees ships, others see hits and misses only.\ngame version is returned in ETag header as in /state, the field is not sent\nif it matches If-None-Match header.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
                 ],
                 "tags": [
-                    "Ships"
+                    "BattleField"
                 ],
-                "summary": "add ships to battlefield",
+                "summary": "get the field view",
                 "parameters": [
                     {
-                        "description": "coordinates",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/battlefield.AddShipsRequest"
-                        }
+                        "type": "boolean",
+                        "description": "hide ships from the owner too",
+                        "name": "fog",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "text"
+                        ],
+                        "type": "string",
+                        "description": "field format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the known field view",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {},
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.FieldResponse"
+                        }
+                    },
+                    "304": {},
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/battlefield.HTTPError"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/battlefield.HTTPError"
                         }
@@ -138,26 +274,93 @@ var doc = `{
                 }
             }
         },
-        "/shot": {
-            "post": {
-                "description": "make a shot to provided coordinate\nexample: \"A1\"",
-                "consumes": [
+        "/games/paused": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list paused games of the player, all paused games are listed for the admin.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Battle"
+                    "Games"
                 ],
-                "summary": "make a shot to provided coordinate",
-                "parameters": [
-                    {
-                        "description": "shot coordinates",
-                        "name": "model",
-                        "in": "body",
-                        "required": true,
+                "summary": "list paused games",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/battlefield.ShotRequest"
+                            "$ref": "#/definitions/battlefield.PausedGamesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
                         }
                     }
+                }
+            }
+        },
+        "/games/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "pause the current game with the identifier from the state: the clock is stopped,\nmoves fail with GAME_PAUSED until the game is resumed, and the game is saved at once.\nanother game can be created while the game is paused.\nonly players of the game can pause it, games paused for too long are archived.",
+                "tags": [
+                    "Games"
+                ],
+                "summary": "pause the game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "game identifier",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
                 ],
                 "responses": {
                     "200": {},
@@ -167,62 +370,2405 @@ var doc = `{
                             "$ref": "#/definitions/battlefield.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
                     }
                 }
             }
         },
-        "/state": {
-            "get": {
-                "description": "get the state of current game",
-                "consumes": [
-                    "application/json"
+        "/games/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "resume the paused game, see /games/paused. The current game is resumed in place,\nother games are resumed if the current game is finished or paused.\nonly players of the game can resume it.",
                 "tags": [
-                    "BattleField"
+                    "Games"
+                ],
+                "summary": "resume the paused game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "game identifier",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
                 ],
-                "summary": "get the state of current game",
                 "responses": {
                     "200": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "battlefield.AddShipsRequest": {
-            "type": "object",
-            "properties": {
-                "Coordinates": {
-                    "type": "string"
-                }
-            }
         },
-        "battlefield.CreateFieldRequest": {
-            "type": "object",
-            "properties": {
-                "range": {
-                    "type": "integer"
+        "/healthz": {
+            "get": {
+                "description": "the server is alive",
+                "tags": [
+                    "Health"
+                ],
+                "summary": "liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list archived games of the player from the last finished one, the admin sees all of them.\nFinished games are archived when the game is saved, games paused for too long are archived too.\ndates are RFC 3339 times or YYYY-MM-DD dates in UTC, the game is finished since from and before to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Games"
+                ],
+                "summary": "list archived games",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "owner or attacker of the game",
+                        "name": "player",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest finish date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest finish date, excluded",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "free",
+                            "classic",
+                            "tetromino"
+                        ],
+                        "type": "string",
+                        "description": "rules preset",
+                        "name": "rules",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "won",
+                            "abandoned"
+                        ],
+                        "type": "string",
+                        "description": "outcome of the game",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of skipped games",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 20 if empty, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/history/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the archived game with all moves and final boards. The game is replayed\nby creating the field with game params and making moves of the log in order.\nonly players of the game and the admin can get it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Games"
+                ],
+                "summary": "get the archived game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "game identifier",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HistoryRecordResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/history/{id}/replay": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replay the archived game move by move: the field seen by the owner after every move,\ntargets of shots and weapons are outlined.\ngif is the animated image, html is the page with a slider over moves, it doesn't load other resources.\nonly players of the game and the admin can replay it.",
+                "produces": [
+                    "text/html",
+                    "image/gif"
+                ],
+                "tags": [
+                    "Games"
+                ],
+                "summary": "replay the archived game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "game identifier",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "html",
+                            "gif"
+                        ],
+                        "type": "string",
+                        "description": "replay format, html if empty",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/mines": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "hide mines in cells without ships, e.g. \"C3,E5\", mines can't be placed on islands.\nthe game is created with the number of mines, they are placed after ships and before the first shot.\nonly the player who created the battlefield can place mines.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BattleField"
+                ],
+                "summary": "hide mines on the battlefield",
+                "parameters": [
+                    {
+                        "description": "coordinates",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.PlaceMinesRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "the server is ready to accept requests:\nit is not shutting down and the store is available",
+                "tags": [
+                    "Health"
+                ],
+                "summary": "readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "add ships to battlefield\ninput params should be like this:\n\"A1 B2,C4 C6,E7 F8\" where first coordinate is one corner of ship, second - other.\nships of other shapes are set cell by cell: \"A1+A2+B2\", cells should be connected.\non hex grid two cells on one axis set the straight line, e.g. \"C1 A3\", other cells set the rhombus.\nships can't be placed on top of each other and near each other.\nonly the player who created the battlefield can add ships.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ships"
+                ],
+                "summary": "add ships to battlefield",
+                "parameters": [
+                    {
+                        "description": "coordinates",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.AddShipsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/auto": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "place straight ships of provided lengths randomly, the fleet of the rules is used\nif lengths are empty. placement depends only on the seed of the game and previous moves.\nonly the player who created the battlefield can add ships.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ships"
+                ],
+                "summary": "place ships randomly",
+                "parameters": [
+                    {
+                        "description": "lengths of ships",
+                        "name": "model",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.AutoPlaceShipsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "export placed ships in JSON or text layout format, see battlefield.Layout.\nonly the player who created the battlefield can export ships.",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Ships"
+                ],
+                "summary": "export placed ships",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "text"
+                        ],
+                        "type": "string",
+                        "description": "layout format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.Layout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "add ships to battlefield from JSON or text layout, see battlefield.Layout.\ntext layout is expected if Content-Type is text/plain.\nlayout size and rules should match the battlefield.\nonly the player who created the battlefield can add ships.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Ships"
+                ],
+                "summary": "import ships to battlefield",
+                "parameters": [
+                    {
+                        "description": "layout",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.Layout"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/validate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "check ships placement without adding ships to battlefield.\nall problems are returned: invalid coordinates, out of bounds,\noverlapping and nearby ships, ships not allowed by the rules.\nonly the player who created the battlefield can validate ships.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ships"
+                ],
+                "summary": "validate ships placement",
+                "parameters": [
+                    {
+                        "description": "coordinates",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.AddShipsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.ValidateShipsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/{id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "move the undamaged ship by one cell: up, down, left or right on the square grid,\nleft, right or diagonally on the hex grid.\nships are numbered from zero in the order they were added, see /ship/export.\nthe owner may reply with one move after every move of the attacker if the game\nis created with moving, the attacker doesn't wait for the reply.\nThe ship is checked like the placed one and can't move onto cells which are already shot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BattleField"
+                ],
+                "summary": "move the ship",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "index of the ship",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "direction",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.MoveShipRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.MoveShipResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ship/{id}/repair": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "repair the hit cell of the damaged ship which is not destroyed yet, e.g. \"A1\".\nthe owner may repair or move one ship after every move of the attacker if the game\nis created with repairs, every ship can be repaired the number of times of the game.\nthe repaired cell is not shot anymore, the attacker sees the hit until they shoot it again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BattleField"
+                ],
+                "summary": "repair the ship",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "index of the ship",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "hit cell",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.RepairShipRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.RepairShipResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/shot": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "make a shot to provided coordinate\nexample: \"A1\"\nowner of the battlefield can't shoot, the first player who shoots\nbecomes the attacker and only they can shoot further.\nislands can't be shot, shots at reefs are absorbed and reported in terrain.\nshots at mines are reported with the penalty, the next shot of the attacker who lost\nthe turn is not fired and is reported as skipped, weapons fail with TURN_LOST until then.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Battle"
+                ],
+                "summary": "make a shot to provided coordinate",
+                "parameters": [
+                    {
+                        "description": "shot coordinates",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.ShotRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/state": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the state of current game\ngame version is returned in ETag header, the state is not sent\nif it matches If-None-Match header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BattleField"
+                ],
+                "summary": "get the state of current game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the known game version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {},
+                    "304": {},
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get accuracy of shots in the current game and of finished games by player",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "get statistics of games",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.StatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/heatmap": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get number of shots and hits of every cell across finished games of the field size.\npng image shows shots or hits depending on kind, more frequent cells are redder.",
+                "produces": [
+                    "application/json",
+                    "image/png"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "get heatmap of shots",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "field size, size of the current field if empty",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "png"
+                        ],
+                        "type": "string",
+                        "description": "heatmap format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "shots",
+                            "hits"
+                        ],
+                        "type": "string",
+                        "description": "cells to show in png",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HeatmapResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/weapon/bomb": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "drop the bomb: shoot at the coordinate, e.g. \"B2\", and at cells sharing a side with it,\nfour on the square grid or six on the hex grid. Cells already shot and islands are skipped,\nevery shot is counted in the state like the shot at one cell.\navailable in the advanced mode only, the number of uses is in the state.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Battle"
+                ],
+                "summary": "drop the bomb",
+                "parameters": [
+                    {
+                        "description": "center of the bomb",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.ShotRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.BombResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/weapon/radar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "scan the column, e.g. \"C\", or the row, e.g. \"3\", and count ship cells\nwhich aren't shot yet there. Cells are not marked as shot.\navailable in the advanced mode only, the number of uses is in the state.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Battle"
+                ],
+                "summary": "scan the line with radar",
+                "parameters": [
+                    {
+                        "description": "line to scan",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.RadarRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.RadarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/weapon/sonar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ping the coordinate, e.g. \"B2\", and cells touching it: the 3x3 area on the square grid\nor six neighbours on the hex grid. Report if any ship which isn't shot yet is there.\nCells are not marked as shot.\navailable in the advanced mode only, the number of uses is in the state.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Battle"
+                ],
+                "summary": "ping the area with sonar",
+                "parameters": [
+                    {
+                        "description": "center of the area",
+                        "name": "model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.ShotRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "unique request ID, retried request gets the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected game version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.SonarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/battlefield.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "battlefield.AddShipsRequest": {
+            "type": "object",
+            "properties": {
+                "Coordinates": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.AutoPlaceShipsRequest": {
+            "type": "object",
+            "properties": {
+                "ships": {
+                    "description": "Ships are lengths of ships, the rules fleet is used if empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "battlefield.BombResponse": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "boolean"
+                },
+                "shots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/battlefield.BombShot"
+                    }
+                }
+            }
+        },
+        "battlefield.BombShot": {
+            "type": "object",
+            "properties": {
+                "coord": {
+                    "type": "string"
+                },
+                "destroy": {
+                    "type": "boolean"
+                },
+                "knock": {
+                    "type": "boolean"
+                },
+                "mine": {
+                    "type": "boolean"
+                },
+                "penalty": {
+                    "type": "string",
+                    "enum": [
+                        "retaliate",
+                        "skip"
+                    ]
+                },
+                "terrain": {
+                    "type": "string",
+                    "enum": [
+                        "reef"
+                    ]
+                }
+            }
+        },
+        "battlefield.CreateFieldRequest": {
+            "type": "object",
+            "properties": {
+                "advanced": {
+                    "description": "Advanced gives the attacker limited weapons: sonar, radar and bomb.",
+                    "type": "boolean"
+                },
+                "grid": {
+                    "description": "Grid is the grid of the field, \"square\" if empty.",
+                    "type": "string",
+                    "enum": [
+                        "square",
+                        "hex"
+                    ]
+                },
+                "map": {
+                    "description": "Map is the name of the terrain preset, can't be set with Terrain.",
+                    "type": "string",
+                    "enum": [
+                        "archipelago",
+                        "strait"
+                    ]
+                },
+                "mine_penalty": {
+                    "description": "MinePenalty is the penalty of the attacker who hits a mine, \"skip\" if empty.\n\"retaliate\" gives the owner a free turn instead of the shot and needs moves or repairs.",
+                    "type": "string",
+                    "enum": [
+                        "retaliate",
+                        "skip"
+                    ]
+                },
+                "mines": {
+                    "description": "Mines is the number of mines the owner hides among cells without ships.",
+                    "type": "integer"
+                },
+                "moving": {
+                    "description": "Moving lets the owner move one undamaged ship by one cell\nafter every move of the attacker.",
+                    "type": "boolean"
+                },
+                "range": {
+                    "type": "integer"
+                },
+                "repairs": {
+                    "description": "Repairs is the number of hit cells the owner can repair on every ship\nafter moves of the attacker.",
+                    "type": "integer"
+                },
+                "rules": {
+                    "description": "Rules is the rules preset, \"free\" if empty.",
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "classic",
+                        "tetromino"
+                    ]
+                },
+                "seed": {
+                    "description": "Seed makes random moves of the game reproducible, random if empty.",
+                    "type": "integer"
+                },
+                "terrain": {
+                    "description": "Terrain is islands and reefs of the field.",
+                    "type": "object",
+                    "$ref": "#/definitions/battlefield.Terrain"
+                },
+                "wrap": {
+                    "description": "Wrap makes the field a torus: ships may cross its edges\nand cells at opposite edges touch each other.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "battlefield.ErrorDetails": {
+            "type": "object",
+            "properties": {
+                "coord": {
+                    "description": "Coord is the coordinate caused the error, e.g. \"A1\".",
+                    "type": "string"
+                },
+                "ship": {
+                    "description": "Ship is zero-based index of the ship in the request.",
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.FieldResponse": {
+            "type": "object",
+            "properties": {
+                "fog": {
+                    "description": "Fog is true if ships which are not hit are hidden.",
+                    "type": "boolean"
+                },
+                "game": {
+                    "type": "string"
+                },
+                "grid": {
+                    "type": "string",
+                    "enum": [
+                        "square",
+                        "hex"
+                    ]
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
         "battlefield.HTTPError": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "INVALID_INPUT_PARAMS",
+                        "INVALID_FIELD_SIZE",
+                        "FIELD_ALREADY_SET",
+                        "INVALID_COORDINATE",
+                        "CELL_OCCUPIED_BY_SHIP",
+                        "CELL_OCCUPIED_NEARBY",
+                        "SHIPS_ALREADY_ADDED",
+                        "OUT_OF_BOUNDS",
+                        "CELL_ALREADY_SHOT",
+                        "SHIPS_NOT_PLACED",
+                        "UNAUTHORIZED",
+                        "INVALID_CREDENTIALS",
+                        "TOKEN_EXPIRED",
+                        "ADMIN_REQUIRED",
+                        "NOT_BOARD_OWNER",
+                        "NOT_YOUR_TURN",
+                        "TOO_MANY_REQUESTS",
+                        "REQUEST_TOO_LARGE",
+                        "SERVER_DRAINING",
+                        "STORE_UNAVAILABLE",
+                        "INVALID_IDEMPOTENCY_KEY",
+                        "IDEMPOTENCY_KEY_REUSED",
+                        "VERSION_MISMATCH",
+                        "UNKNOWN_RULES",
+                        "SHIP_NOT_STRAIGHT",
+                        "FLEET_MISMATCH",
+                        "INVALID_LAYOUT",
+                        "LAYOUT_MISMATCH",
+                        "FLEET_DOES_NOT_FIT",
+                        "SHIP_NOT_CONNECTED",
+                        "SHAPE_NOT_ALLOWED",
+                        "UNKNOWN_MAP",
+                        "INVALID_TERRAIN",
+                        "SHIP_ON_TERRAIN",
+                        "CELL_NOT_SHOOTABLE",
+                        "UNKNOWN_GRID",
+                        "GRID_NOT_SUPPORTED",
+                        "WEAPONS_DISABLED",
+                        "WEAPON_EXHAUSTED",
+                        "MOVES_DISABLED",
+                        "SHIP_NOT_FOUND",
+                        "SHIP_DAMAGED",
+                        "MINES_DISABLED",
+                        "MINES_NOT_PLACED",
+                        "MINES_ALREADY_PLACED",
+                        "MINES_MISMATCH",
+                        "CELL_HAS_MINE",
+                        "TURN_LOST",
+                        "RETALIATE_NOT_SUPPORTED",
+                        "REPAIRS_DISABLED",
+                        "REPAIRS_EXHAUSTED",
+                        "SHIP_DESTROYED",
+                        "CELL_NOT_DAMAGED",
+                        "GAME_NOT_FOUND",
+                        "NOT_GAME_PLAYER",
+                        "GAME_IS_OVER",
+                        "GAME_PAUSED",
+                        "GAME_NOT_PAUSED"
+                    ]
+                },
+                "details": {
+                    "type": "object",
+                    "$ref": "#/definitions/battlefield.ErrorDetails"
+                },
                 "err": {
                     "type": "string"
                 }
             }
         },
+        "battlefield.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.HeatmapResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "shots": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.HistoryGame": {
+            "type": "object",
+            "properties": {
+                "attacker": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is the play time of the game in seconds, pauses excluded.",
+                    "type": "integer"
+                },
+                "finished": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "moves": {
+                    "description": "Moves is the number of moves of both players.",
+                    "type": "integer"
+                },
+                "outcome": {
+                    "description": "Outcome is \"won\" if the attacker destroyed all ships,\n\"abandoned\" if the game was paused for too long.",
+                    "type": "string",
+                    "enum": [
+                        "won",
+                        "abandoned"
+                    ]
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.HistoryMove": {
+            "type": "object",
+            "properties": {
+                "arg": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "ships",
+                        "auto",
+                        "mines",
+                        "shot",
+                        "sonar",
+                        "radar",
+                        "bomb",
+                        "move",
+                        "repair",
+                        "skip",
+                        "pause",
+                        "resume"
+                    ]
+                },
+                "player": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.HistoryRecordResponse": {
+            "type": "object",
+            "properties": {
+                "attacker": {
+                    "type": "string"
+                },
+                "board": {
+                    "description": "Board is the final field as seen by the owner,\nFog is the final field as seen by the attacker.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is the play time of the game in seconds, pauses excluded.",
+                    "type": "integer"
+                },
+                "finished": {
+                    "type": "string"
+                },
+                "fog": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "game": {
+                    "type": "object",
+                    "$ref": "#/definitions/battlefield.CreateFieldRequest"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/battlefield.HistoryMove"
+                    }
+                },
+                "moves": {
+                    "description": "Moves is the number of moves of both players.",
+                    "type": "integer"
+                },
+                "outcome": {
+                    "description": "Outcome is \"won\" if the attacker destroyed all ships,\n\"abandoned\" if the game was paused for too long.",
+                    "type": "string",
+                    "enum": [
+                        "won",
+                        "abandoned"
+                    ]
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/battlefield.HistoryGame"
+                    }
+                },
+                "total": {
+                    "description": "Total is the number of games matching the filters.",
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.Layout": {
+            "type": "object",
+            "properties": {
+                "grid": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "string"
+                },
+                "ships": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "wrap": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "battlefield.MoveShipRequest": {
+            "type": "object",
+            "properties": {
+                "direction": {
+                    "description": "Direction is up, down, left or right on the square grid,\nleft, right, up-left, up-right, down-left or down-right on the hex grid.",
+                    "type": "string",
+                    "enum": [
+                        "up",
+                        "down",
+                        "left",
+                        "right",
+                        "up-left",
+                        "up-right",
+                        "down-left",
+                        "down-right"
+                    ]
+                }
+            }
+        },
+        "battlefield.MoveShipResponse": {
+            "type": "object",
+            "properties": {
+                "ship": {
+                    "description": "Ship is the ship in the format of AddShipsRequest, e.g. \"A2 A5\".",
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.PausedGame": {
+            "type": "object",
+            "properties": {
+                "attacker": {
+                    "type": "string"
+                },
+                "clock": {
+                    "description": "Clock is the play time of the game in seconds.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "paused_at": {
+                    "type": "string"
+                },
+                "range": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.PausedGamesResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/battlefield.PausedGame"
+                    }
+                }
+            }
+        },
+        "battlefield.PlaceMinesRequest": {
+            "type": "object",
+            "properties": {
+                "Coordinates": {
+                    "description": "Coords are cells of mines separated by commas, e.g. \"C3,E5\".",
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.PlayerStats": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "integer"
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "longest_streak": {
+                    "description": "LongestStreak is the longest sequence of hits in a row.",
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "shots": {
+                    "type": "integer"
+                },
+                "shots_per_sunk": {
+                    "description": "ShotsPerSunk is zero if no ships are sunk.",
+                    "type": "number"
+                },
+                "shots_to_first_hit": {
+                    "description": "ShotsToFirstHit is zero if there are no hits,\naverage of finished games for players.",
+                    "type": "number"
+                },
+                "sunk": {
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.RadarRequest": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "Line is the column letter, e.g. \"C\", or the row number, e.g. \"3\".",
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.RadarResponse": {
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "description": "Cells is the number of ship cells which aren't shot yet on the line.",
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.RepairShipRequest": {
+            "type": "object",
+            "properties": {
+                "coord": {
+                    "description": "Coord is the hit cell of the ship, e.g. \"A1\".",
+                    "type": "string"
+                }
+            }
+        },
+        "battlefield.RepairShipResponse": {
+            "type": "object",
+            "properties": {
+                "repairs": {
+                    "type": "integer"
+                }
+            }
+        },
         "battlefield.ShotRequest": {
             "type": "object",
             "properties": {
@@ -230,6 +2776,111 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "battlefield.ShotStats": {
+            "type": "object",
+            "properties": {
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "longest_streak": {
+                    "description": "LongestStreak is the longest sequence of hits in a row.",
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "shots": {
+                    "type": "integer"
+                },
+                "shots_per_sunk": {
+                    "description": "ShotsPerSunk is zero if no ships are sunk.",
+                    "type": "number"
+                },
+                "shots_to_first_hit": {
+                    "description": "ShotsToFirstHit is zero if there are no hits,\naverage of finished games for players.",
+                    "type": "number"
+                },
+                "sunk": {
+                    "type": "integer"
+                }
+            }
+        },
+        "battlefield.SonarResponse": {
+            "type": "object",
+            "properties": {
+                "found": {
+                    "description": "Found is true if any ship cell which isn't shot yet is in the area.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "battlefield.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "game": {
+                    "description": "Game is statistics of the current game, empty if ships are not placed.",
+                    "type": "object",
+                    "$ref": "#/definitions/battlefield.ShotStats"
+                },
+                "games": {
+                    "description": "Games is the number of finished games.",
+                    "type": "integer"
+                },
+                "players": {
+                    "description": "Players is statistics of finished games by attacker,\n\"anonymous\" if authentication is disabled.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/battlefield.PlayerStats"
+                    }
+                }
+            }
+        },
+        "battlefield.Terrain": {
+            "type": "object",
+            "properties": {
+                "islands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reefs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "battlefield.ValidateShipsResponse": {
+            "type": "object",
+            "properties": {
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/battlefield.HTTPError"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
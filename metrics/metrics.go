@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "battleship"
+
+// maxErrorBodySize limits the part of error response body kept for labeling.
+const maxErrorBodySize = 1024
+
+// Metrics collects prometheus metrics of the battleship server.
+// It implements battlefield.Observer.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+
+	gamesCreated  prometheus.Counter
+	gamesFinished prometheus.Counter
+	gamesActive   prometheus.Gauge
+
+	shots prometheus.Counter
+	hits  prometheus.Counter
+	sinks prometheus.Counter
+
+	lockWait *prometheus.HistogramVec
+}
+
+// New creates new Metrics and registers all collectors in reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_errors_total",
+			Help:      "Number of error responses by error message and status code.",
+		}, []string{"err", "code"}),
+		gamesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "games_created_total",
+			Help:      "Number of created games.",
+		}),
+		gamesFinished: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "games_finished_total",
+			Help:      "Number of games finished with all ships destroyed.",
+		}),
+		gamesActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "games_active",
+			Help:      "Number of games in progress.",
+		}),
+		shots: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shots_total",
+			Help:      "Number of shots fired.",
+		}),
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "hits_total",
+			Help:      "Number of shots that hit a ship.",
+		}),
+		sinks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sinks_total",
+			Help:      "Number of shots that destroyed a ship.",
+		}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "service_lock_wait_seconds",
+			Help:      "Time spent waiting for the service lock by lock mode.",
+			Buckets:   []float64{.00001, .0001, .001, .01, .1, 1},
+		}, []string{"mode"}),
+	}
+
+	reg.MustRegister(
+		m.requests,
+		m.latency,
+		m.errors,
+		m.gamesCreated,
+		m.gamesFinished,
+		m.gamesActive,
+		m.shots,
+		m.hits,
+		m.sinks,
+		m.lockWait,
+	)
+	return m
+}
+
+// GameCreated implements battlefield.Observer.
+func (m *Metrics) GameCreated() {
+	m.gamesCreated.Inc()
+	m.gamesActive.Inc()
+}
+
+// GameFinished implements battlefield.Observer.
+func (m *Metrics) GameFinished() {
+	m.gamesFinished.Inc()
+	m.gamesActive.Dec()
+}
+
+// GameDiscarded implements battlefield.Observer.
+func (m *Metrics) GameDiscarded() {
+	m.gamesActive.Dec()
+}
+
+// ShotFired implements battlefield.Observer.
+func (m *Metrics) ShotFired(knock, destroy bool) {
+	m.shots.Inc()
+	if knock {
+		m.hits.Inc()
+	}
+	if destroy {
+		m.sinks.Inc()
+	}
+}
+
+// LockWaited implements battlefield.Observer.
+func (m *Metrics) LockWaited(write bool, d time.Duration) {
+	mode := "read"
+	if write {
+		mode = "write"
+	}
+	m.lockWait.WithLabelValues(mode).Observe(d.Seconds())
+}
+
+// Middleware collects request count, latency and errors of the routes.
+// It should be attached to the mux.Router with Use.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if tpl, err := cr.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		code := strconv.Itoa(rec.status)
+		m.latency.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		m.requests.WithLabelValues(route, r.Method, code).Inc()
+		if rec.status >= http.StatusBadRequest {
+			m.errors.WithLabelValues(rec.errorMessage(), code).Inc()
+		}
+	})
+}
+
+// recorder keeps response status and the beginning of error response body.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if r.status >= http.StatusBadRequest && r.body.Len() < maxErrorBodySize {
+		n := maxErrorBodySize - r.body.Len()
+		if n > len(b) {
+			n = len(b)
+		}
+		r.body.Write(b[:n])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// errorMessage extracts HTTPError message from the response body.
+// Non-JSON bodies are reported as "internal" to keep label cardinality low.
+func (r *recorder) errorMessage() string {
+	if !strings.HasPrefix(r.Header().Get("Content-Type"), "application/json") {
+		return "internal"
+	}
+	e := struct {
+		Err string `json:"err"`
+	}{}
+	if err := json.Unmarshal(r.body.Bytes(), &e); err != nil || e.Err == "" {
+		return "internal"
+	}
+	return e.Err
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Games(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.GameCreated()
+	m.GameCreated()
+	m.GameFinished()
+	m.GameCreated()
+	m.GameDiscarded()
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.gamesCreated))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.gamesFinished))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.gamesActive))
+}
+
+func TestMetrics_ShotFired(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.ShotFired(false, false)
+	m.ShotFired(true, false)
+	m.ShotFired(true, true)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.shots))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.hits))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.sinks))
+}
+
+func TestMetrics_LockWaited(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	m.LockWaited(true, time.Millisecond)
+	m.LockWaited(false, time.Millisecond)
+	m.LockWaited(false, time.Millisecond)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.lockWait))
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantCode    string
+		wantErr     string
+		wantErrors  float64
+		wantRequest float64
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("{}"))
+			},
+			wantCode:    "200",
+			wantRequest: 1,
+		},
+		{
+			name: "error, json body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":"OUT_OF_BOUNDS","err":"out of bonds"}`))
+			},
+			wantCode:    "400",
+			wantErr:     "out of bonds",
+			wantErrors:  1,
+			wantRequest: 1,
+		},
+		{
+			name: "error, plain text body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("something went wrong"))
+			},
+			wantCode:    "500",
+			wantErr:     "internal",
+			wantErrors:  1,
+			wantRequest: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(prometheus.NewRegistry())
+			r := mux.NewRouter()
+			r.Use(m.Middleware)
+			r.HandleFunc("/shot", tt.handler)
+
+			req, _ := http.NewRequest(http.MethodPost, "/shot", nil)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantRequest, testutil.ToFloat64(
+				m.requests.WithLabelValues("/shot", http.MethodPost, tt.wantCode),
+			))
+			assert.Equal(t, tt.wantErrors, testutil.ToFloat64(
+				m.errors.WithLabelValues(tt.wantErr, tt.wantCode),
+			))
+			assert.Equal(t, 1, testutil.CollectAndCount(m.latency))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when there is no value stored by the key.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidKey is returned for keys that can't be stored.
+var ErrInvalidKey = errors.New("invalid key")
+
+// Store keeps values by keys. Keys are slash-separated paths,
+// e.g. "games/active".
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+	// List returns sorted keys with the prefix.
+	List(prefix string) ([]string, error)
+	// Ping checks if the store is available.
+	Ping() error
+}
+
+// Memory is in-memory Store, its values are lost on exit.
+type Memory struct {
+	values map[string][]byte
+	mu     sync.RWMutex
+}
+
+// NewMemory creates new Memory.
+func NewMemory() *Memory {
+	return &Memory{values: make(map[string][]byte)}
+}
+
+// Get implements Store.
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// Put implements Store.
+func (m *Memory) Put(key string, value []byte) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete implements Store.
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+	return nil
+}
+
+// List implements Store.
+func (m *Memory) List(prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for k := range m.values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Ping implements Store.
+func (m *Memory) Ping() error {
+	return nil
+}
+
+// File is Store keeping every value in a separate file under the directory.
+type File struct {
+	dir string
+}
+
+// NewFile creates new File, the directory is created if needed.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	return &File{dir: dir}, nil
+}
+
+func (f *File) path(key string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(key))
+}
+
+// Get implements Store.
+func (f *File) Get(key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+	b, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+// Put implements Store. Value is written to temporary file first,
+// so the stored value is never partially written.
+func (f *File) Put(key string, value []byte) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	path := f.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete implements Store.
+func (f *File) Delete(key string) error {
+	if !validKey(key) {
+		return nil
+	}
+	err := os.Remove(f.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List implements Store.
+func (f *File) List(prefix string) ([]string, error) {
+	keys := make([]string, 0)
+	err := filepath.Walk(f.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Ping implements Store.
+func (f *File) Ping() error {
+	info, err := os.Stat(f.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New(f.dir + " is not a directory")
+	}
+	return nil
+}
+
+// validKey checks that key can't escape the store directory.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, ".tmp-") {
+			return false
+		}
+	}
+	return true
+}
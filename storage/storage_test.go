@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFile(t *testing.T) *File {
+	dir, err := ioutil.TempDir("", "battleship-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	f, err := NewFile(filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{
+			name:  "memory",
+			store: func(t *testing.T) Store { return NewMemory() },
+		},
+		{
+			name:  "file",
+			store: func(t *testing.T) Store { return newFile(t) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.store(t)
+
+			assert.NoError(t, s.Ping())
+
+			_, err := s.Get("games/active")
+			assert.Equal(t, ErrNotFound, err)
+
+			assert.NoError(t, s.Put("games/active", []byte("1")))
+			assert.NoError(t, s.Put("games/active", []byte("2")))
+			assert.NoError(t, s.Put("paused/b", []byte("b")))
+			assert.NoError(t, s.Put("paused/a", []byte("a")))
+
+			got, err := s.Get("games/active")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("2"), got)
+
+			keys, err := s.List("paused/")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"paused/a", "paused/b"}, keys)
+
+			assert.NoError(t, s.Delete("paused/a"))
+			assert.NoError(t, s.Delete("paused/a"))
+			keys, err = s.List("")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"games/active", "paused/b"}, keys)
+
+			assert.Equal(t, ErrInvalidKey, s.Put("../escape", nil))
+			assert.Equal(t, ErrInvalidKey, s.Put("/root", nil))
+			assert.Equal(t, ErrInvalidKey, s.Put("", nil))
+		})
+	}
+}
+
+func TestFile_Ping(t *testing.T) {
+	f := newFile(t)
+	assert.NoError(t, f.Ping())
+
+	assert.NoError(t, os.RemoveAll(f.dir))
+	assert.Error(t, f.Ping())
+}